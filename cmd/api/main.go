@@ -2,18 +2,30 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"log/slog" // New structured logging package
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata" // channel quiet hours use IANA zones, the container may not ship them
 
 	"net"
 
+	"github.com/derkres11/price-pulse/internal/auth"
 	"github.com/derkres11/price-pulse/internal/broker"
+	"github.com/derkres11/price-pulse/internal/config"
 	"github.com/derkres11/price-pulse/internal/database"
+	"github.com/derkres11/price-pulse/internal/domain"
+	"github.com/derkres11/price-pulse/internal/fetcher"
+	"github.com/derkres11/price-pulse/internal/leader"
+	"github.com/derkres11/price-pulse/internal/notify"
+	"github.com/derkres11/price-pulse/internal/outbox"
+	"github.com/derkres11/price-pulse/internal/scheduler"
 	"github.com/derkres11/price-pulse/internal/service"
+	"github.com/derkres11/price-pulse/internal/stream"
 	transportHTTP "github.com/derkres11/price-pulse/internal/transport/http"
 	grpcHandler "github.com/derkres11/price-pulse/internal/transport/http/grpc"
 	desc "github.com/derkres11/price-pulse/pkg/api/v1"
@@ -30,43 +42,280 @@ func main() {
 		slog.Warn("No .env file found, using system environment variables")
 	}
 
+	cfg, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		slog.Error("failed to load configuration", "error", err)
+		os.Exit(1)
+	}
+	slog.Info("configuration loaded", slog.String("config", cfg.String()))
+
+	redisOpts, err := database.RedisOptions(cfg.Redis)
+	if err != nil {
+		slog.Error("failed to configure redis", "error", err)
+		os.Exit(1)
+	}
+	kafkaTLS, err := cfg.Kafka.TLS.Load()
+	if err != nil {
+		slog.Error("failed to configure kafka", "error", err)
+		os.Exit(1)
+	}
+	cluster := broker.Cluster{
+		Brokers:      cfg.Kafka.Brokers,
+		TLS:          kafkaTLS,
+		DialTimeout:  cfg.Kafka.DialTimeout,
+		BatchTimeout: cfg.Kafka.WriteBatchTimeout,
+	}
+
 	// Resource initialization
-	dbPool := database.NewPostgresPool()
-	cache := database.NewCache("localhost:6379")
-	brokers := []string{"localhost:9092"}
+	dbPool := database.NewPostgresPool(cfg.Postgres)
+	cache := database.NewCache(redisOpts)
 
-	producer := broker.NewProductProducer(brokers, "product_updates")
+	producer := broker.NewProductProducer(cluster, cfg.Kafka.ProductTopic)
+	notificationProducer := broker.NewNotificationProducer(cluster, cfg.Kafka.NotificationTopic)
 	repo := database.NewProductRepo(dbPool)
-	productService := service.NewProductService(repo, producer, cache, logger)
+	historyRepo := database.NewPriceHistoryRepo(dbPool)
+	alertRepo := database.NewAlertRepo(dbPool)
+	subscriptionRepo := database.NewSubscriptionRepo(dbPool)
+
+	// Extraction rules are validated up front, a broken file should stop the deploy
+	rules, err := fetcher.NewRuleRegistry(cfg.Fetcher.RulesPath)
+	if err != nil {
+		slog.Error("failed to load extraction rules", "error", err)
+		os.Exit(1)
+	}
+	slog.Info("extraction rules loaded", slog.Int("hosts", len(rules.Hosts())))
+
+	// Live price streams: the watcher publishes through Redis so every replica's clients see it
+	priceHub := stream.NewHub(stream.DefaultBuffer, logger)
+	priceRelay := stream.NewRedisRelay(redisOpts, priceHub, logger)
+	relayCtx, stopRelay := context.WithCancel(context.Background())
+	relayDone := make(chan struct{})
+	go func() {
+		defer close(relayDone)
+		if err := priceRelay.Run(relayCtx); err != nil && !errors.Is(err, context.Canceled) {
+			slog.Error("price event relay stopped", slog.String("error", err.Error()))
+		}
+	}()
+
+	// Shops are paced per host over all replicas, a slot outlives the slowest request
+	hostLimiter := database.NewRedisHostLimiter(redisOpts, database.HostLimits{
+		Burst:         cfg.Fetcher.HostBurst,
+		MaxConcurrent: cfg.Fetcher.HostConcurrency,
+		SlotTTL:       2 * cfg.Fetcher.Timeout,
+	})
+	httpFetcher := fetcher.NewHTTPFetcher(cfg.Fetcher.Timeout, cfg.Fetcher.UserAgent, rules, hostLimiter, fetcher.Politeness{
+		HostInterval: cfg.Fetcher.HostInterval,
+		MaxWait:      cfg.Fetcher.HostMaxWait,
+		Backoff:      cfg.Fetcher.Backoff,
+		MaxBackoff:   cfg.Fetcher.MaxBackoff,
+		RobotsTTL:    cfg.Fetcher.RobotsTTL,
+		RobotsAgent:  cfg.Fetcher.RobotsAgent,
+		IgnoreRobots: cfg.Fetcher.IgnoreRobots,
+	})
+	// A shop that is down fails its products fast instead of each spending the timeout.
+	// The circuits are shared, the leader defers checks by the failures of all replicas.
+	circuitStore := database.NewRedisCircuits(redisOpts)
+	priceFetcher := fetcher.NewBreakerFetcher(httpFetcher, circuitStore, fetcher.BreakerOptions{
+		FailureThreshold: cfg.Fetcher.CircuitFailures,
+		OpenTimeout:      cfg.Fetcher.CircuitOpenTimeout,
+		HalfOpenRequests: cfg.Fetcher.CircuitTrials,
+	})
+	// Product checks are queued in the outbox with the change that causes them, the relay
+	// publishes them so a Kafka outage delays checks instead of losing them
+	outboxRepo := database.NewOutboxRepo(dbPool)
+	outboxRelay := outbox.NewRelay(outboxRepo, producer, outbox.Options{
+		PollInterval: cfg.Outbox.PollInterval,
+		BatchSize:    cfg.Outbox.BatchSize,
+		MaxBackoff:   cfg.Outbox.MaxBackoff,
+		MaxAttempts:  cfg.Outbox.MaxAttempts,
+		ClaimTimeout: cfg.Outbox.ClaimTimeout,
+		Retention:    cfg.Outbox.Retention,
+	}, logger)
+
+	transactor := database.NewTransactor(dbPool)
+	outboxProducer := outbox.NewProducer(outboxRepo)
+	productService := service.NewProductService(repo, historyRepo, alertRepo, subscriptionRepo, transactor, outboxProducer, notificationProducer, producer, priceRelay, cache, priceFetcher, logger)
+
+	// Every product is re-checked on its own interval, the scheduler queues the checks
+	// through the outbox as well
+	scheduleRepo := database.NewScheduleRepo(dbPool)
+	checkScheduler := scheduler.New(scheduleRepo, transactor, outboxProducer, priceFetcher, scheduler.Options{
+		PollInterval:     cfg.Scheduler.PollInterval,
+		BatchSize:        cfg.Scheduler.BatchSize,
+		DefaultInterval:  cfg.Scheduler.DefaultInterval,
+		JitterPercent:    cfg.Scheduler.JitterPercent,
+		Adaptive:         cfg.Scheduler.Adaptive,
+		MinInterval:      cfg.Scheduler.MinInterval,
+		MaxInterval:      cfg.Scheduler.MaxInterval,
+		VolatilityWindow: cfg.Scheduler.VolatilityWindow,
+	}, logger)
+	scheduleService := service.NewScheduleService(repo, scheduleRepo, checkScheduler, transactor, outboxProducer, logger)
+
+	userRepo := database.NewUserRepo(dbPool)
+	apiKeyRepo := database.NewAPIKeyRepo(dbPool)
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo, userRepo, transactor, logger)
+	userService := service.NewUserService(userRepo, apiKeyService, transactor, logger)
+	// Without it nobody could issue the first admin key
+	if cfg.Bootstrap.AdminAPIKey != "" {
+		if err := apiKeyService.Bootstrap(context.Background(), cfg.Bootstrap.AdminEmail, cfg.Bootstrap.AdminAPIKey); err != nil {
+			slog.Error("failed to bootstrap the admin key", "error", err)
+			os.Exit(1)
+		}
+	}
+	jwtVerifier, err := newJWTVerifier(cfg.JWT)
+	if err != nil {
+		slog.Error("failed to configure jwt", "error", err)
+		os.Exit(1)
+	}
+	authenticator := auth.NewAuthenticator(apiKeyRepo, jwtVerifier, logger)
+
+	notifiers := map[domain.ChannelType]domain.Notifier{
+		domain.ChannelWebhook:  notify.NewWebhookNotifier(cfg.Notify.Timeout),
+		domain.ChannelTelegram: notify.NewTelegramNotifier(cfg.Notify.TelegramBaseURL, cfg.Notify.TelegramBotToken, cfg.Notify.Timeout),
+		domain.ChannelSlack:    notify.NewSlackNotifier(cfg.Notify.SlackBaseURL, cfg.Notify.SlackBotToken, cfg.Notify.Timeout),
+	}
+	if addr := cfg.Notify.SMTPAddr; addr != "" {
+		notifiers[domain.ChannelEmail] = notify.NewSMTPNotifier(addr, cfg.Notify.SMTPFrom, cfg.Notify.SMTPUsername, cfg.Notify.SMTPPassword)
+	}
+	operatorPolicy := domain.AlertPolicy{
+		CooldownMinutes: int(cfg.Notify.OperatorCooldown / time.Minute),
+		Rearm:           cfg.Notify.OperatorRearm,
+		QuietHours: domain.QuietHours{
+			QuietStart: cfg.Notify.OperatorQuietStart,
+			QuietEnd:   cfg.Notify.OperatorQuietEnd,
+			Timezone:   cfg.Notify.OperatorTimezone,
+		},
+	}
+	notificationService := service.NewNotificationService(database.NewChannelRepo(dbPool), database.NewAlertStateRepo(dbPool), notifiers,
+		operatorPolicy, cfg.Notify.DispatchTimeout, logger)
+
+	// Start Background Consumer (Watcher). Updates that keep failing end up on the
+	// dead-letter topic, from where admins can replay them.
+	dlq := broker.NewDeadLetterQueue(cluster, cfg.Kafka.ProductDLQTopic)
+	consumer := broker.NewProductConsumer(cluster, cfg.Kafka.ProductTopic, cfg.Kafka.WatcherGroup, dlq, broker.RetryPolicy{
+		MaxAttempts: cfg.Kafka.RetryMaxAttempts,
+		Backoff:     cfg.Kafka.RetryBackoff,
+		MaxBackoff:  cfg.Kafka.RetryMaxBackoff,
+	}, broker.PoolOptions{
+		Workers:   cfg.Kafka.WatcherWorkers,
+		QueueSize: cfg.Kafka.WatcherQueueSize,
+	})
+	// A check in progress is finished on shutdown, only the retries are abandoned
+	watcherEvents := broker.NewDispatcher()
+	broker.Handle(watcherEvents, func(ctx context.Context, e *desc.PriceCheckRequested) error {
+		return productService.ProcessSingleProduct(context.WithoutCancel(ctx), e.GetProductId())
+	})
+	consumerCtx, stopConsumer := context.WithCancel(context.Background())
+	consumerDone := make(chan struct{})
+	go func() {
+		defer close(consumerDone)
+		slog.Info("Watcher: background consumer started", slog.Int("workers", cfg.Kafka.WatcherWorkers))
+		consumer.Start(consumerCtx, watcherEvents)
+	}()
 
-	// Start Background Consumer (Watcher)
-	consumer := broker.NewProductConsumer(brokers, "product_updates", "watcher-group")
+	// Notifications are delivered by their own consumer group, off the watcher path
+	notificationConsumer := broker.NewNotificationConsumer(cluster, cfg.Kafka.NotificationTopic, cfg.Kafka.NotifierGroup)
 	go func() {
-		slog.Info("Watcher: background consumer started")
-		consumer.Start(context.Background(), func(id int64) error {
-			return productService.ProcessSingleProduct(context.Background(), id)
+		slog.Info("Notifier: background consumer started")
+		notificationConsumer.Start(context.Background(), func(ctx context.Context, n *domain.Notification) error {
+			return notificationService.Dispatch(ctx, n)
 		})
 	}()
 
+	// Periodic jobs run on one replica only, the others keep campaigning and take over
+	// when the leader goes away
+	instanceID := cfg.Leader.InstanceID
+	if instanceID == "" {
+		if instanceID, err = os.Hostname(); err != nil {
+			slog.Error("failed to resolve instance id", "error", err)
+			os.Exit(1)
+		}
+	}
+	var (
+		leaderLock  domain.LeaderLock
+		leaderLease *database.RedisLease
+	)
+	switch cfg.Leader.Backend {
+	case "redis":
+		leaderLease = database.NewRedisLease(redisOpts, cfg.Leader.Name, instanceID, cfg.Leader.LeaseTTL)
+		leaderLock = leaderLease
+	default:
+		leaderLock = database.NewAdvisoryLock(dbPool, cfg.Leader.Name)
+	}
+	elector := leader.New(leaderLock, instanceID, cfg.Leader.RenewInterval, logger)
+	elector.WhileLeading(func(ctx context.Context) {
+		slog.Info("Outbox: relay started")
+		outboxRelay.Run(ctx)
+	})
+	elector.WhileLeading(func(ctx context.Context) {
+		slog.Info("Scheduler: started", slog.Duration("default_interval", cfg.Scheduler.DefaultInterval))
+		checkScheduler.Run(ctx)
+	})
+	// Alerts held back during quiet hours go out once the window closes
+	elector.WhileLeading(func(ctx context.Context) {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := notificationService.FlushDigests(ctx); err != nil {
+					slog.Error("Notifier: digest flush failed", slog.String("error", err.Error()))
+				}
+			}
+		}
+	})
+	electionCtx, stopElection := context.WithCancel(context.Background())
+	electionDone := make(chan struct{})
+	go func() {
+		defer close(electionDone)
+		slog.Info("Leader: campaigning", slog.String("instance", instanceID), slog.String("backend", cfg.Leader.Backend))
+		elector.Run(electionCtx)
+	}()
+
 	// Initialize Handler and wrap Gin into standard http.Server
-	handler := transportHTTP.NewHandler(productService, logger)
+	handler := transportHTTP.NewHandler(productService, userService, apiKeyService, notificationService, scheduleService, authenticator, priceHub, rules, dlq, priceFetcher, logger)
 
+	// No write timeout, it would cut the SSE and WebSocket streams
 	srv := &http.Server{
-		Addr:    ":8080",
-		Handler: handler.InitRoutes(),
+		Addr:              cfg.HTTP.Addr,
+		Handler:           handler.InitRoutes(cfg.HTTP.AllowedOrigins),
+		ReadHeaderTimeout: cfg.HTTP.ReadHeaderTimeout,
+		IdleTimeout:       cfg.HTTP.IdleTimeout,
+	}
+
+	// Metrics and docs are kept off the public port
+	adminSrv := &http.Server{
+		Addr:              cfg.Admin.Addr,
+		Handler:           handler.InitAdminRoutes(cfg.Admin.User, cfg.Admin.Password),
+		ReadHeaderTimeout: cfg.HTTP.ReadHeaderTimeout,
 	}
 
-	lis, err := net.Listen("tcp", ":50051")
+	lis, err := net.Listen("tcp", cfg.GRPC.Addr)
 	if err != nil {
 		slog.Error("failed to listen for gRPC", "error", err)
 		os.Exit(1)
 	}
 
-	sServer := grpc.NewServer()
-	desc.RegisterProductServiceServer(sServer, grpcHandler.NewHandler(productService))
+	sServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			grpcHandler.RequestContextUnaryInterceptor(),
+			grpcHandler.AuthUnaryInterceptor(authenticator, logger),
+		),
+		grpc.ChainStreamInterceptor(
+			grpcHandler.RequestContextStreamInterceptor(),
+			grpcHandler.AuthStreamInterceptor(authenticator, logger),
+		),
+	)
+	desc.RegisterProductServiceServer(sServer, grpcHandler.NewHandler(productService, priceHub))
+	desc.RegisterWatchlistServiceServer(sServer, grpcHandler.NewWatchlistHandler(productService))
 
 	go func() {
-		slog.Info("gRPC server started", slog.String("port", "50051"))
+		slog.Info("gRPC server started", slog.String("addr", cfg.GRPC.Addr))
 		if err := sServer.Serve(lis); err != nil {
 			slog.Error("gRPC server failed", "error", err)
 		}
@@ -74,13 +323,20 @@ func main() {
 
 	// Start HTTP server in a goroutine
 	go func() {
-		slog.Info("Server started", slog.String("port", "8080"))
+		slog.Info("Server started", slog.String("addr", cfg.HTTP.Addr))
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			slog.Error("Failed to run server", slog.String("error", err.Error()))
 			os.Exit(1)
 		}
 	}()
 
+	go func() {
+		slog.Info("Admin server started", slog.String("addr", cfg.Admin.Addr))
+		if err := adminSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			slog.Error("Failed to run admin server", slog.String("error", err.Error()))
+		}
+	}()
+
 	// --- SECTION: GRACEFUL SHUTDOWN ---
 
 	quit := make(chan os.Signal, 1)
@@ -89,26 +345,89 @@ func main() {
 	<-quit
 	slog.Info("Shutting down server...")
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.HTTP.ShutdownTimeout)
 	defer cancel()
 
 	// 1. Shutdown HTTP server gracefully
 	if err := srv.Shutdown(ctx); err != nil {
 		slog.Error("Server forced to shutdown", slog.String("error", err.Error()))
 	}
+	if err := adminSrv.Shutdown(ctx); err != nil {
+		slog.Error("Admin server forced to shutdown", slog.String("error", err.Error()))
+	}
 
-	// 2. Close Kafka Producer
-	if err := producer.Close(); err != nil {
-		slog.Error("Kafka producer close error", slog.String("error", err.Error()))
+	// 2. Step down so another replica takes over the periodic jobs, the outbox relay
+	// among them
+	stopElection()
+	<-electionDone
+	if leaderLease != nil {
+		if err := leaderLease.Close(); err != nil {
+			slog.Error("Redis leader lease close error", slog.String("error", err.Error()))
+		}
 	}
 
-	// 3. Close Kafka Consumer
+	// 3. Close Kafka Consumers once the watcher workers are done, a retry in progress is
+	// abandoned and delivered again. The producer outlives both the relay and the
+	// workers, which announce every check they finish.
+	stopConsumer()
+	<-consumerDone
 	if err := consumer.Close(); err != nil {
 		slog.Error("Kafka consumer close error", slog.String("error", err.Error()))
 	}
+	if err := producer.Close(); err != nil {
+		slog.Error("Kafka producer close error", slog.String("error", err.Error()))
+	}
+	if err := dlq.Close(); err != nil {
+		slog.Error("Kafka dead-letter queue close error", slog.String("error", err.Error()))
+	}
+	if err := notificationConsumer.Close(); err != nil {
+		slog.Error("Kafka notification consumer close error", slog.String("error", err.Error()))
+	}
+	if err := notificationProducer.Close(); err != nil {
+		slog.Error("Kafka notification producer close error", slog.String("error", err.Error()))
+	}
+
+	// The subscription goes before the client it runs on
+	stopRelay()
+	<-relayDone
+	if err := priceRelay.Close(); err != nil {
+		slog.Error("Redis price relay close error", slog.String("error", err.Error()))
+	}
+	if err := hostLimiter.Close(); err != nil {
+		slog.Error("Redis host limiter close error", slog.String("error", err.Error()))
+	}
+	if err := circuitStore.Close(); err != nil {
+		slog.Error("Redis circuit store close error", slog.String("error", err.Error()))
+	}
 
 	// 4. Close Database connection pool
 	dbPool.Close()
 
 	slog.Info("Server exited properly")
 }
+
+// newJWTVerifier builds the verifier from the JWT settings.
+// Without a secret or public key bearer tokens are disabled and only API keys work.
+func newJWTVerifier(jwtCfg config.JWTConfig) (*auth.JWTVerifier, error) {
+	cfg := auth.JWTConfig{
+		HMACSecret: []byte(jwtCfg.HS256Secret),
+		Issuer:     jwtCfg.Issuer,
+		Audience:   jwtCfg.Audience,
+		Leeway:     jwtCfg.Leeway,
+	}
+
+	if path := jwtCfg.RS256PublicKeyFile; path != "" {
+		pem, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.RSAPublicKey, err = auth.LoadRSAPublicKey(pem); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(cfg.HMACSecret) == 0 && cfg.RSAPublicKey == nil {
+		return nil, nil
+	}
+	return auth.NewJWTVerifier(cfg)
+}
@@ -0,0 +1,168 @@
+package alerts
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/derkres11/price-pulse/internal/domain"
+)
+
+// ErrInvalidRule is returned by Validate for rules that can never be evaluated
+var ErrInvalidRule = domain.NewError(domain.ErrInvalidArgument, "invalid alert rule")
+
+// Observation is everything a rule may look at. Previous and AllTimeLow describe
+// the history before Price was observed and are nil for the very first check.
+type Observation struct {
+	ProductID      int64
+	Price          float64
+	InStock        bool
+	ObservedAt     time.Time
+	Previous       *domain.PricePoint
+	AllTimeLow     *domain.PricePoint
+	UnchangedSince time.Time // start of the current run of equal prices, zero if unknown
+}
+
+// Validate checks that the fields required by the rule type are present
+func Validate(r *domain.AlertRule) error {
+	switch r.Type {
+	case domain.AlertThreshold:
+		if r.Threshold <= 0 {
+			return fmt.Errorf("%w: threshold must be positive", ErrInvalidRule)
+		}
+	case domain.AlertPercentDrop:
+		if r.Percent <= 0 || r.Percent >= 100 {
+			return fmt.Errorf("%w: percent must be between 0 and 100", ErrInvalidRule)
+		}
+		if r.Baseline <= 0 {
+			return fmt.Errorf("%w: baseline must be positive", ErrInvalidRule)
+		}
+	case domain.AlertPriceIncrease:
+		if r.Percent < 0 {
+			return fmt.Errorf("%w: percent must not be negative", ErrInvalidRule)
+		}
+	case domain.AlertPriceUnchanged:
+		if r.Days <= 0 {
+			return fmt.Errorf("%w: days must be positive", ErrInvalidRule)
+		}
+	case domain.AlertAllTimeLow, domain.AlertBackInStock:
+	default:
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidRule, r.Type)
+	}
+	return nil
+}
+
+// Evaluate returns an event for every enabled rule the observation satisfies.
+// It has no side effects so it can be tested without a database.
+func Evaluate(rules []*domain.AlertRule, obs Observation) []*domain.AlertEvent {
+	var events []*domain.AlertEvent
+
+	for _, r := range rules {
+		if !r.Enabled {
+			continue
+		}
+
+		reason, ok := match(r, obs)
+		if !ok {
+			continue
+		}
+
+		e := &domain.AlertEvent{
+			RuleID:         r.ID,
+			ProductID:      obs.ProductID,
+			SubscriptionID: r.SubscriptionID,
+			Type:           r.Type,
+			Price:          obs.Price,
+			Reason:         reason,
+			CreatedAt:      obs.ObservedAt,
+		}
+		if obs.Previous != nil {
+			e.PreviousPrice = obs.Previous.Price
+		}
+		events = append(events, e)
+	}
+
+	return events
+}
+
+func match(r *domain.AlertRule, obs Observation) (string, bool) {
+	// Out of stock pages carry no meaningful price, nothing to alert on
+	if !obs.InStock {
+		return "", false
+	}
+
+	switch r.Type {
+	case domain.AlertThreshold:
+		if obs.Price <= r.Threshold {
+			return fmt.Sprintf("price %.2f is at or below target %.2f", obs.Price, r.Threshold), true
+		}
+
+	case domain.AlertPercentDrop:
+		if r.Baseline <= 0 {
+			return "", false
+		}
+		drop := (r.Baseline - obs.Price) / r.Baseline * 100
+		if drop >= r.Percent {
+			return fmt.Sprintf("price %.2f is %.1f%% below baseline %.2f", obs.Price, drop, r.Baseline), true
+		}
+
+	case domain.AlertAllTimeLow:
+		if obs.AllTimeLow != nil && obs.Price < obs.AllTimeLow.Price {
+			return fmt.Sprintf("price %.2f is a new all-time low, previous low was %.2f on %s",
+				obs.Price, obs.AllTimeLow.Price, obs.AllTimeLow.ObservedAt.Format(time.DateOnly)), true
+		}
+
+	case domain.AlertPriceIncrease:
+		prev := obs.Previous
+		if prev == nil || !prev.InStock || prev.Price <= 0 || obs.Price <= prev.Price {
+			return "", false
+		}
+		rise := (obs.Price - prev.Price) / prev.Price * 100
+		if rise >= r.Percent {
+			return fmt.Sprintf("price rose %.1f%% from %.2f to %.2f", rise, prev.Price, obs.Price), true
+		}
+
+	case domain.AlertBackInStock:
+		if obs.Previous != nil && !obs.Previous.InStock {
+			return fmt.Sprintf("back in stock at %.2f", obs.Price), true
+		}
+
+	case domain.AlertPriceUnchanged:
+		if obs.UnchangedSince.IsZero() {
+			return "", false
+		}
+		days := int(obs.ObservedAt.Sub(obs.UnchangedSince).Hours() / 24)
+		if days >= r.Days {
+			return fmt.Sprintf("price has stayed at %.2f for %d days", obs.Price, days), true
+		}
+	}
+
+	return "", false
+}
+
+// TargetPriceRule turns the legacy Product.TargetPrice into a threshold rule
+// so products created before rules existed keep alerting.
+func TargetPriceRule(p *domain.Product) *domain.AlertRule {
+	if p.TargetPrice <= 0 {
+		return nil
+	}
+	return &domain.AlertRule{
+		ProductID: p.ID,
+		Type:      domain.AlertThreshold,
+		Threshold: p.TargetPrice,
+		Enabled:   true,
+	}
+}
+
+// SubscriptionTargetRule is the threshold rule behind a subscription's target price
+func SubscriptionTargetRule(s *domain.Subscription) *domain.AlertRule {
+	if s.TargetPrice <= 0 {
+		return nil
+	}
+	return &domain.AlertRule{
+		ProductID:      s.ProductID,
+		SubscriptionID: s.ID,
+		Type:           domain.AlertThreshold,
+		Threshold:      s.TargetPrice,
+		Enabled:        true,
+	}
+}
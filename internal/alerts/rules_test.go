@@ -0,0 +1,111 @@
+package alerts
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/derkres11/price-pulse/internal/domain"
+)
+
+func TestEvaluate(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	point := func(price float64, inStock bool) *domain.PricePoint {
+		return &domain.PricePoint{Price: price, InStock: inStock, ObservedAt: now.Add(-time.Hour)}
+	}
+
+	tests := []struct {
+		name string
+		rule domain.AlertRule
+		obs  Observation
+		want bool
+	}{
+		{"Threshold hit", domain.AlertRule{Type: domain.AlertThreshold, Threshold: 50},
+			Observation{Price: 49.99, InStock: true}, true},
+		{"Threshold equal", domain.AlertRule{Type: domain.AlertThreshold, Threshold: 50},
+			Observation{Price: 50, InStock: true}, true},
+		{"Threshold miss", domain.AlertRule{Type: domain.AlertThreshold, Threshold: 50},
+			Observation{Price: 50.01, InStock: true}, false},
+		{"Threshold ignores out of stock", domain.AlertRule{Type: domain.AlertThreshold, Threshold: 50},
+			Observation{Price: 10, InStock: false}, false},
+
+		{"Percent drop hit", domain.AlertRule{Type: domain.AlertPercentDrop, Percent: 20, Baseline: 100},
+			Observation{Price: 80, InStock: true}, true},
+		{"Percent drop miss", domain.AlertRule{Type: domain.AlertPercentDrop, Percent: 20, Baseline: 100},
+			Observation{Price: 81, InStock: true}, false},
+
+		{"All-time low hit", domain.AlertRule{Type: domain.AlertAllTimeLow},
+			Observation{Price: 89, InStock: true, AllTimeLow: point(90, true)}, true},
+		{"All-time low tie", domain.AlertRule{Type: domain.AlertAllTimeLow},
+			Observation{Price: 90, InStock: true, AllTimeLow: point(90, true)}, false},
+		{"All-time low first check", domain.AlertRule{Type: domain.AlertAllTimeLow},
+			Observation{Price: 90, InStock: true}, false},
+
+		{"Any increase", domain.AlertRule{Type: domain.AlertPriceIncrease},
+			Observation{Price: 101, InStock: true, Previous: point(100, true)}, true},
+		{"Increase below percent", domain.AlertRule{Type: domain.AlertPriceIncrease, Percent: 5},
+			Observation{Price: 104, InStock: true, Previous: point(100, true)}, false},
+		{"Increase after restock is not a rise", domain.AlertRule{Type: domain.AlertPriceIncrease},
+			Observation{Price: 120, InStock: true, Previous: point(0, false)}, false},
+
+		{"Back in stock", domain.AlertRule{Type: domain.AlertBackInStock},
+			Observation{Price: 120, InStock: true, Previous: point(0, false)}, true},
+		{"Still in stock", domain.AlertRule{Type: domain.AlertBackInStock},
+			Observation{Price: 120, InStock: true, Previous: point(120, true)}, false},
+
+		{"Unchanged long enough", domain.AlertRule{Type: domain.AlertPriceUnchanged, Days: 7},
+			Observation{Price: 10, InStock: true, ObservedAt: now, UnchangedSince: now.AddDate(0, 0, -7)}, true},
+		{"Unchanged too short", domain.AlertRule{Type: domain.AlertPriceUnchanged, Days: 7},
+			Observation{Price: 10, InStock: true, ObservedAt: now, UnchangedSince: now.AddDate(0, 0, -6)}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.rule.ID = 7
+			tt.rule.Enabled = true
+
+			events := Evaluate([]*domain.AlertRule{&tt.rule}, tt.obs)
+			if got := len(events) == 1; got != tt.want {
+				t.Fatalf("expected match: %v, got events: %+v", tt.want, events)
+			}
+			if tt.want && (events[0].RuleID != 7 || events[0].Reason == "") {
+				t.Errorf("expected event for rule 7 with a reason, got %+v", events[0])
+			}
+		})
+	}
+}
+
+func TestEvaluate_DisabledRule(t *testing.T) {
+	rules := []*domain.AlertRule{{Type: domain.AlertThreshold, Threshold: 50, Enabled: false}}
+	if events := Evaluate(rules, Observation{Price: 1, InStock: true}); len(events) != 0 {
+		t.Errorf("expected disabled rule to be skipped, got %+v", events)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    domain.AlertRule
+		wantErr bool
+	}{
+		{"Threshold ok", domain.AlertRule{Type: domain.AlertThreshold, Threshold: 10}, false},
+		{"Threshold missing", domain.AlertRule{Type: domain.AlertThreshold}, true},
+		{"Percent drop without baseline", domain.AlertRule{Type: domain.AlertPercentDrop, Percent: 10}, true},
+		{"Percent drop over 100", domain.AlertRule{Type: domain.AlertPercentDrop, Percent: 120, Baseline: 5}, true},
+		{"Unchanged without days", domain.AlertRule{Type: domain.AlertPriceUnchanged}, true},
+		{"Back in stock needs nothing", domain.AlertRule{Type: domain.AlertBackInStock}, false},
+		{"Unknown type", domain.AlertRule{Type: "moon_phase"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(&tt.rule)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expected error: %v, got: %v", tt.wantErr, err)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidRule) {
+				t.Errorf("expected ErrInvalidRule, got: %v", err)
+			}
+		})
+	}
+}
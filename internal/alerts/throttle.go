@@ -0,0 +1,118 @@
+package alerts
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/derkres11/price-pulse/internal/domain"
+)
+
+// ErrInvalidPolicy is returned by ValidatePolicy for delivery settings that cannot be applied
+var ErrInvalidPolicy = domain.NewError(domain.ErrInvalidArgument, "invalid delivery policy")
+
+// Decision is what should happen to an alert for a given subscription
+type Decision int
+
+const (
+	Deliver  Decision = iota
+	Suppress          // repeat of something the subscriber was already told
+	Defer             // quiet hours, goes into the digest
+)
+
+// ValidatePolicy checks the cooldown and quiet hours of a policy
+func ValidatePolicy(p *domain.AlertPolicy) error {
+	if p.CooldownMinutes < 0 {
+		return fmt.Errorf("%w: cooldown must not be negative", ErrInvalidPolicy)
+	}
+	return ValidateQuietHours(&p.QuietHours)
+}
+
+// ValidateQuietHours checks the window and timezone of quiet hours
+func ValidateQuietHours(q *domain.QuietHours) error {
+	if (q.QuietStart == "") != (q.QuietEnd == "") {
+		return fmt.Errorf("%w: quiet hours need both start and end", ErrInvalidPolicy)
+	}
+	if q.QuietStart != "" {
+		if _, err := time.Parse("15:04", q.QuietStart); err != nil {
+			return fmt.Errorf("%w: quiet_start must be HH:MM", ErrInvalidPolicy)
+		}
+		if _, err := time.Parse("15:04", q.QuietEnd); err != nil {
+			return fmt.Errorf("%w: quiet_end must be HH:MM", ErrInvalidPolicy)
+		}
+	}
+	if _, err := time.LoadLocation(q.Timezone); err != nil {
+		return fmt.Errorf("%w: unknown timezone %q", ErrInvalidPolicy, q.Timezone)
+	}
+	return nil
+}
+
+// Throttle decides whether an alert reaches the subscriber now, later or not at all.
+// st is the subscription's state for the rule, nil if it never alerted on it.
+func Throttle(st *domain.AlertState, e *domain.AlertEvent, p *domain.AlertPolicy, now time.Time) (Decision, string) {
+	if st != nil && now.Before(st.ClaimedUntil) {
+		return Suppress, "being delivered"
+	}
+	if st != nil && !st.LastSentAt.IsZero() {
+		switch {
+		case p.Rearm && !st.Armed:
+			return Suppress, "waiting for the condition to clear"
+		case now.Sub(st.LastSentAt) < time.Duration(p.CooldownMinutes)*time.Minute:
+			return Suppress, "cooldown"
+		case !p.Rearm && !st.Armed && e.Price == st.LastPrice:
+			return Suppress, "price already reported"
+		}
+	}
+
+	if InQuietHours(&p.QuietHours, now) {
+		return Defer, "quiet hours"
+	}
+	return Deliver, ""
+}
+
+// InQuietHours reports whether now falls into the quiet window.
+// Windows wrap around midnight when the start is later than the end.
+func InQuietHours(q *domain.QuietHours, now time.Time) bool {
+	if q.QuietStart == "" || q.QuietEnd == "" {
+		return false
+	}
+	start, err1 := time.Parse("15:04", q.QuietStart)
+	end, err2 := time.Parse("15:04", q.QuietEnd)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+
+	if loc, err := time.LoadLocation(q.Timezone); err == nil {
+		now = now.In(loc)
+	}
+
+	minute := func(t time.Time) int { return t.Hour()*60 + t.Minute() }
+	cur, from, to := minute(now), minute(start), minute(end)
+
+	if from <= to {
+		return cur >= from && cur < to
+	}
+	return cur >= from || cur < to
+}
+
+// Claim is the state that marks the alert as being delivered until the given time.
+// Throttle suppresses the same alert meanwhile, so only one replica sends it.
+func Claim(st *domain.AlertState, e *domain.AlertEvent, until time.Time) *domain.AlertState {
+	claim := &domain.AlertState{SubscriptionID: e.SubscriptionID, ProductID: e.ProductID, RuleID: e.RuleID, Armed: true}
+	if st != nil {
+		*claim = *st
+	}
+	claim.ClaimedUntil = until
+	return claim
+}
+
+// Sent is the state to store after the alert was delivered or queued for the digest
+func Sent(e *domain.AlertEvent, now time.Time) *domain.AlertState {
+	return &domain.AlertState{
+		SubscriptionID: e.SubscriptionID,
+		ProductID:      e.ProductID,
+		RuleID:         e.RuleID,
+		LastPrice:      e.Price,
+		LastSentAt:     now,
+		Armed:          false,
+	}
+}
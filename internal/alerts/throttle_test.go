@@ -0,0 +1,101 @@
+package alerts
+
+import (
+	"testing"
+	"time"
+
+	"github.com/derkres11/price-pulse/internal/domain"
+)
+
+func TestThrottle(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	event := &domain.AlertEvent{ProductID: 1, RuleID: 7, Type: domain.AlertThreshold, Price: 79}
+	sent := func(ago time.Duration, price float64, armed bool) *domain.AlertState {
+		return &domain.AlertState{LastSentAt: now.Add(-ago), LastPrice: price, Armed: armed}
+	}
+
+	rearm := &domain.AlertPolicy{CooldownMinutes: 60, Rearm: true}
+	plain := &domain.AlertPolicy{CooldownMinutes: 60}
+	quiet := &domain.AlertPolicy{QuietHours: domain.QuietHours{QuietStart: "11:00", QuietEnd: "13:00", Timezone: "UTC"}}
+	claimed := &domain.AlertState{Armed: true, ClaimedUntil: now.Add(time.Second)}
+	expired := &domain.AlertState{Armed: true, ClaimedUntil: now.Add(-time.Second)}
+
+	tests := []struct {
+		name string
+		st   *domain.AlertState
+		p    *domain.AlertPolicy
+		want Decision
+	}{
+		{"First alert", nil, rearm, Deliver},
+		{"Still below target", sent(3*time.Hour, 79, false), rearm, Suppress},
+		{"Re-armed after rising", sent(3*time.Hour, 79, true), rearm, Deliver},
+		{"Re-armed within cooldown", sent(10*time.Minute, 79, true), rearm, Suppress},
+		{"Same price without hysteresis", sent(3*time.Hour, 79, false), plain, Suppress},
+		{"Lower price without hysteresis", sent(3*time.Hour, 85, false), plain, Deliver},
+		{"Cooldown without hysteresis", sent(10*time.Minute, 85, false), plain, Suppress},
+		{"Quiet hours", nil, quiet, Defer},
+		{"Repeat during quiet hours", sent(time.Hour, 79, false), &domain.AlertPolicy{Rearm: true, QuietHours: quiet.QuietHours}, Suppress},
+		{"Being delivered elsewhere", claimed, rearm, Suppress},
+		{"Claim ran out", expired, rearm, Deliver},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, reason := Throttle(tt.st, event, tt.p, now); got != tt.want {
+				t.Errorf("expected %v, got %v (%s)", tt.want, got, reason)
+			}
+		})
+	}
+}
+
+func TestInQuietHours(t *testing.T) {
+	at := func(h, m int) time.Time { return time.Date(2024, 3, 10, h, m, 0, 0, time.UTC) }
+
+	overnight := &domain.QuietHours{QuietStart: "22:00", QuietEnd: "07:30", Timezone: "UTC"}
+	berlin := &domain.QuietHours{QuietStart: "22:00", QuietEnd: "07:30", Timezone: "Europe/Berlin"}
+
+	tests := []struct {
+		name string
+		q    *domain.QuietHours
+		now  time.Time
+		want bool
+	}{
+		{"Late evening", overnight, at(23, 15), true},
+		{"Early morning", overnight, at(7, 29), true},
+		{"Window end is exclusive", overnight, at(7, 30), false},
+		{"Afternoon", overnight, at(15, 0), false},
+		{"Local time is used", berlin, at(21, 30), true}, // 22:30 in Berlin
+		{"No window", &domain.QuietHours{}, at(23, 0), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := InQuietHours(tt.q, tt.now); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestValidatePolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		p       *domain.AlertPolicy
+		wantErr bool
+	}{
+		{"Defaults", &domain.AlertPolicy{QuietHours: domain.QuietHours{Timezone: "UTC"}}, false},
+		{"Quiet hours", &domain.AlertPolicy{QuietHours: domain.QuietHours{QuietStart: "22:00", QuietEnd: "07:00", Timezone: "Europe/Berlin"}}, false},
+		{"Half a window", &domain.AlertPolicy{QuietHours: domain.QuietHours{QuietStart: "22:00"}}, true},
+		{"Bad clock", &domain.AlertPolicy{QuietHours: domain.QuietHours{QuietStart: "10pm", QuietEnd: "07:00"}}, true},
+		{"Unknown timezone", &domain.AlertPolicy{QuietHours: domain.QuietHours{Timezone: "Mars/Olympus"}}, true},
+		{"Negative cooldown", &domain.AlertPolicy{CooldownMinutes: -1}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidatePolicy(tt.p); (err != nil) != tt.wantErr {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
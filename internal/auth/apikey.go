@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// APIKeyPrefix marks API keys, anything else in a bearer header is treated as a JWT
+const APIKeyPrefix = "pp_"
+
+// GenerateAPIKey returns a new key as "pp_<prefix>_<secret>" together with the
+// lookup prefix and the hash to store. The key itself is never stored.
+func GenerateAPIKey() (key, prefix, hash string, err error) {
+	id := make([]byte, 6)
+	secret := make([]byte, 32)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+
+	prefix = hex.EncodeToString(id)
+	key = APIKeyPrefix + prefix + "_" + base64.RawURLEncoding.EncodeToString(secret)
+	return key, prefix, HashAPIKey(key), nil
+}
+
+// ParseAPIKey extracts the lookup prefix of a key
+func ParseAPIKey(key string) (string, bool) {
+	rest, ok := strings.CutPrefix(key, APIKeyPrefix)
+	if !ok {
+		return "", false
+	}
+	prefix, secret, ok := strings.Cut(rest, "_")
+	if !ok || len(prefix) != 12 || secret == "" {
+		return "", false
+	}
+	return prefix, true
+}
+
+// HashAPIKey is a plain SHA-256: keys carry 256 random bits, so there is nothing to stretch
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
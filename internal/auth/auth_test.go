@@ -0,0 +1,213 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"log/slog"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/derkres11/price-pulse/internal/domain"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// keysMock matches domain.APIKeyRepository interface
+type keysMock struct {
+	keys    map[string]*domain.APIKey
+	touched int
+}
+
+func (m *keysMock) Create(ctx context.Context, k *domain.APIKey) error {
+	k.ID = int64(len(m.keys) + 1)
+	m.keys[k.Prefix] = k
+	return nil
+}
+
+func (m *keysMock) GetByPrefix(ctx context.Context, prefix string) (*domain.APIKey, error) {
+	k, ok := m.keys[prefix]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return k, nil
+}
+
+func (m *keysMock) ListByUser(ctx context.Context, userID int64) ([]*domain.APIKey, error) {
+	return nil, nil
+}
+
+func (m *keysMock) Revoke(ctx context.Context, userID, id int64) error {
+	return nil
+}
+
+func (m *keysMock) Touch(ctx context.Context, id int64) error {
+	m.touched++
+	return nil
+}
+
+func newKey(t *testing.T, m *keysMock, userID int64, admin bool) (string, *domain.APIKey) {
+	t.Helper()
+	plain, prefix, hash, err := GenerateAPIKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	k := &domain.APIKey{UserID: userID, Prefix: prefix, Hash: hash, Admin: admin}
+	_ = m.Create(context.Background(), k)
+	return plain, k
+}
+
+func TestAuthenticator_APIKey(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	keys := &keysMock{keys: make(map[string]*domain.APIKey)}
+	a := NewAuthenticator(keys, nil, logger)
+
+	valid, _ := newKey(t, keys, 7, false)
+	admin, _ := newKey(t, keys, 1, true)
+	revoked, revokedKey := newKey(t, keys, 7, false)
+	now := time.Now()
+	revokedKey.RevokedAt = &now
+
+	prefix, _ := ParseAPIKey(valid)
+	forged := APIKeyPrefix + prefix + "_not-the-secret"
+
+	tests := []struct {
+		name      string
+		key       string
+		wantUser  int64
+		wantAdmin bool
+		wantErr   bool
+	}{
+		{"Valid key", valid, 7, false, false},
+		{"Admin key", admin, 1, true, false},
+		{"Revoked key", revoked, 0, false, true},
+		{"Right prefix, wrong secret", forged, 0, false, true},
+		{"Malformed key", "pp_short", 0, false, true},
+		{"Unknown key", APIKeyPrefix + "000000000000_secret", 0, false, true},
+		{"JWT without verifier", "eyJhbGciOiJIUzI1NiJ9.e30.sig", 0, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := a.Authenticate(context.Background(), tt.key)
+			if tt.wantErr {
+				if !errors.Is(err, ErrUnauthenticated) {
+					t.Errorf("expected ErrUnauthenticated, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if p.UserID != tt.wantUser || p.Admin != tt.wantAdmin || p.Method != domain.AuthAPIKey {
+				t.Errorf("unexpected principal %+v", p)
+			}
+		})
+	}
+
+	if keys.touched != 2 {
+		t.Errorf("expected the two successful logins to record usage, got %d", keys.touched)
+	}
+}
+
+func TestJWTVerifier(t *testing.T) {
+	secret := []byte("hs-secret")
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, _ := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	pub, err := LoadRSAPublicKey(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := NewJWTVerifier(JWTConfig{HMACSecret: secret, RSAPublicKey: pub, Issuer: "idp", Audience: "price-pulse"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims := func(sub int64, role string, exp time.Duration) Claims {
+		return Claims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Subject:   strconv.FormatInt(sub, 10),
+				Issuer:    "idp",
+				Audience:  jwt.ClaimStrings{"price-pulse"},
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(exp)),
+			},
+			Role: role,
+		}
+	}
+	sign := func(method jwt.SigningMethod, key any, c Claims) string {
+		s, err := jwt.NewWithClaims(method, c).SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+
+	wrongIssuer := claims(5, "", time.Hour)
+	wrongIssuer.Issuer = "someone-else"
+	noSubject := claims(5, "", time.Hour)
+	noSubject.Subject = "alice"
+
+	tests := []struct {
+		name      string
+		token     string
+		wantUser  int64
+		wantAdmin bool
+		wantErr   bool
+	}{
+		{"HS256", sign(jwt.SigningMethodHS256, secret, claims(5, "", time.Hour)), 5, false, false},
+		{"RS256 admin", sign(jwt.SigningMethodRS256, rsaKey, claims(1, "admin", time.Hour)), 1, true, false},
+		{"Expired", sign(jwt.SigningMethodHS256, secret, claims(5, "", -time.Hour)), 0, false, true},
+		{"Wrong secret", sign(jwt.SigningMethodHS256, []byte("other"), claims(5, "", time.Hour)), 0, false, true},
+		{"Unaccepted algorithm", sign(jwt.SigningMethodHS512, secret, claims(5, "", time.Hour)), 0, false, true},
+		{"Wrong issuer", sign(jwt.SigningMethodHS256, secret, wrongIssuer), 0, false, true},
+		{"Subject is not a user id", sign(jwt.SigningMethodHS256, secret, noSubject), 0, false, true},
+		{"Garbage", "not.a.token", 0, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := v.Verify(tt.token)
+			if tt.wantErr {
+				if !errors.Is(err, ErrUnauthenticated) {
+					t.Errorf("expected ErrUnauthenticated, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if p.UserID != tt.wantUser || p.Admin != tt.wantAdmin || p.Method != domain.AuthJWT {
+				t.Errorf("unexpected principal %+v", p)
+			}
+		})
+	}
+}
+
+func TestJWTVerifier_HMACOnlyRejectsRS256(t *testing.T) {
+	v, err := NewJWTVerifier(JWTConfig{HMACSecret: []byte("hs-secret")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	token, _ := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.RegisteredClaims{
+		Subject:   "5",
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}).SignedString(rsaKey)
+
+	if _, err := v.Verify(token); !errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("expected RS256 to be rejected without a public key, got %v", err)
+	}
+
+	if _, err := NewJWTVerifier(JWTConfig{}); err == nil {
+		t.Error("expected a verifier without keys to be refused")
+	}
+}
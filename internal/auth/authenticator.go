@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/derkres11/price-pulse/internal/domain"
+)
+
+// ErrUnauthenticated is returned for missing, malformed, expired or revoked credentials
+var ErrUnauthenticated = errors.New("unauthenticated")
+
+// touchInterval limits last_used_at writes to one per key and minute
+const touchInterval = time.Minute
+
+// Authenticator turns a bearer credential into a principal
+type Authenticator struct {
+	keys   domain.APIKeyRepository
+	jwt    *JWTVerifier // nil when no JWT keys are configured
+	logger *slog.Logger
+}
+
+func NewAuthenticator(keys domain.APIKeyRepository, jwt *JWTVerifier, logger *slog.Logger) *Authenticator {
+	return &Authenticator{
+		keys:   keys,
+		jwt:    jwt,
+		logger: logger,
+	}
+}
+
+// BearerToken extracts the credential of an "Authorization: Bearer ..." value
+func BearerToken(header string) (string, bool) {
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// Authenticate accepts an API key or a JWT. Errors other than ErrUnauthenticated
+// mean the credential could not be checked at all.
+func (a *Authenticator) Authenticate(ctx context.Context, credential string) (*domain.Principal, error) {
+	if strings.HasPrefix(credential, APIKeyPrefix) {
+		return a.apiKey(ctx, credential)
+	}
+	if a.jwt == nil {
+		return nil, fmt.Errorf("%w: bearer tokens are not enabled", ErrUnauthenticated)
+	}
+	return a.jwt.Verify(credential)
+}
+
+func (a *Authenticator) apiKey(ctx context.Context, key string) (*domain.Principal, error) {
+	prefix, ok := ParseAPIKey(key)
+	if !ok {
+		return nil, fmt.Errorf("%w: malformed api key", ErrUnauthenticated)
+	}
+
+	stored, err := a.keys.GetByPrefix(ctx, prefix)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, fmt.Errorf("%w: unknown api key", ErrUnauthenticated)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error loading api key: %w", err)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(stored.Hash), []byte(HashAPIKey(key))) != 1 {
+		return nil, fmt.Errorf("%w: unknown api key", ErrUnauthenticated)
+	}
+	if stored.RevokedAt != nil {
+		return nil, fmt.Errorf("%w: api key revoked", ErrUnauthenticated)
+	}
+
+	if stored.LastUsedAt == nil || time.Since(*stored.LastUsedAt) > touchInterval {
+		if err := a.keys.Touch(ctx, stored.ID); err != nil {
+			a.logger.Warn("failed to update api key usage", slog.Int64("key_id", stored.ID), slog.String("error", err.Error()))
+		}
+	}
+
+	return &domain.Principal{
+		UserID: stored.UserID,
+		Admin:  stored.Admin,
+		Method: domain.AuthAPIKey,
+		KeyID:  stored.ID,
+	}, nil
+}
@@ -0,0 +1,97 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/derkres11/price-pulse/internal/domain"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWTConfig configures token verification. Tokens are issued elsewhere; HS256 needs
+// HMACSecret, RS256 needs RSAPublicKey, and only the configured algorithms are accepted.
+type JWTConfig struct {
+	HMACSecret   []byte
+	RSAPublicKey *rsa.PublicKey
+	Issuer       string
+	Audience     string
+	Leeway       time.Duration
+}
+
+// Claims are the token claims we read. The subject is the user id, role "admin"
+// grants access to the admin routes.
+type Claims struct {
+	jwt.RegisteredClaims
+	Role string `json:"role,omitempty"`
+}
+
+type JWTVerifier struct {
+	cfg    JWTConfig
+	parser *jwt.Parser
+}
+
+func NewJWTVerifier(cfg JWTConfig) (*JWTVerifier, error) {
+	var methods []string
+	if len(cfg.HMACSecret) > 0 {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	if cfg.RSAPublicKey != nil {
+		methods = append(methods, jwt.SigningMethodRS256.Alg())
+	}
+	if len(methods) == 0 {
+		return nil, errors.New("jwt: neither an HS256 secret nor an RS256 public key is configured")
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(methods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(cfg.Leeway),
+	}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.Audience))
+	}
+
+	return &JWTVerifier{cfg: cfg, parser: jwt.NewParser(opts...)}, nil
+}
+
+// LoadRSAPublicKey parses a PEM encoded RSA public key
+func LoadRSAPublicKey(pem []byte) (*rsa.PublicKey, error) {
+	return jwt.ParseRSAPublicKeyFromPEM(pem)
+}
+
+// Verify checks the signature and claims of a token and returns its principal
+func (v *JWTVerifier) Verify(token string) (*domain.Principal, error) {
+	claims := &Claims{}
+	_, err := v.parser.ParseWithClaims(token, claims, v.key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnauthenticated, err)
+	}
+
+	userID, err := strconv.ParseInt(claims.Subject, 10, 64)
+	if err != nil || userID <= 0 {
+		return nil, fmt.Errorf("%w: subject must be a user id", ErrUnauthenticated)
+	}
+
+	return &domain.Principal{
+		UserID: userID,
+		Admin:  claims.Role == "admin",
+		Method: domain.AuthJWT,
+	}, nil
+}
+
+// key picks the verification key by algorithm, the key type never follows the token's say-so
+func (v *JWTVerifier) key(t *jwt.Token) (any, error) {
+	switch t.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		return v.cfg.HMACSecret, nil
+	case *jwt.SigningMethodRSA:
+		return v.cfg.RSAPublicKey, nil
+	}
+	return nil, fmt.Errorf("unexpected signing method %s", t.Method.Alg())
+}
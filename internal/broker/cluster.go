@@ -0,0 +1,46 @@
+package broker
+
+import (
+	"crypto/tls"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Cluster is how producers and consumers reach Kafka
+type Cluster struct {
+	Brokers      []string
+	TLS          *tls.Config // nil for plaintext
+	DialTimeout  time.Duration
+	BatchTimeout time.Duration // how long a writer waits to fill a batch
+}
+
+func (c Cluster) writer(topic string) *kafka.Writer {
+	return &kafka.Writer{
+		Addr:         kafka.TCP(c.Brokers...),
+		Topic:        topic,
+		Balancer:     &kafka.Hash{}, // one key, one partition; unkeyed messages go round robin
+		BatchTimeout: c.BatchTimeout,
+		Transport: &kafka.Transport{
+			DialTimeout: c.DialTimeout,
+			TLS:         c.TLS,
+		},
+	}
+}
+
+func (c Cluster) reader(topic, groupID string) *kafka.Reader {
+	return kafka.NewReader(kafka.ReaderConfig{
+		Brokers: c.Brokers,
+		Topic:   topic,
+		GroupID: groupID,
+		Dialer:  c.dialer(),
+	})
+}
+
+func (c Cluster) dialer() *kafka.Dialer {
+	return &kafka.Dialer{
+		Timeout:   c.DialTimeout,
+		DualStack: true,
+		TLS:       c.TLS,
+	}
+}
@@ -0,0 +1,231 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/derkres11/price-pulse/internal/domain"
+	"github.com/segmentio/kafka-go"
+)
+
+// Headers a dead letter carries next to the original key and value
+const (
+	HeaderError     = "x-error"
+	HeaderAttempts  = "x-attempts"
+	HeaderTopic     = "x-original-topic"
+	HeaderPartition = "x-original-partition"
+	HeaderOffset    = "x-original-offset"
+	HeaderFailedAt  = "x-failed-at"
+	HeaderReplayOf  = "x-replay-of" // dead-letter partition/offset of a replayed message
+)
+
+// maxDeadLetterBytes bounds one fetch while listing, dead letters are small task messages
+const maxDeadLetterBytes = 10 << 20
+
+// RetryPolicy is how often a consumer retries a message before dead-lettering it.
+// The delay doubles per attempt, starting at Backoff and capped at MaxBackoff.
+type RetryPolicy struct {
+	MaxAttempts int
+	Backoff     time.Duration
+	MaxBackoff  time.Duration
+}
+
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	d := p.Backoff
+	for i := 1; i < attempt && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, p.MaxBackoff)
+}
+
+// DeadLetterQueue parks messages that kept failing on their own topic, where they can be
+// listed and replayed to the topic they came from.
+type DeadLetterQueue struct {
+	cluster Cluster
+	topic   string
+	writer  *kafka.Writer
+}
+
+func NewDeadLetterQueue(cluster Cluster, topic string) *DeadLetterQueue {
+	return &DeadLetterQueue{
+		cluster: cluster,
+		topic:   topic,
+		writer:  cluster.writer(""), // the topic is set per message, replays go elsewhere
+	}
+}
+
+// send copies msg to the dead-letter topic with the reason in its headers
+func (q *DeadLetterQueue) send(ctx context.Context, msg kafka.Message, attempts int, cause error) error {
+	headers := append(withoutHeaders(msg.Headers, deadLetterHeaders...),
+		kafka.Header{Key: HeaderError, Value: []byte(cause.Error())},
+		kafka.Header{Key: HeaderAttempts, Value: []byte(strconv.Itoa(attempts))},
+		kafka.Header{Key: HeaderTopic, Value: []byte(msg.Topic)},
+		kafka.Header{Key: HeaderPartition, Value: []byte(strconv.Itoa(msg.Partition))},
+		kafka.Header{Key: HeaderOffset, Value: []byte(strconv.FormatInt(msg.Offset, 10))},
+		kafka.Header{Key: HeaderFailedAt, Value: []byte(time.Now().UTC().Format(time.RFC3339))},
+	)
+
+	err := q.writer.WriteMessages(ctx, kafka.Message{
+		Topic:   q.topic,
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Headers: headers,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write dead letter: %w", err)
+	}
+	return nil
+}
+
+func (q *DeadLetterQueue) List(ctx context.Context, limit int) ([]*domain.DeadLetter, error) {
+	conn, err := q.cluster.dialer().DialContext(ctx, "tcp", q.cluster.Brokers[0])
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to reach kafka: %w", domain.ErrUnavailable, err)
+	}
+	partitions, err := conn.ReadPartitions(q.topic)
+	conn.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dead-letter partitions: %w", err)
+	}
+
+	var out []*domain.DeadLetter
+	for _, p := range partitions {
+		msgs, err := q.read(ctx, p.ID, -int64(limit), limit)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range msgs {
+			out = append(out, toDeadLetter(m))
+		}
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].FailedAt.After(out[j].FailedAt) })
+	return out, nil
+}
+
+func (q *DeadLetterQueue) Replay(ctx context.Context, partition int, offset int64) error {
+	msgs, err := q.read(ctx, partition, offset, 1)
+	if err != nil {
+		return err
+	}
+	if len(msgs) == 0 || msgs[0].Offset != offset {
+		return fmt.Errorf("%w: no dead letter at %d/%d", domain.ErrNotFound, partition, offset)
+	}
+
+	dl := msgs[0]
+	topic := header(dl.Headers, HeaderTopic)
+	if topic == "" {
+		return fmt.Errorf("%w: dead letter %d/%d has no original topic", domain.ErrInvalidArgument, partition, offset)
+	}
+
+	err = q.writer.WriteMessages(ctx, kafka.Message{
+		Topic: topic,
+		Key:   dl.Key,
+		Value: dl.Value,
+		Headers: append(withoutHeaders(dl.Headers, replayHeaders...),
+			kafka.Header{Key: HeaderReplayOf, Value: fmt.Appendf(nil, "%d/%d", partition, offset)}),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to replay dead letter: %w", err)
+	}
+	return nil
+}
+
+// read returns up to n messages of a partition from offset on. A negative offset counts
+// back from the end.
+func (q *DeadLetterQueue) read(ctx context.Context, partition int, offset int64, n int) ([]kafka.Message, error) {
+	conn, err := q.cluster.dialer().DialLeader(ctx, "tcp", q.cluster.Brokers[0], q.topic, partition)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to reach kafka: %w", domain.ErrUnavailable, err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	} else {
+		conn.SetDeadline(time.Now().Add(q.cluster.DialTimeout))
+	}
+
+	first, last, err := conn.ReadOffsets()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dead-letter offsets: %w", err)
+	}
+	if offset < 0 {
+		offset = last + offset
+	}
+	offset = max(offset, first)
+	if offset >= last {
+		return nil, nil
+	}
+	if _, err := conn.Seek(offset, kafka.SeekAbsolute); err != nil {
+		return nil, fmt.Errorf("failed to seek dead letters: %w", err)
+	}
+
+	batch := conn.ReadBatch(1, maxDeadLetterBytes)
+	defer batch.Close()
+
+	var msgs []kafka.Message
+	for len(msgs) < n && offset+int64(len(msgs)) < last {
+		m, err := batch.ReadMessage()
+		if err != nil {
+			if errors.Is(err, io.EOF) && len(msgs) > 0 {
+				break // the rest did not fit into one fetch
+			}
+			return nil, fmt.Errorf("failed to read dead letter: %w", err)
+		}
+		msgs = append(msgs, m)
+	}
+	return msgs, nil
+}
+
+func (q *DeadLetterQueue) Close() error {
+	return q.writer.Close()
+}
+
+func toDeadLetter(m kafka.Message) *domain.DeadLetter {
+	dl := &domain.DeadLetter{
+		Partition: m.Partition,
+		Offset:    m.Offset,
+		Topic:     header(m.Headers, HeaderTopic),
+		Key:       string(m.Key),
+		Value:     string(m.Value),
+		Error:     header(m.Headers, HeaderError),
+		FailedAt:  m.Time,
+	}
+	dl.Attempts, _ = strconv.Atoi(header(m.Headers, HeaderAttempts))
+	if t, err := time.Parse(time.RFC3339, header(m.Headers, HeaderFailedAt)); err == nil {
+		dl.FailedAt = t
+	}
+	return dl
+}
+
+func header(headers []kafka.Header, key string) string {
+	for _, h := range headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+var (
+	deadLetterHeaders = []string{HeaderError, HeaderAttempts, HeaderTopic, HeaderPartition, HeaderOffset, HeaderFailedAt}
+	replayHeaders     = append(slices.Clip(deadLetterHeaders), HeaderReplayOf)
+)
+
+// withoutHeaders keeps the headers of the producer, a message that fails again after
+// a replay gets fresh dead-letter headers
+func withoutHeaders(headers []kafka.Header, keys ...string) []kafka.Header {
+	out := make([]kafka.Header, 0, len(headers)+len(deadLetterHeaders))
+	for _, h := range headers {
+		if !slices.Contains(keys, h.Key) {
+			out = append(out, h)
+		}
+	}
+	return out
+}
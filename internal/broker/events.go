@@ -0,0 +1,149 @@
+package broker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/derkres11/price-pulse/internal/domain"
+	v1 "github.com/derkres11/price-pulse/pkg/api/v1"
+	"github.com/segmentio/kafka-go"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// Headers describing the event in a message
+const (
+	HeaderEventType     = "x-event-type" // full message name, e.g. v1.PriceCheckRequested
+	HeaderSchemaVersion = "x-schema-version"
+	HeaderContentType   = "content-type"
+	HeaderRequestID     = "x-request-id"  // of the request that caused the event
+	HeaderTraceparent   = "traceparent"   // W3C trace context of that request
+	HeaderProducedAt    = "x-produced-at" // RFC 3339 with nanoseconds
+)
+
+// SchemaVersion is the version of the events in proto/events.proto. It changes only
+// with a breaking change, added fields keep it.
+const SchemaVersion = "1"
+
+const contentTypeProtobuf = "application/x-protobuf"
+
+// errUnknownEvent is an event type this build has no schema for, typically one added
+// by a newer producer
+var errUnknownEvent = errors.New("unknown event type")
+
+// Event is one of the messages in proto/events.proto. All of them are about a product,
+// consumers keep the events of one product in order.
+type Event interface {
+	proto.Message
+	GetProductId() int64
+}
+
+func eventType(e Event) protoreflect.FullName {
+	return e.ProtoReflect().Descriptor().FullName()
+}
+
+// encodeEvent keys the message by product ID, so the events of a product stay on one
+// partition, and copies the request ID and trace context of ctx into the headers
+func encodeEvent(ctx context.Context, e Event) (kafka.Message, error) {
+	value, err := proto.Marshal(e)
+	if err != nil {
+		return kafka.Message{}, fmt.Errorf("%w: failed to encode %s: %w", domain.ErrUnpublishable, eventType(e), err)
+	}
+
+	headers := []kafka.Header{
+		{Key: HeaderEventType, Value: []byte(eventType(e))},
+		{Key: HeaderSchemaVersion, Value: []byte(SchemaVersion)},
+		{Key: HeaderContentType, Value: []byte(contentTypeProtobuf)},
+		{Key: HeaderProducedAt, Value: []byte(time.Now().UTC().Format(time.RFC3339Nano))},
+	}
+	if id := domain.RequestIDFromContext(ctx); id != "" {
+		headers = append(headers, kafka.Header{Key: HeaderRequestID, Value: []byte(id)})
+	}
+	if t, ok := domain.TraceFromContext(ctx); ok {
+		headers = append(headers, kafka.Header{Key: HeaderTraceparent, Value: []byte(t.Traceparent())})
+	}
+
+	return kafka.Message{
+		Key:     strconv.AppendInt(nil, e.GetProductId(), 10),
+		Value:   value,
+		Headers: headers,
+	}, nil
+}
+
+// eventContext restores what encodeEvent put into the headers. The consumer gets a span
+// of its own within the producer's trace.
+func eventContext(ctx context.Context, msg kafka.Message) context.Context {
+	if id := header(msg.Headers, HeaderRequestID); id != "" {
+		ctx = domain.WithRequestID(ctx, id)
+	}
+	if t, err := domain.ParseTraceparent(header(msg.Headers, HeaderTraceparent)); err == nil {
+		ctx = domain.WithTrace(ctx, t.Child())
+	}
+	return ctx
+}
+
+// decodeEvent reads the event named by the x-event-type header. An unsupported schema
+// version is an error, the message is dead-lettered until a consumer that knows it
+// runs. A message without the header predates the schema and is the JSON price check
+// {"product_id": 1, "action": "check_price"}.
+func decodeEvent(msg kafka.Message) (Event, error) {
+	name := header(msg.Headers, HeaderEventType)
+	if name == "" {
+		return decodeLegacy(msg.Value)
+	}
+	if v := header(msg.Headers, HeaderSchemaVersion); v != SchemaVersion {
+		return nil, fmt.Errorf("unsupported schema version %q of %s", v, name)
+	}
+
+	mt, err := protoregistry.GlobalTypes.FindMessageByName(protoreflect.FullName(name))
+	if err != nil {
+		return nil, fmt.Errorf("%w %s", errUnknownEvent, name)
+	}
+	e, ok := mt.New().Interface().(Event)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an event", name)
+	}
+	if err := proto.Unmarshal(msg.Value, e); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", name, err)
+	}
+	return e, nil
+}
+
+func decodeLegacy(value []byte) (Event, error) {
+	var data struct {
+		ProductID int64 `json:"product_id"`
+	}
+	if err := json.Unmarshal(value, &data); err != nil {
+		return nil, err
+	}
+	return &v1.PriceCheckRequested{ProductId: data.ProductID}, nil
+}
+
+// Dispatcher routes events to the handler registered for their type. Events without a
+// handler are skipped, so producers can add types before every consumer knows them.
+type Dispatcher struct {
+	handlers map[protoreflect.FullName]func(ctx context.Context, e Event) error
+}
+
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{handlers: make(map[protoreflect.FullName]func(ctx context.Context, e Event) error)}
+}
+
+// Handle registers fn for the events of type T, replacing an earlier handler
+func Handle[T Event](d *Dispatcher, fn func(ctx context.Context, e T) error) {
+	var zero T
+	d.handlers[eventType(zero)] = func(ctx context.Context, e Event) error {
+		return fn(ctx, e.(T))
+	}
+}
+
+// handler reports false for event types nobody registered
+func (d *Dispatcher) handler(e Event) (func(ctx context.Context, e Event) error, bool) {
+	fn, ok := d.handlers[eventType(e)]
+	return fn, ok
+}
@@ -0,0 +1,152 @@
+package broker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/derkres11/price-pulse/internal/domain"
+	v1 "github.com/derkres11/price-pulse/pkg/api/v1"
+	"github.com/segmentio/kafka-go"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestEventRoundTrip(t *testing.T) {
+	at := timestamppb.Now()
+	events := []Event{
+		&v1.PriceCheckRequested{ProductId: 1, RequestedAt: at},
+		&v1.PriceObserved{ProductId: 2, Price: 19.99, Currency: "EUR", InStock: true, Source: "jsonld", ObservedAt: at},
+		&v1.AlertTriggered{ProductId: 3, RuleId: 4, SubscriptionId: 5, UserId: 6, Type: "below", Price: 9.5, PreviousPrice: 12, Reason: "price dropped", TriggeredAt: at},
+		&v1.ProductDeleted{ProductId: 7, DeletedAt: at},
+	}
+
+	for _, want := range events {
+		t.Run(string(eventType(want)), func(t *testing.T) {
+			msg, err := encodeEvent(context.Background(), want)
+			if err != nil {
+				t.Fatalf("encodeEvent() error = %v", err)
+			}
+			if got := header(msg.Headers, HeaderEventType); got != string(eventType(want)) {
+				t.Errorf("%s = %q, want %q", HeaderEventType, got, eventType(want))
+			}
+			if got := header(msg.Headers, HeaderSchemaVersion); got != SchemaVersion {
+				t.Errorf("%s = %q, want %q", HeaderSchemaVersion, got, SchemaVersion)
+			}
+
+			got, err := decodeEvent(msg)
+			if err != nil {
+				t.Fatalf("decodeEvent() error = %v", err)
+			}
+			if !proto.Equal(got, want) {
+				t.Errorf("decodeEvent() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestDecodeEvent(t *testing.T) {
+	headers := func(typ, version string) []kafka.Header {
+		return []kafka.Header{{Key: HeaderEventType, Value: []byte(typ)}, {Key: HeaderSchemaVersion, Value: []byte(version)}}
+	}
+
+	tests := []struct {
+		name        string
+		msg         kafka.Message
+		wantProduct int64
+		wantErr     bool
+		wantUnknown bool
+	}{
+		{"Legacy JSON", kafka.Message{Value: []byte(`{"product_id":42,"action":"check_price"}`)}, 42, false, false},
+		{"Legacy garbage", kafka.Message{Value: []byte("{")}, 0, true, false},
+		{"Unknown type", kafka.Message{Headers: headers("v1.PriceRefunded", SchemaVersion)}, 0, true, true},
+		{"Not an event", kafka.Message{Headers: headers("v1.GetProductRequest", SchemaVersion)}, 0, true, false},
+		{"Newer schema version", kafka.Message{Headers: headers("v1.ProductDeleted", "2")}, 0, true, false},
+		{"Corrupt payload", kafka.Message{Headers: headers("v1.ProductDeleted", SchemaVersion), Value: []byte{0xff}}, 0, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeEvent(tt.msg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodeEvent() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if errors.Is(err, errUnknownEvent) != tt.wantUnknown {
+				t.Errorf("decodeEvent() error = %v, want unknown event %v", err, tt.wantUnknown)
+			}
+			if err == nil && got.GetProductId() != tt.wantProduct {
+				t.Errorf("product = %d, want %d", got.GetProductId(), tt.wantProduct)
+			}
+		})
+	}
+}
+
+func TestEventContextPropagation(t *testing.T) {
+	trace := domain.NewTraceContext()
+	ctx := domain.WithTrace(domain.WithRequestID(context.Background(), "req-1"), trace)
+
+	msg, err := encodeEvent(ctx, &v1.PriceCheckRequested{ProductId: 42})
+	if err != nil {
+		t.Fatalf("encodeEvent() error = %v", err)
+	}
+	if string(msg.Key) != "42" {
+		t.Errorf("key = %q, want the product ID", msg.Key)
+	}
+	if header(msg.Headers, HeaderProducedAt) == "" {
+		t.Errorf("%s header missing", HeaderProducedAt)
+	}
+
+	got := eventContext(context.Background(), msg)
+	if id := domain.RequestIDFromContext(got); id != "req-1" {
+		t.Errorf("request ID = %q, want %q", id, "req-1")
+	}
+	span, ok := domain.TraceFromContext(got)
+	if !ok || span.TraceID != trace.TraceID || span.SpanID == trace.SpanID {
+		t.Errorf("trace = %s, want a child span of %s", span.Traceparent(), trace.Traceparent())
+	}
+
+	// Without them on the producer side the headers are left out
+	msg, _ = encodeEvent(context.Background(), &v1.PriceCheckRequested{ProductId: 42})
+	if header(msg.Headers, HeaderRequestID) != "" || header(msg.Headers, HeaderTraceparent) != "" {
+		t.Errorf("headers = %v, want no request ID or traceparent", msg.Headers)
+	}
+	if _, ok := domain.TraceFromContext(eventContext(context.Background(), msg)); ok {
+		t.Error("eventContext() invented a trace")
+	}
+}
+
+func TestDecodeNotification(t *testing.T) {
+	n := &domain.Notification{
+		Event: &domain.AlertEvent{ID: 9, RuleID: 4, ProductID: 3, SubscriptionID: 5, UserID: 6, Type: domain.AlertThreshold,
+			Price: 9.5, PreviousPrice: 12, Reason: "price dropped", CreatedAt: time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)},
+		Title: "Lamp", URL: "https://shop.example/lamp", Currency: "EUR",
+	}
+	legacy, _ := json.Marshal(n)
+
+	tests := []struct {
+		name    string
+		msg     kafka.Message
+		want    *domain.Notification
+		wantErr bool
+	}{
+		{"AlertTriggered", mustEncode(t, alertTriggered(n)), n, false},
+		{"Legacy JSON", kafka.Message{Value: legacy}, n, false},
+		{"Legacy without event", kafka.Message{Value: []byte(`{"title":"Lamp"}`)}, nil, true},
+		{"Other event", mustEncode(t, &v1.PriceCheckRequested{ProductId: 3}), nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeNotification(tt.msg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodeNotification() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodeNotification() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,45 +2,212 @@ package broker
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
+	"sync"
+	"time"
 
+	"github.com/derkres11/price-pulse/internal/domain"
 	"github.com/segmentio/kafka-go"
 )
 
+// deadLetterSink is where messages go after the last attempt
+type deadLetterSink interface {
+	send(ctx context.Context, msg kafka.Message, attempts int, cause error) error
+}
+
+// messageReader is the part of kafka.Reader the consumer uses
+type messageReader interface {
+	FetchMessage(ctx context.Context) (kafka.Message, error)
+	CommitMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// PoolOptions sizes the worker pool of a consumer. Every worker has its own queue of
+// QueueSize messages.
+type PoolOptions struct {
+	Workers   int
+	QueueSize int
+}
+
+// commitTimeout bounds a commit, including the last ones made while shutting down
+const commitTimeout = 10 * time.Second
+
+// ProductConsumer processes messages on a pool of workers. All messages of a product go
+// to the same worker, so they are handled in order. An offset is committed only once
+// the message and everything before it in the partition was processed or
+// dead-lettered, so a crash in between delivers them again.
 type ProductConsumer struct {
-	reader *kafka.Reader
+	reader messageReader
+	dlq    deadLetterSink
+	retry  RetryPolicy
+	pool   PoolOptions
+	topic  string
 }
 
-func NewProductConsumer(brokers []string, topic string, groupID string) *ProductConsumer {
+func NewProductConsumer(cluster Cluster, topic string, groupID string, dlq *DeadLetterQueue, retry RetryPolicy, pool PoolOptions) *ProductConsumer {
 	return &ProductConsumer{
-		reader: kafka.NewReader(kafka.ReaderConfig{
-			Brokers: brokers,
-			Topic:   topic,
-			GroupID: groupID,
-		}),
+		reader: cluster.reader(topic, groupID),
+		dlq:    dlq,
+		retry:  retry,
+		pool:   pool,
+		topic:  topic,
 	}
 }
 
-func (c *ProductConsumer) Start(ctx context.Context, processFunc func(id int64) error) {
+// delivery is a fetched message with its event, decoded once before it is queued
+type delivery struct {
+	msg   kafka.Message
+	event Event
+	err   error // why the message could not be decoded
+}
+
+// Start hands the events to the handlers registered on events. It blocks until ctx
+// ends and the workers have finished what they were doing. Messages still queued then
+// are left uncommitted and delivered again.
+func (c *ProductConsumer) Start(ctx context.Context, events *Dispatcher) {
+	var (
+		offsets     = newOffsetTracker()
+		done        = make(chan kafka.Message)
+		queues      = make([]chan delivery, max(c.pool.Workers, 1))
+		workers     sync.WaitGroup
+		committer   sync.WaitGroup
+		inFlight    = consumerInFlight.WithLabelValues(c.topic)
+		queueDepth  = consumerQueueDepth.WithLabelValues(c.topic)
+		uncommitted = consumerUncommitted.WithLabelValues(c.topic)
+	)
+
+	committer.Add(1)
+	go func() {
+		defer committer.Done()
+		for msg := range done {
+			commit, ok := offsets.complete(msg)
+			uncommitted.Set(float64(offsets.uncommitted()))
+			if ok {
+				c.commit(ctx, commit)
+			}
+		}
+	}()
+
+	for i := range queues {
+		queues[i] = make(chan delivery, c.pool.QueueSize)
+		workers.Add(1)
+		go func(queue <-chan delivery) {
+			defer workers.Done()
+			for d := range queue {
+				queueDepth.Dec()
+				if ctx.Err() != nil {
+					continue // shutting down, drain without processing
+				}
+
+				inFlight.Inc()
+				ok := c.handle(ctx, d, events)
+				inFlight.Dec()
+				if ok {
+					done <- d.msg
+				}
+			}
+		}(queues[i])
+	}
+
+	defer func() {
+		for _, q := range queues {
+			close(q)
+		}
+		workers.Wait()
+		close(done)
+		committer.Wait()
+	}()
+
 	for {
-		msg, err := c.reader.ReadMessage(ctx)
+		msg, err := c.reader.FetchMessage(ctx)
 		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
 			log.Printf("error while receiving message: %s", err.Error())
 			continue
 		}
 
-		var data struct {
-			ProductID int64 `json:"product_id"`
+		offsets.add(msg)
+		uncommitted.Set(float64(offsets.uncommitted()))
+		d := delivery{msg: msg}
+		d.event, d.err = decodeEvent(msg)
+		queueDepth.Inc()
+		select {
+		case queues[workerFor(d, len(queues))] <- d:
+		case <-ctx.Done():
+			queueDepth.Dec()
+			return
 		}
+	}
+}
 
-		if err := json.Unmarshal(msg.Value, &data); err != nil {
-			log.Printf("error unmarshaling message: %s", err.Error())
-			continue
+// commit outlives ctx for a while, so work finished during shutdown is not redone
+func (c *ProductConsumer) commit(ctx context.Context, msg kafka.Message) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), commitTimeout)
+	defer cancel()
+
+	if err := c.reader.CommitMessages(ctx, msg); err != nil {
+		log.Printf("error committing offset %d: %s", msg.Offset, err.Error())
+	}
+}
+
+// workerFor picks the worker by product ID, a malformed message can go anywhere
+func workerFor(d delivery, workers int) int {
+	if d.err != nil {
+		return 0
+	}
+	return int(uint64(d.event.GetProductId()) % uint64(workers))
+}
+
+// handle retries the event's handler with backoff and dead-letters the message after
+// the last attempt. It returns false when ctx ended before the message was done with.
+func (c *ProductConsumer) handle(ctx context.Context, d delivery, events *Dispatcher) bool {
+	if errors.Is(d.err, errUnknownEvent) {
+		return true // added after this build, not for us
+	}
+	if d.err != nil {
+		return c.deadLetter(ctx, d.msg, 1, fmt.Errorf("malformed message: %w", d.err))
+	}
+	fn, ok := events.handler(d.event)
+	if !ok {
+		return true // an event type this consumer does not care about
+	}
+
+	id := d.event.GetProductId()
+	eventCtx := eventContext(ctx, d.msg)
+	for attempt := 1; ; attempt++ {
+		err := fn(eventCtx, d.event)
+		if err == nil {
+			return true
+		}
+		// Retrying cannot fix a deleted product or a bad request
+		if attempt >= c.retry.MaxAttempts || errors.Is(err, domain.ErrNotFound) || errors.Is(err, domain.ErrInvalidArgument) {
+			return c.deadLetter(ctx, d.msg, attempt, err)
 		}
 
-		if err := processFunc(data.ProductID); err != nil {
-			log.Printf("error processing product %d: %s", data.ProductID, err.Error())
+		delay := c.retry.delay(attempt)
+		log.Printf("error processing product %d (attempt %d), retrying in %s: %s", id, attempt, delay, err.Error())
+		if !sleep(ctx, delay) {
+			return false
+		}
+	}
+}
+
+// deadLetter keeps trying until the dead-letter topic takes the message, the offset
+// must not be committed past a message that is nowhere
+func (c *ProductConsumer) deadLetter(ctx context.Context, msg kafka.Message, attempts int, cause error) bool {
+	log.Printf("dead-lettering message at offset %d after %d attempt(s): %s", msg.Offset, attempts, cause.Error())
+	for i := 1; ; i++ {
+		err := c.dlq.send(ctx, msg, attempts, cause)
+		if err == nil {
+			return true
+		}
+		log.Printf("error dead-lettering message at offset %d: %s", msg.Offset, err.Error())
+		if !sleep(ctx, c.retry.delay(i)) {
+			return false
 		}
 	}
 }
@@ -48,3 +215,15 @@ func (c *ProductConsumer) Start(ctx context.Context, processFunc func(id int64)
 func (c *ProductConsumer) Close() error {
 	return c.reader.Close()
 }
+
+// sleep waits for d and reports false if ctx ended first
+func sleep(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
@@ -0,0 +1,219 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/derkres11/price-pulse/internal/domain"
+	v1 "github.com/derkres11/price-pulse/pkg/api/v1"
+	"github.com/segmentio/kafka-go"
+)
+
+type sinkMock struct {
+	fail     int // sends to fail before one succeeds
+	attempts []int
+	causes   []error
+}
+
+func (s *sinkMock) send(ctx context.Context, msg kafka.Message, attempts int, cause error) error {
+	if s.fail > 0 {
+		s.fail--
+		return errors.New("kafka unavailable")
+	}
+	s.attempts = append(s.attempts, attempts)
+	s.causes = append(s.causes, cause)
+	return nil
+}
+
+// checks runs process for every price check
+func checks(process func(id int64) error) *Dispatcher {
+	d := NewDispatcher()
+	Handle(d, func(ctx context.Context, e *v1.PriceCheckRequested) error {
+		return process(e.GetProductId())
+	})
+	return d
+}
+
+func mustEncode(t *testing.T, e Event) kafka.Message {
+	t.Helper()
+	msg, err := encodeEvent(context.Background(), e)
+	if err != nil {
+		t.Fatalf("encodeEvent() error = %v", err)
+	}
+	return msg
+}
+
+func deliver(msg kafka.Message) delivery {
+	d := delivery{msg: msg}
+	d.event, d.err = decodeEvent(msg)
+	return d
+}
+
+func TestProductConsumer_Handle(t *testing.T) {
+	valid := mustEncode(t, &v1.PriceCheckRequested{ProductId: 7})
+	legacy := kafka.Message{Value: []byte(`{"product_id":7,"action":"check_price"}`)}
+	unknown := kafka.Message{Headers: []kafka.Header{{Key: HeaderEventType, Value: []byte("v1.PriceRefunded")}, {Key: HeaderSchemaVersion, Value: []byte(SchemaVersion)}}}
+	future := mustEncode(t, &v1.PriceCheckRequested{ProductId: 7})
+	future.Headers[1].Value = []byte("2")
+	failing := func(n int, err error) func(int64) error {
+		return func(int64) error {
+			if n > 0 {
+				n--
+				return err
+			}
+			return nil
+		}
+	}
+	transient := errors.New("shop timed out")
+
+	tests := []struct {
+		name         string
+		msg          kafka.Message
+		process      func(int64) error
+		sinkFailures int
+		wantDLQ      int // attempts recorded on the dead letter, 0 for none
+	}{
+		{"Processed", valid, failing(0, nil), 0, 0},
+		{"Legacy JSON", legacy, failing(0, nil), 0, 0},
+		{"Event without handler is skipped", mustEncode(t, &v1.ProductDeleted{ProductId: 7}), failing(10, transient), 0, 0},
+		{"Unknown event type is skipped", unknown, failing(10, transient), 0, 0},
+		{"Unsupported schema version", future, failing(0, nil), 0, 1},
+		{"Recovers on retry", valid, failing(2, transient), 0, 0},
+		{"Retries exhausted", valid, failing(10, transient), 0, 3},
+		{"Malformed", kafka.Message{Value: []byte("{")}, failing(0, nil), 0, 1},
+		{"Not found is not retried", valid, failing(10, fmt.Errorf("loading: %w", domain.ErrNotFound)), 0, 1},
+		{"Dead-letter topic down for a while", valid, failing(10, transient), 2, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := &sinkMock{fail: tt.sinkFailures}
+			c := &ProductConsumer{dlq: sink, retry: RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond}}
+
+			if !c.handle(context.Background(), deliver(tt.msg), checks(tt.process)) {
+				t.Fatal("handle() = false, want the message done with")
+			}
+			switch {
+			case tt.wantDLQ == 0 && len(sink.attempts) > 0:
+				t.Errorf("dead-lettered %v, want none", sink.causes)
+			case tt.wantDLQ > 0 && (len(sink.attempts) != 1 || sink.attempts[0] != tt.wantDLQ):
+				t.Errorf("dead letters %v, want one after %d attempt(s)", sink.attempts, tt.wantDLQ)
+			}
+		})
+	}
+}
+
+func TestProductConsumer_HandleStopsOnShutdown(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &ProductConsumer{dlq: &sinkMock{}, retry: RetryPolicy{MaxAttempts: 5, Backoff: time.Hour, MaxBackoff: time.Hour}}
+
+	done := c.handle(ctx, deliver(mustEncode(t, &v1.PriceCheckRequested{ProductId: 7})), checks(func(int64) error {
+		cancel()
+		return errors.New("shop timed out")
+	}))
+	if done {
+		t.Error("handle() = true, want the message left uncommitted for redelivery")
+	}
+}
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	p := RetryPolicy{Backoff: time.Second, MaxBackoff: 5 * time.Second}
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, w := range want {
+		if got := p.delay(i + 1); got != w {
+			t.Errorf("delay(%d) = %s, want %s", i+1, got, w)
+		}
+	}
+}
+
+type readerMock struct {
+	mu        sync.Mutex
+	msgs      []kafka.Message
+	committed []kafka.Message
+}
+
+func (r *readerMock) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	r.mu.Lock()
+	if len(r.msgs) > 0 {
+		msg := r.msgs[0]
+		r.msgs = r.msgs[1:]
+		r.mu.Unlock()
+		return msg, nil
+	}
+	r.mu.Unlock()
+	<-ctx.Done()
+	return kafka.Message{}, ctx.Err()
+}
+
+func (r *readerMock) CommitMessages(ctx context.Context, msgs ...kafka.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.committed = append(r.committed, msgs...)
+	return nil
+}
+
+func (r *readerMock) Close() error { return nil }
+
+func TestProductConsumer_Start(t *testing.T) {
+	// Product 1 is slow, product 2 is not held up by it
+	ids := []int64{1, 2, 1, 2, 1, 2}
+	reader := &readerMock{}
+	for i, id := range ids {
+		msg := mustEncode(t, &v1.PriceCheckRequested{ProductId: id})
+		msg.Offset = int64(i)
+		reader.msgs = append(reader.msgs, msg)
+	}
+	c := &ProductConsumer{reader: reader, dlq: &sinkMock{}, pool: PoolOptions{Workers: 2, QueueSize: 4}, topic: "test"}
+
+	var (
+		mu        sync.Mutex
+		processed = map[int64][]int{} // product -> order of processing
+		seq       int
+		finished  = make(chan struct{})
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		defer close(finished)
+		c.Start(ctx, checks(func(id int64) error {
+			if id == 1 {
+				time.Sleep(20 * time.Millisecond)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			seq++
+			processed[id] = append(processed[id], seq)
+			if seq == len(ids) {
+				cancel()
+			}
+			return nil
+		}))
+	}()
+
+	select {
+	case <-finished:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Start() did not return after cancel")
+	}
+
+	if len(processed[1]) != 3 || len(processed[2]) != 3 {
+		t.Fatalf("processed %v, want three updates per product", processed)
+	}
+	if processed[2][0] > processed[1][0] {
+		t.Errorf("product 2 was first processed at %d, after product 1 at %d; want it not held up", processed[2][0], processed[1][0])
+	}
+
+	var last int64 = -1
+	for _, m := range reader.committed {
+		if m.Offset <= last {
+			t.Errorf("committed offset %d after %d, want increasing", m.Offset, last)
+		}
+		last = m.Offset
+	}
+	if last != int64(len(ids)-1) {
+		t.Errorf("last committed offset = %d, want %d", last, len(ids)-1)
+	}
+}
@@ -2,39 +2,52 @@ package broker
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"time"
 
+	"github.com/derkres11/price-pulse/internal/domain"
+	v1 "github.com/derkres11/price-pulse/pkg/api/v1"
 	"github.com/segmentio/kafka-go"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type ProductProducer struct {
 	writer *kafka.Writer
 }
 
-func NewProductProducer(brokers []string, topic string) *ProductProducer {
+func NewProductProducer(cluster Cluster, topic string) *ProductProducer {
 	return &ProductProducer{
-		writer: &kafka.Writer{
-			Addr:     kafka.TCP(brokers...),
-			Topic:    topic,
-			Balancer: &kafka.LeastBytes{},
-		},
+		writer: cluster.writer(topic),
 	}
 }
 
 func (p *ProductProducer) SendProductUpdate(ctx context.Context, productID int64) error {
-	message := map[string]interface{}{
-		"product_id": productID,
-		"action":     "check_price",
-	}
+	return p.send(ctx, &v1.PriceCheckRequested{ProductId: productID, RequestedAt: timestamppb.Now()})
+}
 
-	payload, _ := json.Marshal(message)
+func (p *ProductProducer) SendProductDeleted(ctx context.Context, productID int64, at time.Time) error {
+	return p.send(ctx, &v1.ProductDeleted{ProductId: productID, DeletedAt: timestamppb.New(at)})
+}
 
-	err := p.writer.WriteMessages(ctx, kafka.Message{
-		Value: payload,
+// SendPriceObserved goes on the product topic as well, the watcher skips it
+func (p *ProductProducer) SendPriceObserved(ctx context.Context, productID int64, info *domain.PriceInfo, at time.Time) error {
+	return p.send(ctx, &v1.PriceObserved{
+		ProductId:  productID,
+		Price:      info.Price,
+		Currency:   info.Currency,
+		InStock:    info.InStock,
+		Source:     info.Source,
+		ObservedAt: timestamppb.New(at),
 	})
+}
 
+func (p *ProductProducer) send(ctx context.Context, e Event) error {
+	msg, err := encodeEvent(ctx, e)
 	if err != nil {
+		return err
+	}
+
+	if err := p.writer.WriteMessages(ctx, msg); err != nil {
 		return fmt.Errorf("failed to write message to kafka: %w", err)
 	}
 
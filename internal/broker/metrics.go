@@ -0,0 +1,23 @@
+package broker
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	consumerInFlight = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "pricepulse_consumer_in_flight",
+		Help: "Messages a consumer worker is processing right now.",
+	}, []string{"topic"})
+
+	consumerQueueDepth = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "pricepulse_consumer_queue_depth",
+		Help: "Fetched messages waiting for a consumer worker.",
+	}, []string{"topic"})
+
+	consumerUncommitted = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "pricepulse_consumer_uncommitted",
+		Help: "Fetched messages whose offset cannot be committed yet.",
+	}, []string{"topic"})
+)
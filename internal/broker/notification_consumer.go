@@ -0,0 +1,82 @@
+package broker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+
+	"github.com/derkres11/price-pulse/internal/domain"
+	v1 "github.com/derkres11/price-pulse/pkg/api/v1"
+	"github.com/segmentio/kafka-go"
+)
+
+type NotificationConsumer struct {
+	reader *kafka.Reader
+}
+
+func NewNotificationConsumer(cluster Cluster, topic string, groupID string) *NotificationConsumer {
+	return &NotificationConsumer{
+		reader: cluster.reader(topic, groupID),
+	}
+}
+
+// Start hands every AlertTriggered event to processFunc, with the request ID and trace
+// of the check that triggered it in ctx
+func (c *NotificationConsumer) Start(ctx context.Context, processFunc func(ctx context.Context, n *domain.Notification) error) {
+	for {
+		msg, err := c.reader.ReadMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Printf("error while receiving notification: %s", err.Error())
+			continue
+		}
+
+		n, err := decodeNotification(msg)
+		if errors.Is(err, errUnknownEvent) {
+			continue // added after this build, not for us
+		}
+		if err != nil {
+			log.Printf("error decoding notification at offset %d: %s", msg.Offset, err.Error())
+			continue
+		}
+		if n == nil {
+			continue // another event type on the topic
+		}
+
+		if err := processFunc(eventContext(ctx, msg), n); err != nil {
+			log.Printf("error dispatching notification for product %d: %s", n.Event.ProductID, err.Error())
+		}
+	}
+}
+
+// decodeNotification returns nil for events other than AlertTriggered. A message without
+// an event type predates the schema and is the notification as JSON.
+func decodeNotification(msg kafka.Message) (*domain.Notification, error) {
+	if header(msg.Headers, HeaderEventType) == "" {
+		var n domain.Notification
+		if err := json.Unmarshal(msg.Value, &n); err != nil {
+			return nil, err
+		}
+		if n.Event == nil {
+			return nil, errors.New("notification without an alert event")
+		}
+		return &n, nil
+	}
+
+	e, err := decodeEvent(msg)
+	if err != nil {
+		return nil, err
+	}
+	alert, ok := e.(*v1.AlertTriggered)
+	if !ok {
+		return nil, nil
+	}
+	return notificationFrom(alert), nil
+}
+
+func (c *NotificationConsumer) Close() error {
+	return c.reader.Close()
+}
@@ -0,0 +1,82 @@
+package broker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/derkres11/price-pulse/internal/domain"
+	v1 "github.com/derkres11/price-pulse/pkg/api/v1"
+	"github.com/segmentio/kafka-go"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// NotificationProducer queues alert notifications on their own topic,
+// delivery happens in NotificationConsumer so slow channels never hold up the watcher.
+type NotificationProducer struct {
+	writer *kafka.Writer
+}
+
+func NewNotificationProducer(cluster Cluster, topic string) *NotificationProducer {
+	return &NotificationProducer{
+		writer: cluster.writer(topic),
+	}
+}
+
+func (p *NotificationProducer) SendNotification(ctx context.Context, n *domain.Notification) error {
+	if n.Event == nil {
+		return fmt.Errorf("%w: notification without an alert event", domain.ErrInvalidArgument)
+	}
+	msg, err := encodeEvent(ctx, alertTriggered(n))
+	if err != nil {
+		return err
+	}
+
+	if err := p.writer.WriteMessages(ctx, msg); err != nil {
+		return fmt.Errorf("failed to write notification to kafka: %w", err)
+	}
+	return nil
+}
+
+func (p *NotificationProducer) Close() error {
+	return p.writer.Close()
+}
+
+func alertTriggered(n *domain.Notification) *v1.AlertTriggered {
+	e := n.Event
+	return &v1.AlertTriggered{
+		ProductId:      e.ProductID,
+		RuleId:         e.RuleID,
+		SubscriptionId: e.SubscriptionID,
+		UserId:         e.UserID,
+		Type:           string(e.Type),
+		Price:          e.Price,
+		PreviousPrice:  e.PreviousPrice,
+		Reason:         e.Reason,
+		TriggeredAt:    timestamppb.New(e.CreatedAt),
+		EventId:        e.ID,
+		Title:          n.Title,
+		Url:            n.URL,
+		Currency:       n.Currency,
+	}
+}
+
+// notificationFrom is the reverse of alertTriggered
+func notificationFrom(e *v1.AlertTriggered) *domain.Notification {
+	return &domain.Notification{
+		Event: &domain.AlertEvent{
+			ID:             e.GetEventId(),
+			RuleID:         e.GetRuleId(),
+			ProductID:      e.GetProductId(),
+			SubscriptionID: e.GetSubscriptionId(),
+			UserID:         e.GetUserId(),
+			Type:           domain.AlertRuleType(e.GetType()),
+			Price:          e.GetPrice(),
+			PreviousPrice:  e.GetPreviousPrice(),
+			Reason:         e.GetReason(),
+			CreatedAt:      e.GetTriggeredAt().AsTime(),
+		},
+		Title:    e.GetTitle(),
+		URL:      e.GetUrl(),
+		Currency: e.GetCurrency(),
+	}
+}
@@ -0,0 +1,75 @@
+package broker
+
+import (
+	"sync"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// offsetTracker finds what may be committed when messages finish out of order. A
+// partition's offset is only committed once every message fetched before it is done.
+type offsetTracker struct {
+	mu         sync.Mutex
+	partitions map[int]*partitionOffsets
+}
+
+type partitionOffsets struct {
+	pending []int64 // fetched and not yet committable, in fetch order
+	done    map[int64]bool
+}
+
+func newOffsetTracker() *offsetTracker {
+	return &offsetTracker{partitions: make(map[int]*partitionOffsets)}
+}
+
+// add registers a fetched message, it must be called in fetch order
+func (t *offsetTracker) add(msg kafka.Message) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	p, ok := t.partitions[msg.Partition]
+	if !ok {
+		p = &partitionOffsets{done: make(map[int64]bool)}
+		t.partitions[msg.Partition] = p
+	}
+	p.pending = append(p.pending, msg.Offset)
+}
+
+// complete marks msg done. It reports the message to commit, the last one of the
+// partition with nothing unfinished before it, or false when msg completed nothing.
+func (t *offsetTracker) complete(msg kafka.Message) (kafka.Message, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	p, ok := t.partitions[msg.Partition]
+	if !ok {
+		return kafka.Message{}, false
+	}
+	p.done[msg.Offset] = true
+
+	n := 0
+	for n < len(p.pending) && p.done[p.pending[n]] {
+		delete(p.done, p.pending[n])
+		n++
+	}
+	if n == 0 {
+		return kafka.Message{}, false
+	}
+
+	commit := msg
+	commit.Offset = p.pending[n-1]
+	p.pending = p.pending[n:]
+	return commit, true
+}
+
+// uncommitted is the number of fetched messages not committable yet
+func (t *offsetTracker) uncommitted() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	n := 0
+	for _, p := range t.partitions {
+		n += len(p.pending)
+	}
+	return n
+}
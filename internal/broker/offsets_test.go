@@ -0,0 +1,69 @@
+package broker
+
+import (
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+)
+
+func TestOffsetTracker(t *testing.T) {
+	msg := func(partition int, offset int64) kafka.Message {
+		return kafka.Message{Partition: partition, Offset: offset}
+	}
+
+	tests := []struct {
+		name    string
+		fetched []kafka.Message
+		done    []kafka.Message
+		want    []int64 // offset committed after each completion, -1 for none
+	}{
+		{
+			name:    "In order",
+			fetched: []kafka.Message{msg(0, 1), msg(0, 2)},
+			done:    []kafka.Message{msg(0, 1), msg(0, 2)},
+			want:    []int64{1, 2},
+		},
+		{
+			name:    "Later message waits for earlier",
+			fetched: []kafka.Message{msg(0, 1), msg(0, 2), msg(0, 3)},
+			done:    []kafka.Message{msg(0, 3), msg(0, 2), msg(0, 1)},
+			want:    []int64{-1, -1, 3},
+		},
+		{
+			name:    "Gap in the middle",
+			fetched: []kafka.Message{msg(0, 1), msg(0, 2), msg(0, 3)},
+			done:    []kafka.Message{msg(0, 1), msg(0, 3), msg(0, 2)},
+			want:    []int64{1, -1, 3},
+		},
+		{
+			name:    "Partitions are independent",
+			fetched: []kafka.Message{msg(0, 1), msg(1, 1), msg(0, 2)},
+			done:    []kafka.Message{msg(1, 1), msg(0, 2), msg(0, 1)},
+			want:    []int64{1, -1, 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := newOffsetTracker()
+			for _, m := range tt.fetched {
+				tracker.add(m)
+			}
+			for i, m := range tt.done {
+				got := int64(-1)
+				if commit, ok := tracker.complete(m); ok {
+					got = commit.Offset
+					if commit.Partition != m.Partition {
+						t.Errorf("commit partition = %d, want %d", commit.Partition, m.Partition)
+					}
+				}
+				if got != tt.want[i] {
+					t.Errorf("complete(%d/%d) committed %d, want %d", m.Partition, m.Offset, got, tt.want[i])
+				}
+			}
+			if n := tracker.uncommitted(); n != 0 {
+				t.Errorf("uncommitted() = %d, want 0", n)
+			}
+		})
+	}
+}
@@ -0,0 +1,394 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// Config is everything the service reads at startup. Values come from Default, then the
+// YAML file, then the environment, then flags; every field names its env variable.
+// Flags are the YAML path, e.g. -postgres.max_conns=20.
+type Config struct {
+	HTTP      HTTPConfig      `yaml:"http"`
+	GRPC      GRPCConfig      `yaml:"grpc"`
+	Admin     AdminConfig     `yaml:"admin"`
+	Bootstrap BootstrapConfig `yaml:"bootstrap"`
+	Postgres  PostgresConfig  `yaml:"postgres"`
+	Redis     RedisConfig     `yaml:"redis"`
+	Kafka     KafkaConfig     `yaml:"kafka"`
+	Outbox    OutboxConfig    `yaml:"outbox"`
+	Scheduler SchedulerConfig `yaml:"scheduler"`
+	Leader    LeaderConfig    `yaml:"leader"`
+	Fetcher   FetcherConfig   `yaml:"fetcher"`
+	Notify    NotifyConfig    `yaml:"notify"`
+	JWT       JWTConfig       `yaml:"jwt"`
+}
+
+type HTTPConfig struct {
+	Addr              string        `yaml:"addr" env:"HTTP_ADDR"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" env:"HTTP_READ_HEADER_TIMEOUT"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout" env:"HTTP_IDLE_TIMEOUT"`
+	ShutdownTimeout   time.Duration `yaml:"shutdown_timeout" env:"HTTP_SHUTDOWN_TIMEOUT"`
+	// AllowedOrigins may open WebSocket streams besides the API's own origin, "*" allows any
+	AllowedOrigins []string `yaml:"allowed_origins" env:"HTTP_ALLOWED_ORIGINS"`
+}
+
+type GRPCConfig struct {
+	Addr string `yaml:"addr" env:"GRPC_ADDR"`
+}
+
+// AdminConfig is the listener for metrics and docs, basic auth is on when User is set
+type AdminConfig struct {
+	Addr     string `yaml:"addr" env:"ADMIN_ADDR"`
+	User     string `yaml:"user" env:"ADMIN_USER"`
+	Password string `yaml:"password" env:"ADMIN_PASSWORD" secret:"true"`
+}
+
+// BootstrapConfig gives AdminEmail the admin key AdminAPIKey at startup, only its hash
+// is stored. The key has the format of issued ones, pp_<12 hex digits>_<secret>.
+type BootstrapConfig struct {
+	AdminEmail  string `yaml:"admin_email" env:"ADMIN_EMAIL"`
+	AdminAPIKey string `yaml:"admin_api_key" env:"ADMIN_API_KEY" secret:"true"`
+}
+
+type PostgresConfig struct {
+	Host     string `yaml:"host" env:"POSTGRES_HOST"`
+	Port     int    `yaml:"port" env:"POSTGRES_PORT"`
+	User     string `yaml:"user" env:"POSTGRES_USER"`
+	Password string `yaml:"password" env:"POSTGRES_PASSWORD" secret:"true"`
+	Database string `yaml:"database" env:"POSTGRES_DB"`
+	// SSLMode is passed to libpq as is: disable, allow, prefer, require, verify-ca or verify-full
+	SSLMode         string        `yaml:"sslmode" env:"POSTGRES_SSLMODE"`
+	SSLRootCert     string        `yaml:"sslrootcert" env:"POSTGRES_SSLROOTCERT"`
+	MaxConns        int           `yaml:"max_conns" env:"POSTGRES_MAX_CONNS"`
+	MinConns        int           `yaml:"min_conns" env:"POSTGRES_MIN_CONNS"`
+	MaxConnLifetime time.Duration `yaml:"max_conn_lifetime" env:"POSTGRES_MAX_CONN_LIFETIME"`
+	MaxConnIdleTime time.Duration `yaml:"max_conn_idle_time" env:"POSTGRES_MAX_CONN_IDLE_TIME"`
+	ConnectTimeout  time.Duration `yaml:"connect_timeout" env:"POSTGRES_CONNECT_TIMEOUT"`
+	ConnectAttempts int           `yaml:"connect_attempts" env:"POSTGRES_CONNECT_ATTEMPTS"`
+}
+
+type RedisConfig struct {
+	Addr        string        `yaml:"addr" env:"REDIS_ADDR"`
+	Username    string        `yaml:"username" env:"REDIS_USERNAME"`
+	Password    string        `yaml:"password" env:"REDIS_PASSWORD" secret:"true"`
+	DB          int           `yaml:"db" env:"REDIS_DB"`
+	PoolSize    int           `yaml:"pool_size" env:"REDIS_POOL_SIZE"`
+	DialTimeout time.Duration `yaml:"dial_timeout" env:"REDIS_DIAL_TIMEOUT"`
+	TLS         TLSConfig     `yaml:"tls" env:"REDIS_TLS"`
+}
+
+type KafkaConfig struct {
+	Brokers           []string      `yaml:"brokers" env:"KAFKA_BROKERS"`
+	ProductTopic      string        `yaml:"product_topic" env:"KAFKA_PRODUCT_TOPIC"`
+	NotificationTopic string        `yaml:"notification_topic" env:"KAFKA_NOTIFICATION_TOPIC"`
+	WatcherGroup      string        `yaml:"watcher_group" env:"KAFKA_WATCHER_GROUP"`
+	NotifierGroup     string        `yaml:"notifier_group" env:"KAFKA_NOTIFIER_GROUP"`
+	DialTimeout       time.Duration `yaml:"dial_timeout" env:"KAFKA_DIAL_TIMEOUT"`
+	WriteBatchTimeout time.Duration `yaml:"write_batch_timeout" env:"KAFKA_WRITE_BATCH_TIMEOUT"`
+	TLS               TLSConfig     `yaml:"tls" env:"KAFKA_TLS"`
+	// ProductDLQTopic takes the product updates that still fail after RetryMaxAttempts
+	ProductDLQTopic  string        `yaml:"product_dlq_topic" env:"KAFKA_PRODUCT_DLQ_TOPIC"`
+	RetryMaxAttempts int           `yaml:"retry_max_attempts" env:"KAFKA_RETRY_MAX_ATTEMPTS"`
+	RetryBackoff     time.Duration `yaml:"retry_backoff" env:"KAFKA_RETRY_BACKOFF"`
+	RetryMaxBackoff  time.Duration `yaml:"retry_max_backoff" env:"KAFKA_RETRY_MAX_BACKOFF"`
+	// WatcherWorkers process product updates concurrently, updates of one product in order
+	WatcherWorkers   int `yaml:"watcher_workers" env:"KAFKA_WATCHER_WORKERS"`
+	WatcherQueueSize int `yaml:"watcher_queue_size" env:"KAFKA_WATCHER_QUEUE_SIZE"`
+}
+
+// OutboxConfig drives the relay that publishes the outbox table to Kafka
+type OutboxConfig struct {
+	PollInterval time.Duration `yaml:"poll_interval" env:"OUTBOX_POLL_INTERVAL"`
+	BatchSize    int           `yaml:"batch_size" env:"OUTBOX_BATCH_SIZE"`
+	MaxBackoff   time.Duration `yaml:"max_backoff" env:"OUTBOX_MAX_BACKOFF"`
+	MaxAttempts  int           `yaml:"max_attempts" env:"OUTBOX_MAX_ATTEMPTS"`
+	ClaimTimeout time.Duration `yaml:"claim_timeout" env:"OUTBOX_CLAIM_TIMEOUT"`
+	Retention    time.Duration `yaml:"retention" env:"OUTBOX_RETENTION"`
+}
+
+// SchedulerConfig drives the loop that queues the checks of due products. A product's
+// own interval wins over its host's, which wins over DefaultInterval.
+type SchedulerConfig struct {
+	PollInterval    time.Duration `yaml:"poll_interval" env:"SCHEDULER_POLL_INTERVAL"`
+	BatchSize       int           `yaml:"batch_size" env:"SCHEDULER_BATCH_SIZE"`
+	DefaultInterval time.Duration `yaml:"default_interval" env:"SCHEDULER_DEFAULT_INTERVAL"`
+	JitterPercent   int           `yaml:"jitter_percent" env:"SCHEDULER_JITTER_PERCENT"`
+	// Adaptive scales the host's or default interval by the product's price volatility,
+	// distance to its target and subscribers, within MinInterval and MaxInterval
+	Adaptive         bool          `yaml:"adaptive" env:"SCHEDULER_ADAPTIVE"`
+	MinInterval      time.Duration `yaml:"min_interval" env:"SCHEDULER_MIN_INTERVAL"`
+	MaxInterval      time.Duration `yaml:"max_interval" env:"SCHEDULER_MAX_INTERVAL"`
+	VolatilityWindow time.Duration `yaml:"volatility_window" env:"SCHEDULER_VOLATILITY_WINDOW"`
+}
+
+// LeaderConfig picks the one replica that runs the periodic jobs. Replicas with the
+// same Name compete; InstanceID defaults to the hostname.
+type LeaderConfig struct {
+	Backend       string        `yaml:"backend" env:"LEADER_BACKEND"` // postgres or redis
+	Name          string        `yaml:"name" env:"LEADER_NAME"`
+	InstanceID    string        `yaml:"instance_id" env:"LEADER_INSTANCE_ID"`
+	RenewInterval time.Duration `yaml:"renew_interval" env:"LEADER_RENEW_INTERVAL"`
+	LeaseTTL      time.Duration `yaml:"lease_ttl" env:"LEADER_LEASE_TTL"` // redis only
+}
+
+type FetcherConfig struct {
+	Timeout   time.Duration `yaml:"timeout" env:"FETCHER_TIMEOUT"`
+	UserAgent string        `yaml:"user_agent" env:"FETCHER_USER_AGENT"`
+	RulesPath string        `yaml:"rules_path" env:"FETCHER_RULES_PATH"`
+	// A host gets a request every HostInterval on average, HostBurst back to back after
+	// a quiet spell and at most HostConcurrency at once, counted over all replicas.
+	// A check that would wait longer than HostMaxWait for its turn is skipped.
+	HostInterval    time.Duration `yaml:"host_interval" env:"FETCHER_HOST_INTERVAL"`
+	HostBurst       int           `yaml:"host_burst" env:"FETCHER_HOST_BURST"`
+	HostConcurrency int           `yaml:"host_concurrency" env:"FETCHER_HOST_CONCURRENCY"`
+	HostMaxWait     time.Duration `yaml:"host_max_wait" env:"FETCHER_HOST_MAX_WAIT"`
+	// A 429 or 503 holds back the host for its Retry-After, else Backoff, at most MaxBackoff
+	Backoff      time.Duration `yaml:"backoff" env:"FETCHER_BACKOFF"`
+	MaxBackoff   time.Duration `yaml:"max_backoff" env:"FETCHER_MAX_BACKOFF"`
+	RobotsTTL    time.Duration `yaml:"robots_ttl" env:"FETCHER_ROBOTS_TTL"`
+	RobotsAgent  string        `yaml:"robots_agent" env:"FETCHER_ROBOTS_AGENT"`
+	IgnoreRobots bool          `yaml:"ignore_robots" env:"FETCHER_IGNORE_ROBOTS"`
+	// CircuitFailures failures of a host in a row open its circuit, for CircuitOpenTimeout
+	// its checks fail fast, then CircuitTrials trial requests decide whether it closes
+	CircuitFailures    int           `yaml:"circuit_failures" env:"FETCHER_CIRCUIT_FAILURES"`
+	CircuitOpenTimeout time.Duration `yaml:"circuit_open_timeout" env:"FETCHER_CIRCUIT_OPEN_TIMEOUT"`
+	CircuitTrials      int           `yaml:"circuit_trials" env:"FETCHER_CIRCUIT_TRIALS"`
+}
+
+type NotifyConfig struct {
+	// Timeout bounds one delivery, DispatchTimeout a whole notification over all channels
+	Timeout          time.Duration `yaml:"timeout" env:"NOTIFY_TIMEOUT"`
+	DispatchTimeout  time.Duration `yaml:"dispatch_timeout" env:"NOTIFY_DISPATCH_TIMEOUT"`
+	TelegramBaseURL  string        `yaml:"telegram_base_url" env:"TELEGRAM_BASE_URL"`
+	TelegramBotToken string        `yaml:"telegram_bot_token" env:"TELEGRAM_BOT_TOKEN" secret:"true"`
+	SlackBaseURL     string        `yaml:"slack_base_url" env:"SLACK_BASE_URL"`
+	SlackBotToken    string        `yaml:"slack_bot_token" env:"SLACK_BOT_TOKEN" secret:"true"`
+	SMTPAddr         string        `yaml:"smtp_addr" env:"SMTP_ADDR"`
+	SMTPFrom         string        `yaml:"smtp_from" env:"SMTP_FROM"`
+	SMTPUsername     string        `yaml:"smtp_username" env:"SMTP_USERNAME"`
+	SMTPPassword     string        `yaml:"smtp_password" env:"SMTP_PASSWORD" secret:"true"`
+
+	// The delivery policy of product level alerts, subscriptions and users carry their own
+	OperatorCooldown   time.Duration `yaml:"operator_cooldown" env:"NOTIFY_OPERATOR_COOLDOWN"`
+	OperatorRearm      bool          `yaml:"operator_rearm" env:"NOTIFY_OPERATOR_REARM"`
+	OperatorQuietStart string        `yaml:"operator_quiet_start" env:"NOTIFY_OPERATOR_QUIET_START"`
+	OperatorQuietEnd   string        `yaml:"operator_quiet_end" env:"NOTIFY_OPERATOR_QUIET_END"`
+	OperatorTimezone   string        `yaml:"operator_timezone" env:"NOTIFY_OPERATOR_TIMEZONE"`
+}
+
+// JWTConfig enables bearer tokens when HS256Secret or RS256PublicKeyFile is set
+type JWTConfig struct {
+	HS256Secret        string        `yaml:"hs256_secret" env:"JWT_HS256_SECRET" secret:"true"`
+	RS256PublicKeyFile string        `yaml:"rs256_public_key_file" env:"JWT_RS256_PUBLIC_KEY_FILE"`
+	Issuer             string        `yaml:"issuer" env:"JWT_ISSUER"`
+	Audience           string        `yaml:"audience" env:"JWT_AUDIENCE"`
+	Leeway             time.Duration `yaml:"leeway" env:"JWT_LEEWAY"`
+}
+
+// Default is the configuration for running next to the docker-compose infrastructure
+func Default() *Config {
+	return &Config{
+		HTTP: HTTPConfig{
+			Addr:              ":8080",
+			ReadHeaderTimeout: 10 * time.Second,
+			IdleTimeout:       2 * time.Minute,
+			ShutdownTimeout:   5 * time.Second,
+		},
+		GRPC:  GRPCConfig{Addr: ":50051"},
+		Admin: AdminConfig{Addr: "127.0.0.1:9090"},
+		Postgres: PostgresConfig{
+			Host:            "localhost",
+			Port:            5432,
+			SSLMode:         "prefer",
+			MaxConns:        10,
+			MinConns:        0,
+			MaxConnLifetime: time.Hour,
+			MaxConnIdleTime: 30 * time.Minute,
+			ConnectTimeout:  5 * time.Second,
+			ConnectAttempts: 5,
+		},
+		Redis: RedisConfig{
+			Addr:        "localhost:6379",
+			PoolSize:    10,
+			DialTimeout: 5 * time.Second,
+		},
+		Kafka: KafkaConfig{
+			Brokers:           []string{"localhost:9092"},
+			ProductTopic:      "product_updates",
+			NotificationTopic: "notifications",
+			WatcherGroup:      "watcher-group",
+			NotifierGroup:     "notifier-group",
+			DialTimeout:       10 * time.Second,
+			WriteBatchTimeout: 10 * time.Millisecond, // writes are synchronous, the outbox relay sends one by one
+			ProductDLQTopic:   "product_updates.dlq",
+			RetryMaxAttempts:  5,
+			RetryBackoff:      time.Second,
+			RetryMaxBackoff:   30 * time.Second,
+			WatcherWorkers:    8,
+			WatcherQueueSize:  16,
+		},
+		Outbox: OutboxConfig{
+			PollInterval: time.Second,
+			BatchSize:    100,
+			MaxBackoff:   5 * time.Minute,
+			MaxAttempts:  20,
+			ClaimTimeout: time.Minute,
+			Retention:    7 * 24 * time.Hour,
+		},
+		Scheduler: SchedulerConfig{
+			PollInterval:     10 * time.Second,
+			BatchSize:        100,
+			DefaultInterval:  6 * time.Hour,
+			JitterPercent:    10,
+			MinInterval:      15 * time.Minute,
+			MaxInterval:      24 * time.Hour,
+			VolatilityWindow: 7 * 24 * time.Hour,
+		},
+		Leader: LeaderConfig{
+			Backend:       "postgres",
+			Name:          "pricepulse-jobs",
+			RenewInterval: 5 * time.Second,
+			LeaseTTL:      15 * time.Second,
+		},
+		Fetcher: FetcherConfig{
+			Timeout:         15 * time.Second,
+			HostInterval:    2 * time.Second,
+			HostBurst:       3,
+			HostConcurrency: 2,
+			HostMaxWait:     time.Minute,
+			Backoff:         5 * time.Minute,
+			MaxBackoff:      6 * time.Hour,
+			RobotsTTL:       24 * time.Hour,
+			RobotsAgent:     "PricePulse",
+
+			CircuitFailures:    5,
+			CircuitOpenTimeout: 5 * time.Minute,
+			CircuitTrials:      1,
+		},
+		Notify: NotifyConfig{
+			Timeout:          10 * time.Second,
+			DispatchTimeout:  15 * time.Second,
+			OperatorCooldown: 6 * time.Hour,
+			OperatorRearm:    true,
+			OperatorTimezone: "UTC",
+		},
+		JWT: JWTConfig{Leeway: 30 * time.Second},
+	}
+}
+
+var sslModes = map[string]bool{
+	"disable": true, "allow": true, "prefer": true, "require": true, "verify-ca": true, "verify-full": true,
+}
+
+// Validate reports every invalid value at once
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+	checkAddr := func(name, addr string) {
+		_, port, err := net.SplitHostPort(addr)
+		check(err == nil && port != "", "%s: %q is not host:port", name, addr)
+	}
+	positive := func(name string, d time.Duration) {
+		check(d > 0, "%s must be positive, got %s", name, d)
+	}
+	clock := func(name, v string) {
+		_, err := time.Parse("15:04", v)
+		check(v == "" || err == nil, "%s %q is not HH:MM", name, v)
+	}
+
+	checkAddr("http.addr", c.HTTP.Addr)
+	positive("http.read_header_timeout", c.HTTP.ReadHeaderTimeout)
+	check(c.HTTP.IdleTimeout >= 0, "http.idle_timeout must not be negative")
+	positive("http.shutdown_timeout", c.HTTP.ShutdownTimeout)
+	checkAddr("grpc.addr", c.GRPC.Addr)
+	checkAddr("admin.addr", c.Admin.Addr)
+	check(c.Admin.User == "" || c.Admin.Password != "", "admin.password is required with admin.user")
+	check((c.Bootstrap.AdminEmail == "") == (c.Bootstrap.AdminAPIKey == ""), "bootstrap.admin_email and admin_api_key go together")
+
+	check(c.Postgres.Host != "", "postgres.host is required")
+	check(c.Postgres.Port > 0 && c.Postgres.Port < 1<<16, "postgres.port %d is out of range", c.Postgres.Port)
+	check(c.Postgres.User != "", "postgres.user is required")
+	check(c.Postgres.Database != "", "postgres.database is required")
+	check(sslModes[c.Postgres.SSLMode], "postgres.sslmode %q is not a libpq sslmode", c.Postgres.SSLMode)
+	check(c.Postgres.MaxConns > 0, "postgres.max_conns must be positive")
+	check(c.Postgres.MinConns >= 0 && c.Postgres.MinConns <= c.Postgres.MaxConns, "postgres.min_conns must be between 0 and max_conns")
+	positive("postgres.max_conn_lifetime", c.Postgres.MaxConnLifetime)
+	positive("postgres.max_conn_idle_time", c.Postgres.MaxConnIdleTime)
+	positive("postgres.connect_timeout", c.Postgres.ConnectTimeout)
+	check(c.Postgres.ConnectAttempts > 0, "postgres.connect_attempts must be positive")
+
+	checkAddr("redis.addr", c.Redis.Addr)
+	check(c.Redis.DB >= 0, "redis.db must not be negative")
+	check(c.Redis.PoolSize > 0, "redis.pool_size must be positive")
+	positive("redis.dial_timeout", c.Redis.DialTimeout)
+	errs = append(errs, c.Redis.TLS.validate("redis.tls"))
+
+	check(len(c.Kafka.Brokers) > 0, "kafka.brokers is required")
+	for _, b := range c.Kafka.Brokers {
+		checkAddr("kafka.brokers", b)
+	}
+	check(c.Kafka.ProductTopic != "", "kafka.product_topic is required")
+	check(c.Kafka.NotificationTopic != "", "kafka.notification_topic is required")
+	check(c.Kafka.WatcherGroup != "", "kafka.watcher_group is required")
+	check(c.Kafka.NotifierGroup != "", "kafka.notifier_group is required")
+	positive("kafka.dial_timeout", c.Kafka.DialTimeout)
+	positive("kafka.write_batch_timeout", c.Kafka.WriteBatchTimeout)
+	errs = append(errs, c.Kafka.TLS.validate("kafka.tls"))
+	check(c.Kafka.ProductDLQTopic != "", "kafka.product_dlq_topic is required")
+	check(c.Kafka.ProductDLQTopic != c.Kafka.ProductTopic, "kafka.product_dlq_topic must differ from kafka.product_topic")
+	check(c.Kafka.RetryMaxAttempts > 0, "kafka.retry_max_attempts must be positive")
+	positive("kafka.retry_backoff", c.Kafka.RetryBackoff)
+	check(c.Kafka.RetryMaxBackoff >= c.Kafka.RetryBackoff, "kafka.retry_max_backoff must not be below kafka.retry_backoff")
+	check(c.Kafka.WatcherWorkers > 0, "kafka.watcher_workers must be positive")
+	check(c.Kafka.WatcherQueueSize >= 0, "kafka.watcher_queue_size must not be negative")
+
+	positive("outbox.poll_interval", c.Outbox.PollInterval)
+	check(c.Outbox.BatchSize > 0, "outbox.batch_size must be positive")
+	positive("outbox.max_backoff", c.Outbox.MaxBackoff)
+	check(c.Outbox.MaxAttempts > 0, "outbox.max_attempts must be positive")
+	positive("outbox.claim_timeout", c.Outbox.ClaimTimeout)
+	positive("outbox.retention", c.Outbox.Retention)
+
+	positive("scheduler.poll_interval", c.Scheduler.PollInterval)
+	check(c.Scheduler.BatchSize > 0, "scheduler.batch_size must be positive")
+	positive("scheduler.default_interval", c.Scheduler.DefaultInterval)
+	check(c.Scheduler.JitterPercent >= 0 && c.Scheduler.JitterPercent < 100, "scheduler.jitter_percent must be between 0 and 99")
+	positive("scheduler.min_interval", c.Scheduler.MinInterval)
+	check(c.Scheduler.MaxInterval >= c.Scheduler.MinInterval, "scheduler.max_interval must not be below scheduler.min_interval")
+	positive("scheduler.volatility_window", c.Scheduler.VolatilityWindow)
+
+	check(c.Leader.Backend == "postgres" || c.Leader.Backend == "redis", "leader.backend %q is neither postgres nor redis", c.Leader.Backend)
+	check(c.Leader.Name != "", "leader.name is required")
+	positive("leader.renew_interval", c.Leader.RenewInterval)
+	check(c.Leader.Backend != "redis" || c.Leader.LeaseTTL >= 2*c.Leader.RenewInterval, "leader.lease_ttl must be at least twice leader.renew_interval")
+
+	positive("fetcher.timeout", c.Fetcher.Timeout)
+	positive("fetcher.host_interval", c.Fetcher.HostInterval)
+	check(c.Fetcher.HostBurst > 0, "fetcher.host_burst must be positive")
+	check(c.Fetcher.HostConcurrency >= 0, "fetcher.host_concurrency must not be negative")
+	positive("fetcher.host_max_wait", c.Fetcher.HostMaxWait)
+	positive("fetcher.backoff", c.Fetcher.Backoff)
+	check(c.Fetcher.MaxBackoff >= c.Fetcher.Backoff, "fetcher.max_backoff must not be below fetcher.backoff")
+	positive("fetcher.robots_ttl", c.Fetcher.RobotsTTL)
+	check(c.Fetcher.CircuitFailures > 0, "fetcher.circuit_failures must be positive")
+	positive("fetcher.circuit_open_timeout", c.Fetcher.CircuitOpenTimeout)
+	check(c.Fetcher.CircuitTrials > 0, "fetcher.circuit_trials must be positive")
+	positive("notify.timeout", c.Notify.Timeout)
+	positive("notify.dispatch_timeout", c.Notify.DispatchTimeout)
+	check(c.Notify.OperatorCooldown >= 0, "notify.operator_cooldown must not be negative")
+	check((c.Notify.OperatorQuietStart == "") == (c.Notify.OperatorQuietEnd == ""), "notify.operator_quiet_start and operator_quiet_end go together")
+	clock("notify.operator_quiet_start", c.Notify.OperatorQuietStart)
+	clock("notify.operator_quiet_end", c.Notify.OperatorQuietEnd)
+	_, err := time.LoadLocation(c.Notify.OperatorTimezone)
+	check(err == nil, "notify.operator_timezone %q is unknown", c.Notify.OperatorTimezone)
+	check(c.JWT.Leeway >= 0, "jwt.leeway must not be negative")
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,112 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	path := writeFile(t, `
+http:
+  addr: ":8000"
+postgres:
+  user: pricepulse
+  database: pricepulse
+  max_conns: 20
+kafka:
+  brokers: [kafka-1:9092, kafka-2:9092]
+  product_topic: from-file
+`)
+	t.Setenv("POSTGRES_MAX_CONNS", "30")
+	t.Setenv("KAFKA_PRODUCT_TOPIC", "from-env")
+	t.Setenv("REDIS_DIAL_TIMEOUT", "2s")
+
+	cfg, err := Load([]string{"-config", path, "-kafka.product_topic=from-flag"})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if cfg.HTTP.Addr != ":8000" {
+		t.Errorf("http.addr = %q, want the file's", cfg.HTTP.Addr)
+	}
+	if cfg.Postgres.MaxConns != 30 {
+		t.Errorf("postgres.max_conns = %d, want env to beat the file", cfg.Postgres.MaxConns)
+	}
+	if cfg.Kafka.ProductTopic != "from-flag" {
+		t.Errorf("kafka.product_topic = %q, want the flag to win", cfg.Kafka.ProductTopic)
+	}
+	if len(cfg.Kafka.Brokers) != 2 {
+		t.Errorf("kafka.brokers = %v, want two", cfg.Kafka.Brokers)
+	}
+	if cfg.Redis.DialTimeout != 2*time.Second {
+		t.Errorf("redis.dial_timeout = %s, want 2s", cfg.Redis.DialTimeout)
+	}
+	if cfg.GRPC.Addr != ":50051" {
+		t.Errorf("grpc.addr = %q, want the default", cfg.GRPC.Addr)
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	t.Setenv("POSTGRES_USER", "pricepulse")
+	t.Setenv("POSTGRES_DB", "pricepulse")
+
+	tests := []struct {
+		name string
+		file string
+		args []string
+		want string
+	}{
+		{"Unknown key", "postgres:\n  max_con: 5\n", nil, "max_con"},
+		{"Bad duration", "", []string{"-fetcher.timeout=soon"}, "fetcher.timeout"},
+		{"Zero pool", "", []string{"-postgres.max_conns=0"}, "postgres.max_conns"},
+		{"Unknown sslmode", "", []string{"-postgres.sslmode=maybe"}, "postgres.sslmode"},
+		{"Broker without port", "", []string{"-kafka.brokers=kafka"}, "kafka.brokers"},
+		{"Half a client certificate", "", []string{"-kafka.tls.enabled=true", "-kafka.tls.cert_file=client.pem"}, "kafka.tls"},
+		{"Missing CA", "", []string{"-redis.tls.enabled=true", "-redis.tls.ca_file=/nonexistent/ca.pem"}, "redis.tls"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := tt.args
+			if tt.file != "" {
+				args = append([]string{"-config", writeFile(t, tt.file)}, args...)
+			}
+			_, err := Load(args)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Load() error = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_StringRedactsSecrets(t *testing.T) {
+	cfg := Default()
+	cfg.Postgres.Password = "pg-secret"
+	cfg.JWT.HS256Secret = "jwt-secret"
+	cfg.Notify.SlackBotToken = "xoxb-secret"
+
+	out := cfg.String()
+	for _, secret := range []string{"pg-secret", "jwt-secret", "xoxb-secret"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("String() leaks %q", secret)
+		}
+	}
+	if !strings.Contains(out, redacted) || !strings.Contains(out, "read_header_timeout: 10s") {
+		t.Errorf("String() = %s, want redacted secrets and readable durations", out)
+	}
+	if cfg.Postgres.Password != "pg-secret" {
+		t.Error("String() must not change the config")
+	}
+}
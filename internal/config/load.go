@@ -0,0 +1,177 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileEnv names the YAML file when -config is not given
+const FileEnv = "CONFIG_FILE"
+
+const redacted = "[REDACTED]"
+
+// Load builds the configuration from defaults, the YAML file, the environment and args,
+// later sources winning, and validates it. args are the command line without the program name.
+func Load(args []string) (*Config, error) {
+	cfg := Default()
+	fields := collect(reflect.ValueOf(cfg).Elem(), "", "")
+
+	fs := flag.NewFlagSet("pricepulse", flag.ContinueOnError)
+	path := fs.String("config", os.Getenv(FileEnv), "YAML configuration file")
+	for _, f := range fields {
+		fs.String(f.path, "", "overrides "+f.env)
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if *path != "" {
+		if err := loadFile(cfg, *path); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, f := range fields {
+		if v, ok := os.LookupEnv(f.env); ok {
+			if err := f.set(v); err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", f.env, err)
+			}
+		}
+	}
+
+	byPath := make(map[string]field, len(fields))
+	for _, f := range fields {
+		byPath[f.path] = f
+	}
+	var flagErr error
+	fs.Visit(func(fl *flag.Flag) {
+		if f, ok := byPath[fl.Name]; ok && flagErr == nil {
+			if err := f.set(fl.Value.String()); err != nil {
+				flagErr = fmt.Errorf("invalid -%s: %w", fl.Name, err)
+			}
+		}
+	})
+	if flagErr != nil {
+		return nil, flagErr
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	return cfg, nil
+}
+
+// loadFile rejects unknown keys, a typo should not silently fall back to a default
+func loadFile(cfg *Config, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return nil
+}
+
+// String is the effective configuration as YAML with secrets redacted, safe to log
+func (c *Config) String() string {
+	cp := *c
+	for _, f := range collect(reflect.ValueOf(&cp).Elem(), "", "") {
+		if f.secret && f.value.String() != "" {
+			f.value.SetString(redacted)
+		}
+	}
+
+	out, err := yaml.Marshal(&cp)
+	if err != nil {
+		return "error: " + err.Error()
+	}
+	return string(out)
+}
+
+// field is one leaf of the configuration. path is the YAML path and doubles as flag name.
+type field struct {
+	path   string
+	env    string
+	secret bool
+	value  reflect.Value
+}
+
+func collect(v reflect.Value, path, env string) []field {
+	var out []field
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name, _, _ := strings.Cut(sf.Tag.Get("yaml"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		if path != "" {
+			name = path + "." + name
+		}
+		envName := sf.Tag.Get("env")
+		if env != "" && envName != "" {
+			envName = env + "_" + envName
+		}
+
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Struct {
+			out = append(out, collect(fv, name, envName)...)
+			continue
+		}
+		out = append(out, field{path: name, env: envName, secret: sf.Tag.Get("secret") == "true", value: fv})
+	}
+	return out
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func (f field) set(s string) error {
+	v := f.value
+	switch {
+	case v.Type() == durationType:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+	case v.Kind() == reflect.String:
+		v.SetString(s)
+	case v.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case v.Kind() == reflect.Int:
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(n))
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.String:
+		var items []string
+		for _, item := range strings.Split(s, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package database
+
+import (
+	"context"
+
+	"github.com/derkres11/price-pulse/internal/domain"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type AlertRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewAlertRepo(db *pgxpool.Pool) *AlertRepo {
+	return &AlertRepo{db: db}
+}
+
+func (r *AlertRepo) CreateRule(ctx context.Context, a *domain.AlertRule) error {
+	query := `
+            INSERT INTO alert_rules(product_id, subscription_id, type, threshold, percent, baseline, days, enabled)
+            VALUES ($1, NULLIF($2, 0), $3, $4, $5, $6, $7, $8)
+            RETURNING id, created_at`
+
+	return dbError(r.db.QueryRow(ctx, query, a.ProductID, a.SubscriptionID, a.Type, a.Threshold, a.Percent, a.Baseline, a.Days, a.Enabled).
+		Scan(&a.ID, &a.CreatedAt))
+}
+
+func (r *AlertRepo) ListRules(ctx context.Context, productID int64) ([]*domain.AlertRule, error) {
+	return r.listRules(ctx, `WHERE product_id = $1`, productID)
+}
+
+func (r *AlertRepo) ListSubscriptionRules(ctx context.Context, subscriptionID int64) ([]*domain.AlertRule, error) {
+	return r.listRules(ctx, `WHERE subscription_id = $1`, subscriptionID)
+}
+
+func (r *AlertRepo) listRules(ctx context.Context, where string, id int64) ([]*domain.AlertRule, error) {
+	query := `
+            SELECT id, product_id, COALESCE(subscription_id, 0), type, threshold, percent, baseline, days, enabled, created_at
+            FROM alert_rules ` + where + `
+            ORDER BY id`
+
+	rows, err := r.db.Query(ctx, query, id)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+
+	var rules []*domain.AlertRule
+	for rows.Next() {
+		a := &domain.AlertRule{}
+		if err := rows.Scan(&a.ID, &a.ProductID, &a.SubscriptionID, &a.Type, &a.Threshold, &a.Percent, &a.Baseline, &a.Days, &a.Enabled, &a.CreatedAt); err != nil {
+			return nil, dbError(err)
+		}
+		rules = append(rules, a)
+	}
+	return rules, dbError(rows.Err())
+}
+
+func (r *AlertRepo) DeleteRule(ctx context.Context, productID, ruleID int64) error {
+	query := `DELETE FROM alert_rules WHERE id = $1 AND product_id = $2 AND subscription_id IS NULL`
+	return r.deleteRule(ctx, query, ruleID, productID)
+}
+
+func (r *AlertRepo) DeleteSubscriptionRule(ctx context.Context, subscriptionID, ruleID int64) error {
+	query := `DELETE FROM alert_rules WHERE id = $1 AND subscription_id = $2`
+	return r.deleteRule(ctx, query, ruleID, subscriptionID)
+}
+
+func (r *AlertRepo) deleteRule(ctx context.Context, query string, args ...any) error {
+	tag, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return dbError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *AlertRepo) SaveEvent(ctx context.Context, e *domain.AlertEvent) error {
+	query := `
+            INSERT INTO alert_events(rule_id, product_id, subscription_id, type, price, previous_price, reason)
+            VALUES (NULLIF($1, 0), $2, NULLIF($3, 0), $4, $5, $6, $7)
+            RETURNING id, created_at`
+
+	return dbError(r.db.QueryRow(ctx, query, e.RuleID, e.ProductID, e.SubscriptionID, e.Type, e.Price, e.PreviousPrice, e.Reason).
+		Scan(&e.ID, &e.CreatedAt))
+}
+
+func (r *AlertRepo) ListEvents(ctx context.Context, productID, userID int64, limit int) ([]*domain.AlertEvent, error) {
+	query := `
+            SELECT e.id, COALESCE(e.rule_id, 0), e.product_id, COALESCE(e.subscription_id, 0), COALESCE(s.user_id, 0),
+                e.type, e.price, e.previous_price, e.reason, e.created_at
+            FROM alert_events e
+            LEFT JOIN subscriptions s ON s.id = e.subscription_id
+            WHERE e.product_id = $1 AND (e.subscription_id IS NULL OR s.user_id = $2)
+            ORDER BY e.created_at DESC
+            LIMIT $3`
+
+	rows, err := r.db.Query(ctx, query, productID, userID, limit)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+
+	var events []*domain.AlertEvent
+	for rows.Next() {
+		e := &domain.AlertEvent{}
+		if err := rows.Scan(&e.ID, &e.RuleID, &e.ProductID, &e.SubscriptionID, &e.UserID,
+			&e.Type, &e.Price, &e.PreviousPrice, &e.Reason, &e.CreatedAt); err != nil {
+			return nil, dbError(err)
+		}
+		events = append(events, e)
+	}
+	return events, dbError(rows.Err())
+}
+
+func (r *AlertRepo) RearmRules(ctx context.Context, productID int64, matched []*domain.AlertEvent) error {
+	// Claimed rows are still armed, the version bump tells the delivery in flight that
+	// the rule cleared meanwhile and must not be disarmed
+	query := `
+            UPDATE alert_state s
+            SET armed = TRUE, version = s.version + 1
+            WHERE s.product_id = $1
+                AND (NOT s.armed OR s.claimed_until > NOW())
+                AND NOT EXISTS (
+                    SELECT 1 FROM unnest($2::bigint[], $3::bigint[]) AS m(rule_id, subscription_id)
+                    WHERE m.rule_id = s.rule_id AND m.subscription_id = s.subscription_id
+                )`
+
+	ruleIDs := make([]int64, 0, len(matched))
+	subscriptionIDs := make([]int64, 0, len(matched))
+	for _, e := range matched {
+		ruleIDs = append(ruleIDs, e.RuleID)
+		subscriptionIDs = append(subscriptionIDs, e.SubscriptionID)
+	}
+
+	_, err := r.db.Exec(ctx, query, productID, ruleIDs, subscriptionIDs)
+	return dbError(err)
+}
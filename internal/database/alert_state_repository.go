@@ -0,0 +1,216 @@
+package database
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/derkres11/price-pulse/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type AlertStateRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewAlertStateRepo(db *pgxpool.Pool) *AlertStateRepo {
+	return &AlertStateRepo{db: db}
+}
+
+func (r *AlertStateRepo) Policy(ctx context.Context, subscriptionID int64) (*domain.AlertPolicy, error) {
+	query := `
+            SELECT s.cooldown_minutes, s.rearm, u.quiet_start, u.quiet_end, u.timezone
+            FROM subscriptions s
+            JOIN users u ON u.id = s.user_id
+            WHERE s.id = $1`
+
+	p := &domain.AlertPolicy{}
+	if err := conn(ctx, r.db).QueryRow(ctx, query, subscriptionID).
+		Scan(&p.CooldownMinutes, &p.Rearm, &p.QuietStart, &p.QuietEnd, &p.Timezone); err != nil {
+		return nil, dbError(err)
+	}
+	return p, nil
+}
+
+func (r *AlertStateRepo) WithState(
+	ctx context.Context,
+	subscriptionID, productID, ruleID int64,
+	fn func(ctx context.Context, st *domain.AlertState) (*domain.AlertState, error),
+) error {
+	return NewTransactor(r.db).InTx(ctx, func(ctx context.Context) error {
+		db := conn(ctx, r.db)
+
+		// Make sure there is a row to lock, otherwise two replicas could both see "never sent"
+		insert := `
+            INSERT INTO alert_state(subscription_id, product_id, rule_id)
+            VALUES ($1, $2, $3)
+            ON CONFLICT DO NOTHING`
+		if _, err := db.Exec(ctx, insert, subscriptionID, productID, ruleID); err != nil {
+			return dbError(err)
+		}
+
+		query := `
+            SELECT last_price, last_sent_at, armed, version, claimed_until
+            FROM alert_state
+            WHERE subscription_id = $1 AND product_id = $2 AND rule_id = $3
+            FOR UPDATE`
+
+		st := &domain.AlertState{SubscriptionID: subscriptionID, ProductID: productID, RuleID: ruleID}
+		var sentAt, claimedUntil *time.Time
+		if err := db.QueryRow(ctx, query, subscriptionID, productID, ruleID).
+			Scan(&st.LastPrice, &sentAt, &st.Armed, &st.Version, &claimedUntil); err != nil {
+			return dbError(err)
+		}
+		if claimedUntil != nil {
+			st.ClaimedUntil = *claimedUntil
+		}
+
+		current := st
+		if sentAt == nil && claimedUntil == nil {
+			current = nil
+		} else if sentAt != nil {
+			st.LastSentAt = *sentAt
+		}
+
+		next, err := fn(ctx, current)
+		if err != nil || next == nil {
+			return err
+		}
+
+		update := `
+            UPDATE alert_state
+            SET last_price = $4, last_sent_at = $5, armed = $6, claimed_until = $7, version = version + 1
+            WHERE subscription_id = $1 AND product_id = $2 AND rule_id = $3
+            RETURNING version`
+		return dbError(db.QueryRow(ctx, update, subscriptionID, productID, ruleID,
+			next.LastPrice, nullTime(next.LastSentAt), next.Armed, nullTime(next.ClaimedUntil)).Scan(&next.Version))
+	})
+}
+
+func (r *AlertStateRepo) RecordSent(ctx context.Context, claim, sent *domain.AlertState) error {
+	// RearmRules bumps the version as well, the rule stays armed if it ran meanwhile
+	query := `
+            UPDATE alert_state
+            SET last_price = $4, last_sent_at = $5,
+                armed = CASE WHEN version = $6 THEN $7 ELSE armed END,
+                claimed_until = NULL, version = version + 1
+            WHERE subscription_id = $1 AND product_id = $2 AND rule_id = $3`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query, claim.SubscriptionID, claim.ProductID, claim.RuleID,
+		sent.LastPrice, sent.LastSentAt, claim.Version, sent.Armed)
+	return dbError(err)
+}
+
+func (r *AlertStateRepo) ReleaseClaim(ctx context.Context, claim *domain.AlertState) error {
+	query := `
+            UPDATE alert_state
+            SET claimed_until = NULL, version = version + 1
+            WHERE subscription_id = $1 AND product_id = $2 AND rule_id = $3`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query, claim.SubscriptionID, claim.ProductID, claim.RuleID)
+	return dbError(err)
+}
+
+func (r *AlertStateRepo) QueueDigest(ctx context.Context, userID int64, n *domain.Notification) error {
+	payload, err := json.Marshal(n)
+	if err != nil {
+		return fmt.Errorf("failed to encode digest item: %w", err)
+	}
+
+	_, err = conn(ctx, r.db).Exec(ctx, `INSERT INTO digest_queue(user_id, payload) VALUES (NULLIF($1, 0), $2)`, userID, payload)
+	return dbError(err)
+}
+
+func (r *AlertStateRepo) UsersWithDigest(ctx context.Context) ([]*domain.User, error) {
+	query := `
+            SELECT DISTINCT COALESCE(d.user_id, 0), COALESCE(u.quiet_start, ''), COALESCE(u.quiet_end, ''), COALESCE(u.timezone, '')
+            FROM digest_queue d
+            LEFT JOIN users u ON u.id = d.user_id
+            ORDER BY 1`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+
+	var users []*domain.User
+	for rows.Next() {
+		u := &domain.User{}
+		if err := rows.Scan(&u.ID, &u.QuietStart, &u.QuietEnd, &u.Timezone); err != nil {
+			return nil, dbError(err)
+		}
+		users = append(users, u)
+	}
+	return users, dbError(rows.Err())
+}
+
+func (r *AlertStateRepo) FlushDigest(ctx context.Context, userID int64, until time.Time, fn func(items []*domain.Notification) error) error {
+	// The update waits for rows another replica is claiming and then skips them,
+	// their claimed_until is in the future by then
+	claim := `
+            UPDATE digest_queue
+            SET claimed_until = $2
+            WHERE user_id IS NOT DISTINCT FROM NULLIF($1, 0)
+                AND (claimed_until IS NULL OR claimed_until < NOW())
+            RETURNING id, payload`
+
+	rows, err := r.db.Query(ctx, claim, userID, until)
+	if err != nil {
+		return dbError(err)
+	}
+
+	type queued struct {
+		id      int64
+		payload []byte
+	}
+	batch, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (queued, error) {
+		var q queued
+		err := row.Scan(&q.id, &q.payload)
+		return q, err
+	})
+	if err != nil {
+		return dbError(err)
+	}
+	if len(batch) == 0 {
+		return nil
+	}
+
+	// RETURNING has no ORDER BY, restore the order the alerts were queued in
+	slices.SortFunc(batch, func(a, b queued) int { return cmp.Compare(a.id, b.id) })
+
+	ids := make([]int64, 0, len(batch))
+	items := make([]*domain.Notification, 0, len(batch))
+	for _, q := range batch {
+		n := &domain.Notification{}
+		if err := json.Unmarshal(q.payload, n); err != nil {
+			return fmt.Errorf("corrupt digest item %d: %w", q.id, err)
+		}
+		ids = append(ids, q.id)
+		items = append(items, n)
+	}
+
+	// Whatever happens to the request, the claim must not outlive the delivery
+	ctx = context.WithoutCancel(ctx)
+	if err := fn(items); err != nil {
+		if _, releaseErr := r.db.Exec(ctx, `UPDATE digest_queue SET claimed_until = NULL WHERE id = ANY($1)`, ids); releaseErr != nil {
+			return fmt.Errorf("%w (and releasing the claim: %v)", err, dbError(releaseErr))
+		}
+		return err
+	}
+
+	_, err = r.db.Exec(ctx, `DELETE FROM digest_queue WHERE id = ANY($1)`, ids)
+	return dbError(err)
+}
+
+// nullTime stores the zero time as NULL
+func nullTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
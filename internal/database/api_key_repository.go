@@ -0,0 +1,80 @@
+package database
+
+import (
+	"context"
+
+	"github.com/derkres11/price-pulse/internal/domain"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type APIKeyRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewAPIKeyRepo(db *pgxpool.Pool) *APIKeyRepo {
+	return &APIKeyRepo{db: db}
+}
+
+func (r *APIKeyRepo) Create(ctx context.Context, k *domain.APIKey) error {
+	query := `
+            INSERT INTO api_keys(user_id, name, prefix, hash, admin)
+            VALUES ($1, $2, $3, $4, $5)
+            RETURNING id, created_at`
+
+	return dbError(conn(ctx, r.db).QueryRow(ctx, query, k.UserID, k.Name, k.Prefix, k.Hash, k.Admin).Scan(&k.ID, &k.CreatedAt))
+}
+
+func (r *APIKeyRepo) GetByPrefix(ctx context.Context, prefix string) (*domain.APIKey, error) {
+	keys, err := r.list(ctx, `WHERE prefix = $1`, prefix)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, domain.ErrNotFound
+	}
+	return keys[0], nil
+}
+
+func (r *APIKeyRepo) ListByUser(ctx context.Context, userID int64) ([]*domain.APIKey, error) {
+	return r.list(ctx, `WHERE user_id = $1`, userID)
+}
+
+func (r *APIKeyRepo) Revoke(ctx context.Context, userID, id int64) error {
+	query := `UPDATE api_keys SET revoked_at = NOW() WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`
+	tag, err := r.db.Exec(ctx, query, id, userID)
+	if err != nil {
+		return dbError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *APIKeyRepo) Touch(ctx context.Context, id int64) error {
+	_, err := r.db.Exec(ctx, `UPDATE api_keys SET last_used_at = NOW() WHERE id = $1`, id)
+	return dbError(err)
+}
+
+func (r *APIKeyRepo) list(ctx context.Context, where string, args ...any) ([]*domain.APIKey, error) {
+	query := `
+            SELECT id, user_id, name, prefix, hash, admin, created_at, last_used_at, revoked_at
+            FROM api_keys ` + where + `
+            ORDER BY id`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, args...)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+
+	var keys []*domain.APIKey
+	for rows.Next() {
+		k := &domain.APIKey{}
+		if err := rows.Scan(&k.ID, &k.UserID, &k.Name, &k.Prefix, &k.Hash, &k.Admin, &k.CreatedAt, &k.LastUsedAt, &k.RevokedAt); err != nil {
+			return nil, dbError(err)
+		}
+		keys = append(keys, k)
+	}
+	return keys, dbError(rows.Err())
+}
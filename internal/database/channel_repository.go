@@ -0,0 +1,69 @@
+package database
+
+import (
+	"context"
+
+	"github.com/derkres11/price-pulse/internal/domain"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ChannelRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewChannelRepo(db *pgxpool.Pool) *ChannelRepo {
+	return &ChannelRepo{db: db}
+}
+
+func (r *ChannelRepo) Create(ctx context.Context, ch *domain.NotificationChannel) error {
+	query := `
+            INSERT INTO notification_channels(user_id, name, type, target, secret, template, enabled)
+            VALUES (NULLIF($1, 0), $2, $3, $4, $5, $6, $7)
+            RETURNING id, created_at`
+
+	return dbError(r.db.QueryRow(ctx, query, ch.UserID, ch.Name, ch.Type, ch.Target, ch.Secret, ch.Template, ch.Enabled).
+		Scan(&ch.ID, &ch.CreatedAt))
+}
+
+func (r *ChannelRepo) GetEnabled(ctx context.Context) ([]*domain.NotificationChannel, error) {
+	return r.list(ctx, `WHERE enabled`)
+}
+
+func (r *ChannelRepo) ListByUser(ctx context.Context, userID int64) ([]*domain.NotificationChannel, error) {
+	return r.list(ctx, `WHERE COALESCE(user_id, 0) = $1`, userID)
+}
+
+func (r *ChannelRepo) Delete(ctx context.Context, userID, id int64) error {
+	query := `DELETE FROM notification_channels WHERE id = $1 AND COALESCE(user_id, 0) = $2`
+	tag, err := r.db.Exec(ctx, query, id, userID)
+	if err != nil {
+		return dbError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *ChannelRepo) list(ctx context.Context, where string, args ...any) ([]*domain.NotificationChannel, error) {
+	query := `
+            SELECT id, COALESCE(user_id, 0), name, type, target, secret, template, enabled, created_at
+            FROM notification_channels ` + where + `
+            ORDER BY id`
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+
+	var channels []*domain.NotificationChannel
+	for rows.Next() {
+		ch := &domain.NotificationChannel{}
+		if err := rows.Scan(&ch.ID, &ch.UserID, &ch.Name, &ch.Type, &ch.Target, &ch.Secret, &ch.Template, &ch.Enabled, &ch.CreatedAt); err != nil {
+			return nil, dbError(err)
+		}
+		channels = append(channels, ch)
+	}
+	return channels, dbError(rows.Err())
+}
@@ -0,0 +1,153 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/derkres11/price-pulse/internal/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// circuitTTL forgets the circuit of a host nobody fetched for a day. It outlasts any
+	// request, so no outcome turns up for a circuit that was forgotten.
+	circuitTTL = 24 * time.Hour
+	// circuitIndex is the set of hosts with a stored circuit
+	circuitIndex = "circuits"
+	// circuitRetries bounds the updates that lost to another replica's in a row
+	circuitRetries = 10
+)
+
+// RedisCircuits keeps the circuit breakers of all hosts in Redis, so every replica, and
+// the leader deferring checks in particular, sees the failures of all of them. A circuit
+// is a hash updated in a transaction that fails when another replica wrote it since it
+// was read.
+type RedisCircuits struct {
+	client *redis.Client
+}
+
+func NewRedisCircuits(opts *redis.Options) *RedisCircuits {
+	return &RedisCircuits{
+		client: redis.NewClient(opts),
+	}
+}
+
+func circuitKey(host string) string {
+	return "circuit:{" + host + "}"
+}
+
+func (s *RedisCircuits) Get(ctx context.Context, host string) (*domain.Circuit, error) {
+	return loadCircuit(ctx, s.client, host)
+}
+
+func (s *RedisCircuits) Update(ctx context.Context, host string, fn func(c *domain.Circuit) error) error {
+	key := circuitKey(host)
+	var stored bool
+	update := func(tx *redis.Tx) error {
+		c, err := loadCircuit(ctx, tx, host)
+		if err != nil {
+			return err
+		}
+		before := *c
+		if err := fn(c); err != nil {
+			return err
+		}
+		if stored = *c != before; !stored {
+			return nil // e.g. a success while closed
+		}
+
+		_, err = tx.TxPipelined(ctx, func(p redis.Pipeliner) error {
+			p.HSet(ctx, key, circuitHash{
+				State:      string(c.State),
+				Generation: c.Generation,
+				Failures:   c.Failures,
+				Trials:     c.Trials,
+				Succeeded:  c.Succeeded,
+				OpenedAt:   c.OpenedAt.UnixMilli(),
+				ChangedAt:  c.ChangedAt.UnixMilli(),
+			})
+			p.Expire(ctx, key, circuitTTL)
+			return nil
+		})
+		return err
+	}
+
+	for range circuitRetries {
+		err := s.client.Watch(ctx, update, key)
+		if errors.Is(err, redis.TxFailedErr) {
+			continue
+		}
+		if err != nil || !stored {
+			return err
+		}
+		// The index is another key, outside the transaction
+		return s.client.SAdd(ctx, circuitIndex, host).Err()
+	}
+	return fmt.Errorf("error updating circuit of %s: too many concurrent updates", host)
+}
+
+func (s *RedisCircuits) List(ctx context.Context) ([]*domain.Circuit, error) {
+	hosts, err := s.client.SMembers(ctx, circuitIndex).Result()
+	if err != nil {
+		return nil, fmt.Errorf("error listing circuits: %w", err)
+	}
+
+	circuits := []*domain.Circuit{}
+	for _, host := range hosts {
+		c, err := loadCircuit(ctx, s.client, host)
+		if err != nil {
+			return nil, err
+		}
+		if c.State == domain.CircuitClosed && c.Failures == 0 {
+			// Healthy or expired, the next failure puts it back
+			_ = s.client.SRem(ctx, circuitIndex, host).Err()
+			continue
+		}
+		circuits = append(circuits, c)
+	}
+	return circuits, nil
+}
+
+func (s *RedisCircuits) Close() error {
+	return s.client.Close()
+}
+
+// circuitHash is a circuit as stored, times in Unix milliseconds
+type circuitHash struct {
+	State      string `redis:"state"`
+	Generation int64  `redis:"generation"`
+	Failures   int    `redis:"failures"`
+	Trials     int    `redis:"trials"`
+	Succeeded  int    `redis:"succeeded"`
+	OpenedAt   int64  `redis:"opened_at"`
+	ChangedAt  int64  `redis:"changed_at"`
+}
+
+// loadCircuit reads the circuit of host, a closed one when there is none
+func loadCircuit(ctx context.Context, client redis.Cmdable, host string) (*domain.Circuit, error) {
+	cmd := client.HGetAll(ctx, circuitKey(host))
+	fields, err := cmd.Result()
+	if err != nil {
+		return nil, fmt.Errorf("error loading circuit of %s: %w", host, err)
+	}
+	if len(fields) == 0 {
+		return &domain.Circuit{Host: host, State: domain.CircuitClosed}, nil
+	}
+
+	var h circuitHash
+	if err := cmd.Scan(&h); err != nil {
+		return nil, fmt.Errorf("error loading circuit of %s: %w", host, err)
+	}
+	return &domain.Circuit{
+		Host:       host,
+		State:      domain.CircuitState(h.State),
+		Generation: h.Generation,
+		Failures:   h.Failures,
+		OpenedAt:   time.UnixMilli(h.OpenedAt),
+		ChangedAt:  time.UnixMilli(h.ChangedAt),
+		Trials:     h.Trials,
+		Succeeded:  h.Succeeded,
+	}, nil
+}
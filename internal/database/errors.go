@@ -0,0 +1,56 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/derkres11/price-pulse/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Postgres error codes the repositories translate
+const (
+	uniqueViolation      = "23505"
+	foreignKeyViolation  = "23503"
+	checkViolation       = "23514"
+	notNullViolation     = "23502"
+	invalidTextValue     = "22P02"
+	numericValueOutRange = "22003"
+)
+
+// dbError translates driver errors into the domain error kinds. The original error stays
+// in the chain for logs; errors that are already translated pass through unchanged.
+func dbError(err error) error {
+	if err == nil || isTranslated(err) {
+		return err
+	}
+
+	var pgErr *pgconn.PgError
+	var connectErr *pgconn.ConnectError
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return fmt.Errorf("%w: %w", domain.ErrNotFound, err)
+	case errors.As(err, &pgErr):
+		switch pgErr.Code {
+		case uniqueViolation:
+			return fmt.Errorf("%w: %w", domain.ErrAlreadyExists, err)
+		case foreignKeyViolation:
+			return fmt.Errorf("%w: referenced row is missing: %w", domain.ErrNotFound, err)
+		case checkViolation, notNullViolation, invalidTextValue, numericValueOutRange:
+			return fmt.Errorf("%w: %w", domain.ErrInvalidArgument, err)
+		}
+	case errors.As(err, &connectErr), pgconn.Timeout(err):
+		return fmt.Errorf("%w: %w", domain.ErrUnavailable, err)
+	}
+	return err
+}
+
+func isTranslated(err error) bool {
+	for _, kind := range []error{domain.ErrNotFound, domain.ErrAlreadyExists, domain.ErrInvalidArgument, domain.ErrUnavailable} {
+		if errors.Is(err, kind) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/derkres11/price-pulse/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestDBError(t *testing.T) {
+	unique := &pgconn.PgError{Code: uniqueViolation}
+
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"No rows", pgx.ErrNoRows, domain.ErrNotFound},
+		{"Wrapped no rows", fmt.Errorf("scan: %w", pgx.ErrNoRows), domain.ErrNotFound},
+		{"Unique violation", unique, domain.ErrAlreadyExists},
+		{"Foreign key violation", &pgconn.PgError{Code: foreignKeyViolation}, domain.ErrNotFound},
+		{"Check violation", &pgconn.PgError{Code: checkViolation}, domain.ErrInvalidArgument},
+		{"Bad input syntax", &pgconn.PgError{Code: invalidTextValue}, domain.ErrInvalidArgument},
+		{"Already translated", domain.ErrNotFound, domain.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := dbError(tt.err)
+			if !errors.Is(got, tt.want) {
+				t.Errorf("dbError(%v) = %v, want kind %v", tt.err, got, tt.want)
+			}
+			if !errors.Is(got, tt.err) {
+				t.Errorf("dbError(%v) lost the original error", tt.err)
+			}
+		})
+	}
+
+	if got := dbError(nil); got != nil {
+		t.Errorf("dbError(nil) = %v, want nil", got)
+	}
+	other := errors.New("boom")
+	if got := dbError(other); got != other {
+		t.Errorf("dbError(%v) = %v, want it unchanged", other, got)
+	}
+	if errors.Is(dbError(&pgconn.PgError{Code: "40001"}), domain.ErrInvalidArgument) {
+		t.Error("serialization failure must not turn into a client error")
+	}
+}
@@ -0,0 +1,136 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"time"
+
+	"github.com/derkres11/price-pulse/internal/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+// slotPoll is how often a request waiting for a concurrency slot looks again
+const slotPoll = 100 * time.Millisecond
+
+// HostLimits are the limits every host gets
+type HostLimits struct {
+	Burst         int // requests a host that was left alone may get back to back
+	MaxConcurrent int // requests in flight to one host, 0 for no cap
+	// SlotTTL frees the slot of a replica that died mid-request, it must outlast a request
+	SlotTTL time.Duration
+}
+
+// RedisHostLimiter is a token bucket and a concurrency cap per host kept in Redis, so
+// all replicas together stay within the limits. The time is Redis', not the replicas'.
+type RedisHostLimiter struct {
+	client *redis.Client
+	limits HostLimits
+}
+
+func NewRedisHostLimiter(opts *redis.Options, limits HostLimits) *RedisHostLimiter {
+	return &RedisHostLimiter{
+		client: redis.NewClient(opts),
+		limits: limits,
+	}
+}
+
+var (
+	// acquireRequest returns 0 once a token and a slot are taken, else the milliseconds
+	// to wait for the token or the block to end, -1 to wait for a slot
+	acquireRequest = redis.NewScript(`
+local blocked = redis.call("PTTL", KEYS[3])
+if blocked > 0 then
+    return blocked
+end
+
+local t = redis.call("TIME")
+local now = t[1] * 1000 + math.floor(t[2] / 1000)
+local interval = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local cap = tonumber(ARGV[3])
+local ttl = tonumber(ARGV[4])
+
+redis.call("ZREMRANGEBYSCORE", KEYS[2], "-inf", now)
+if cap > 0 and redis.call("ZCARD", KEYS[2]) >= cap then
+    return -1
+end
+
+local bucket = redis.call("HMGET", KEYS[1], "tokens", "at")
+local tokens = tonumber(bucket[1]) or burst
+local at = tonumber(bucket[2]) or now
+tokens = math.min(burst, tokens + (now - at) / interval)
+if tokens < 1 then
+    return math.ceil((1 - tokens) * interval)
+end
+
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens - 1), "at", now)
+redis.call("PEXPIRE", KEYS[1], interval * burst + 1000)
+redis.call("ZADD", KEYS[2], now + ttl, ARGV[5])
+redis.call("PEXPIRE", KEYS[2], ttl)
+return 0`)
+
+	// blockHost never shortens a block already in place
+	blockHost = redis.NewScript(`
+if redis.call("PTTL", KEYS[1]) < tonumber(ARGV[1]) then
+    redis.call("SET", KEYS[1], 1, "PX", ARGV[1])
+end
+return 0`)
+)
+
+// hostKeys are the bucket, the slots and the block of host. The braces keep them in
+// one Redis Cluster slot, the scripts touch them together.
+func hostKeys(host string) []string {
+	prefix := "ratelimit:{" + host + "}:"
+	return []string{prefix + "bucket", prefix + "slots", prefix + "blocked"}
+}
+
+func (l *RedisHostLimiter) Acquire(ctx context.Context, host string, interval time.Duration) (func(), error) {
+	keys := hostKeys(host)
+	slot := strconv.FormatUint(rand.Uint64(), 36)
+	interval = max(interval, time.Millisecond)
+
+	for {
+		wait, err := acquireRequest.Run(ctx, l.client, keys,
+			interval.Milliseconds(), max(l.limits.Burst, 1), l.limits.MaxConcurrent, l.limits.SlotTTL.Milliseconds(), slot).Int64()
+		if err != nil {
+			return nil, fmt.Errorf("error pacing request to %s: %w", host, err)
+		}
+		if wait == 0 {
+			return func() { l.release(ctx, keys[1], slot) }, nil
+		}
+
+		d := time.Duration(wait) * time.Millisecond
+		if wait < 0 {
+			d = slotPoll
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < d {
+			return nil, fmt.Errorf("%w: %s for another %s", domain.ErrHostThrottled, host, d)
+		}
+
+		t := time.NewTimer(d)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return nil, ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// release frees the slot even when the request's context is gone, else it would be
+// held until SlotTTL
+func (l *RedisHostLimiter) release(ctx context.Context, key, slot string) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), time.Second)
+	defer cancel()
+	_ = l.client.ZRem(ctx, key, slot).Err()
+}
+
+func (l *RedisHostLimiter) Block(ctx context.Context, host string, d time.Duration) error {
+	return blockHost.Run(ctx, l.client, hostKeys(host)[2:], d.Milliseconds()).Err()
+}
+
+func (l *RedisHostLimiter) Close() error {
+	return l.client.Close()
+}
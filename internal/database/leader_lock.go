@@ -0,0 +1,119 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+)
+
+// AdvisoryLock is a leader lock on a Postgres session level advisory lock. The lock
+// lives as long as the session, so it holds one pool connection while leading; when
+// that connection dies Postgres releases the lock and another replica can take it.
+type AdvisoryLock struct {
+	db   *pgxpool.Pool
+	name string
+	conn *pgxpool.Conn // nil unless leading
+}
+
+// NewAdvisoryLock names the lock, replicas using the same name compete for it
+func NewAdvisoryLock(db *pgxpool.Pool, name string) *AdvisoryLock {
+	return &AdvisoryLock{db: db, name: name}
+}
+
+func (l *AdvisoryLock) TryAcquire(ctx context.Context) (bool, error) {
+	if l.conn != nil {
+		if err := l.conn.Ping(ctx); err != nil {
+			l.drop()
+			return false, dbError(err)
+		}
+		return true, nil
+	}
+
+	conn, err := l.db.Acquire(ctx)
+	if err != nil {
+		return false, dbError(err)
+	}
+	var locked bool
+	if err := conn.QueryRow(ctx, `SELECT pg_try_advisory_lock(hashtextextended($1, 0))`, l.name).Scan(&locked); err != nil {
+		conn.Release()
+		return false, dbError(err)
+	}
+	if !locked {
+		conn.Release()
+		return false, nil
+	}
+	l.conn = conn
+	return true, nil
+}
+
+func (l *AdvisoryLock) Release(ctx context.Context) error {
+	if l.conn == nil {
+		return nil
+	}
+	if _, err := l.conn.Exec(ctx, `SELECT pg_advisory_unlock(hashtextextended($1, 0))`, l.name); err != nil {
+		l.drop() // ending the session releases the lock as well
+		return dbError(err)
+	}
+	l.conn.Release()
+	l.conn = nil
+	return nil
+}
+
+// drop closes the session instead of returning it to the pool with the lock held
+func (l *AdvisoryLock) drop() {
+	l.conn.Conn().Close(context.Background())
+	l.conn.Release()
+	l.conn = nil
+}
+
+// RedisLease is a leader lock on a Redis key holding the leader's ID. It expires after
+// the TTL unless renewed, so a leader that dies is replaced within one TTL.
+type RedisLease struct {
+	client *redis.Client
+	key    string
+	id     string
+	ttl    time.Duration
+}
+
+func NewRedisLease(opts *redis.Options, name, instanceID string, ttl time.Duration) *RedisLease {
+	return &RedisLease{
+		client: redis.NewClient(opts),
+		key:    "leader:" + name,
+		id:     instanceID,
+		ttl:    ttl,
+	}
+}
+
+// Only the holder may renew or delete the key
+var (
+	renewLease = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+    return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+
+	releaseLease = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+    return redis.call("DEL", KEYS[1])
+end
+return 0`)
+)
+
+func (l *RedisLease) TryAcquire(ctx context.Context) (bool, error) {
+	acquired, err := l.client.SetNX(ctx, l.key, l.id, l.ttl).Result()
+	if err != nil || acquired {
+		return acquired, err
+	}
+	renewed, err := renewLease.Run(ctx, l.client, []string{l.key}, l.id, l.ttl.Milliseconds()).Int()
+	return renewed == 1, err
+}
+
+func (l *RedisLease) Release(ctx context.Context) error {
+	return releaseLease.Run(ctx, l.client, []string{l.key}, l.id).Err()
+}
+
+func (l *RedisLease) Close() error {
+	return l.client.Close()
+}
@@ -0,0 +1,89 @@
+package database
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/derkres11/price-pulse/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type OutboxRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewOutboxRepo(db *pgxpool.Pool) *OutboxRepo {
+	return &OutboxRepo{db: db}
+}
+
+func (r *OutboxRepo) Enqueue(ctx context.Context, m *domain.OutboxMessage) error {
+	query := `
+            INSERT INTO outbox(kind, aggregate_id, request_id, traceparent)
+            VALUES ($1, $2, $3, $4)
+            RETURNING id, available_at, created_at`
+
+	row := conn(ctx, r.db).QueryRow(ctx, query, m.Kind, m.AggregateID, m.RequestID, m.Traceparent)
+	return dbError(row.Scan(&m.ID, &m.AvailableAt, &m.CreatedAt))
+}
+
+func (r *OutboxRepo) ClaimDue(ctx context.Context, limit int, until time.Time) ([]*domain.OutboxMessage, error) {
+	// SKIP LOCKED keeps two relays claiming at once from taking the same messages, the
+	// lock ends with the statement and available_at keeps them off until the claim runs out
+	query := `
+            UPDATE outbox SET available_at = $2
+            WHERE id IN (
+                SELECT id FROM outbox
+                WHERE sent_at IS NULL AND parked_at IS NULL AND available_at <= NOW()
+                ORDER BY id
+                LIMIT $1
+                FOR UPDATE SKIP LOCKED)
+            RETURNING id, kind, aggregate_id, attempts, last_error, request_id, traceparent, available_at, created_at`
+
+	rows, err := r.db.Query(ctx, query, limit, until)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	msgs, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*domain.OutboxMessage, error) {
+		m := &domain.OutboxMessage{}
+		err := row.Scan(&m.ID, &m.Kind, &m.AggregateID, &m.Attempts, &m.LastError, &m.RequestID, &m.Traceparent, &m.AvailableAt, &m.CreatedAt)
+		return m, err
+	})
+	if err != nil {
+		return nil, dbError(err)
+	}
+	// RETURNING keeps no order
+	slices.SortFunc(msgs, func(a, b *domain.OutboxMessage) int { return cmp.Compare(a.ID, b.ID) })
+	return msgs, nil
+}
+
+func (r *OutboxRepo) MarkSent(ctx context.Context, ids []int64) error {
+	_, err := r.db.Exec(ctx, `
+            UPDATE outbox SET attempts = attempts + 1, last_error = '', sent_at = NOW()
+            WHERE id = ANY($1) AND sent_at IS NULL`, ids)
+	return dbError(err)
+}
+
+func (r *OutboxRepo) Retry(ctx context.Context, id int64, lastErr string, at time.Time) error {
+	_, err := r.db.Exec(ctx, `
+            UPDATE outbox SET attempts = attempts + 1, last_error = $2, available_at = $3
+            WHERE id = $1 AND sent_at IS NULL`, id, lastErr, at)
+	return dbError(err)
+}
+
+func (r *OutboxRepo) Park(ctx context.Context, id int64, lastErr string) error {
+	_, err := r.db.Exec(ctx, `
+            UPDATE outbox SET attempts = attempts + 1, last_error = $2, parked_at = NOW()
+            WHERE id = $1 AND sent_at IS NULL`, id, lastErr)
+	return dbError(err)
+}
+
+func (r *OutboxRepo) DeleteSent(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM outbox WHERE sent_at < $1 OR parked_at < $1`, before)
+	if err != nil {
+		return 0, dbError(err)
+	}
+	return tag.RowsAffected(), nil
+}
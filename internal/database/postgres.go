@@ -2,28 +2,31 @@ package database
 
 import (
 	"context"
-	"fmt"
 	"log"
-	"os"
+	"net"
+	"net/url"
+	"strconv"
 	"time"
 
+	"github.com/derkres11/price-pulse/internal/config"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-func NewPostgresPool() *pgxpool.Pool {
-	dsn := fmt.Sprintf("postgresql://%s:%s@%s:%s/%s?sslmode=disable",
-		os.Getenv("POSTGRES_USER"),
-		os.Getenv("POSTGRES_PASSWORD"),
-		os.Getenv("POSTGRES_HOST"),
-		os.Getenv("POSTGRES_PORT"),
-		os.Getenv("POSTGRES_DB"),
-	)
+func NewPostgresPool(cfg config.PostgresConfig) *pgxpool.Pool {
+	poolCfg, err := pgxpool.ParseConfig(postgresDSN(cfg))
+	if err != nil {
+		log.Fatalf("❌ Invalid Postgres configuration: %v", err)
+	}
+	poolCfg.MaxConns = int32(cfg.MaxConns)
+	poolCfg.MinConns = int32(cfg.MinConns)
+	poolCfg.MaxConnLifetime = cfg.MaxConnLifetime
+	poolCfg.MaxConnIdleTime = cfg.MaxConnIdleTime
+	poolCfg.ConnConfig.ConnectTimeout = cfg.ConnectTimeout
 
 	var pool *pgxpool.Pool
-	var err error
 
-	for i := 0; i < 5; i++ {
-		pool, err = pgxpool.New(context.Background(), dsn)
+	for i := 0; i < cfg.ConnectAttempts; i++ {
+		pool, err = pgxpool.NewWithConfig(context.Background(), poolCfg)
 
 		if err == nil {
 			err = pool.Ping(context.Background())
@@ -31,12 +34,31 @@ func NewPostgresPool() *pgxpool.Pool {
 				log.Println("✅ Successfully connected to Postgres")
 				return pool
 			}
+			pool.Close()
 		}
 
 		log.Printf("⚠️  DB connection attempt %d failed: %v. Retrying in 2s...", i+1, err)
 		time.Sleep(2 * time.Second)
 	}
 
-	log.Fatalf("❌ Could not connect to Postgres after 5 attempts: %v", err)
+	log.Fatalf("❌ Could not connect to Postgres after %d attempts: %v", cfg.ConnectAttempts, err)
 	return nil
 }
+
+// postgresDSN escapes the credentials, a password with @ or / must not break the URL
+func postgresDSN(cfg config.PostgresConfig) string {
+	q := url.Values{}
+	q.Set("sslmode", cfg.SSLMode)
+	if cfg.SSLRootCert != "" {
+		q.Set("sslrootcert", cfg.SSLRootCert)
+	}
+
+	u := url.URL{
+		Scheme:   "postgresql",
+		User:     url.UserPassword(cfg.User, cfg.Password),
+		Host:     net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+		Path:     "/" + cfg.Database,
+		RawQuery: q.Encode(),
+	}
+	return u.String()
+}
@@ -2,8 +2,12 @@ package database
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/derkres11/price-pulse/internal/domain"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -15,50 +19,189 @@ func NewProductRepo(db *pgxpool.Pool) *ProductRepo {
 	return &ProductRepo{db: db}
 }
 
+const productColumns = `id, url, title, current_price, target_price, created_at, updated_at, check_interval_seconds, next_check_at`
+
+// scanProduct reads a row of productColumns
+func scanProduct(row pgx.Row) (*domain.Product, error) {
+	var (
+		p        = &domain.Product{}
+		interval *int64
+	)
+	err := row.Scan(&p.ID, &p.URL, &p.Title, &p.CurrentPrice, &p.TargetPrice, &p.CreatedAt, &p.UpdatedAt, &interval, &p.NextCheckAt)
+	if interval != nil {
+		p.CheckInterval = domain.Interval(time.Duration(*interval) * time.Second)
+	}
+	return p, err
+}
+
+// intervalSeconds stores 0 as NULL, the default
+func intervalSeconds(i domain.Interval) *int64 {
+	if i <= 0 {
+		return nil
+	}
+	s := int64(time.Duration(i) / time.Second)
+	return &s
+}
+
 func (r *ProductRepo) Create(ctx context.Context, p *domain.Product) error {
 	query := `
             INSERT INTO products(url, title, current_price, target_price)
             VALUES ($1, $2, $3, $4)
             RETURNING id, created_at, updated_at`
 
-	return r.db.QueryRow(ctx, query, p.URL, p.Title, p.CurrentPrice, p.TargetPrice).Scan(&p.ID, &p.CreatedAt, &p.UpdatedAt)
+	return dbError(conn(ctx, r.db).QueryRow(ctx, query, p.URL, p.Title, p.CurrentPrice, p.TargetPrice).Scan(&p.ID, &p.CreatedAt, &p.UpdatedAt))
 }
 
 func (r *ProductRepo) GetByID(ctx context.Context, id int64) (*domain.Product, error) {
 	query := `
-            SELECT id, url, title, current_price, target_price, created_at, updated_at
+            SELECT ` + productColumns + `
             FROM products
             WHERE id = $1`
 
-	p := &domain.Product{}
-	err := r.db.QueryRow(ctx, query, id).Scan(&p.ID, &p.URL, &p.Title, &p.CurrentPrice, &p.TargetPrice, &p.CreatedAt, &p.UpdatedAt)
+	p, err := scanProduct(conn(ctx, r.db).QueryRow(ctx, query, id))
 	if err != nil {
-		return nil, err
+		return nil, dbError(err)
+	}
+	return p, nil
+}
+
+func (r *ProductRepo) GetForUpdate(ctx context.Context, id int64) (*domain.Product, error) {
+	query := `
+            SELECT ` + productColumns + `
+            FROM products
+            WHERE id = $1
+            FOR UPDATE`
+
+	p, err := scanProduct(conn(ctx, r.db).QueryRow(ctx, query, id))
+	if err != nil {
+		return nil, dbError(err)
+	}
+	return p, nil
+}
+
+func (r *ProductRepo) GetByURL(ctx context.Context, url string) (*domain.Product, error) {
+	query := `
+            SELECT ` + productColumns + `
+            FROM products
+            WHERE url = $1`
+
+	p, err := scanProduct(conn(ctx, r.db).QueryRow(ctx, query, url))
+	if err != nil {
+		return nil, dbError(err)
 	}
 	return p, nil
 }
 
 func (r *ProductRepo) UpdatePrice(ctx context.Context, id int64, newPrice float64) error {
 	query := `UPDATE products SET current_price = $1, updated_at = NOW() WHERE id = $2`
-	_, err := r.db.Exec(ctx, query, newPrice, id)
-	return err
+	_, err := conn(ctx, r.db).Exec(ctx, query, newPrice, id)
+	return dbError(err)
+}
+
+func (r *ProductRepo) UpdateTitle(ctx context.Context, id int64, title string) error {
+	query := `UPDATE products SET title = $1, updated_at = NOW() WHERE id = $2`
+	_, err := conn(ctx, r.db).Exec(ctx, query, title, id)
+	return dbError(err)
 }
 
-func (r *ProductRepo) GetAll(ctx context.Context) ([]*domain.Product, error) {
-	query := `SELECT id, url, title, current_price, target_price, created_at, updated_at FROM products`
-	rows, err := r.db.Query(ctx, query)
+func (r *ProductRepo) Update(ctx context.Context, p *domain.Product) error {
+	query := `
+            UPDATE products
+            SET title = $1, target_price = $2, check_interval_seconds = $3, updated_at = NOW()
+            WHERE id = $4
+            RETURNING updated_at`
+
+	row := conn(ctx, r.db).QueryRow(ctx, query, p.Title, p.TargetPrice, intervalSeconds(p.CheckInterval), p.ID)
+	return dbError(row.Scan(&p.UpdatedAt))
+}
+
+func (r *ProductRepo) Delete(ctx context.Context, id int64) error {
+	tag, err := conn(ctx, r.db).Exec(ctx, `DELETE FROM products WHERE id = $1`, id)
+	if err != nil {
+		return dbError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// sortColumns maps a sort key to its column and the type its cursor value is cast to
+var sortColumns = map[domain.ProductSort]struct{ expr, cast string }{
+	domain.SortByID:      {"id", "bigint"},
+	domain.SortByCreated: {"created_at", "timestamptz"},
+	domain.SortByUpdated: {"updated_at", "timestamptz"},
+	domain.SortByPrice:   {"COALESCE(current_price, 0)", "numeric"},
+	domain.SortByTitle:   {"title", "text"},
+}
+
+func (r *ProductRepo) List(ctx context.Context, q domain.ProductQuery) ([]*domain.Product, error) {
+	col, ok := sortColumns[q.Sort]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown sort %q", domain.ErrInvalidArgument, q.Sort)
+	}
+
+	var (
+		where []string
+		args  []any
+	)
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if q.Host != "" {
+		p := arg(strings.ToLower(q.Host))
+		where = append(where, fmt.Sprintf("host IN (%s, 'www.' || %s)", p, p))
+	}
+	if q.MinPrice > 0 {
+		where = append(where, "current_price >= "+arg(q.MinPrice))
+	}
+	if q.MaxPrice > 0 {
+		where = append(where, "current_price <= "+arg(q.MaxPrice))
+	}
+	if q.BelowTarget {
+		where = append(where, "target_price > 0 AND current_price > 0 AND current_price <= target_price")
+	}
+	if !q.UpdatedSince.IsZero() {
+		where = append(where, "updated_at >= "+arg(q.UpdatedSince))
+	}
+	if q.Search != "" {
+		where = append(where, "to_tsvector('simple', title) @@ plainto_tsquery('simple', "+arg(q.Search)+")")
+	}
+
+	op, dir := ">", "ASC"
+	if q.Desc {
+		op, dir = "<", "DESC"
+	}
+	if q.After != nil {
+		where = append(where, fmt.Sprintf("(%s, id) %s (%s::%s, %s)", col.expr, op, arg(q.After.Value), col.cast, arg(q.After.ID)))
+	}
+
+	query := `
+            SELECT ` + productColumns + `
+            FROM products`
+	if len(where) > 0 {
+		query += `
+            WHERE ` + strings.Join(where, " AND ")
+	}
+	query += fmt.Sprintf(`
+            ORDER BY %s %s, id %s
+            LIMIT %s`, col.expr, dir, dir, arg(q.Limit))
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return nil, dbError(err)
 	}
 	defer rows.Close()
 
 	var products []*domain.Product
 	for rows.Next() {
-		p := &domain.Product{}
-		if err := rows.Scan(&p.ID, &p.URL, &p.Title, &p.CurrentPrice, &p.TargetPrice, &p.CreatedAt, &p.UpdatedAt); err != nil {
-			return nil, err
+		p, err := scanProduct(rows)
+		if err != nil {
+			return nil, dbError(err)
 		}
 		products = append(products, p)
 	}
-	return products, nil
+	return products, dbError(rows.Err())
 }
@@ -0,0 +1,147 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/derkres11/price-pulse/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PriceHistoryRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewPriceHistoryRepo(db *pgxpool.Pool) *PriceHistoryRepo {
+	return &PriceHistoryRepo{db: db}
+}
+
+func (r *PriceHistoryRepo) AddPoint(ctx context.Context, p *domain.PricePoint) error {
+	query := `
+            INSERT INTO price_history(product_id, price, currency, in_stock, source)
+            VALUES ($1, $2, $3, $4, $5)
+            RETURNING observed_at`
+
+	return dbError(r.db.QueryRow(ctx, query, p.ProductID, p.Price, p.Currency, p.InStock, p.Source).Scan(&p.ObservedAt))
+}
+
+func (r *PriceHistoryRepo) GetRange(ctx context.Context, productID int64, from, to time.Time) ([]*domain.PricePoint, error) {
+	query := `
+            SELECT product_id, price, currency, in_stock, source, observed_at
+            FROM price_history
+            WHERE product_id = $1 AND observed_at >= $2 AND observed_at < $3
+            ORDER BY observed_at`
+
+	rows, err := r.db.Query(ctx, query, productID, from, to)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+
+	var points []*domain.PricePoint
+	for rows.Next() {
+		p := &domain.PricePoint{}
+		if err := rows.Scan(&p.ProductID, &p.Price, &p.Currency, &p.InStock, &p.Source, &p.ObservedAt); err != nil {
+			return nil, dbError(err)
+		}
+		points = append(points, p)
+	}
+	return points, dbError(rows.Err())
+}
+
+// GetBuckets groups observations into fixed steps aligned to from.
+// Out of stock rows carry no real price, so they are left out of the aggregates.
+func (r *PriceHistoryRepo) GetBuckets(ctx context.Context, productID int64, from, to time.Time, step time.Duration) ([]*domain.PriceBucket, error) {
+	query := `
+            SELECT date_bin(make_interval(secs => $4), observed_at, $2) AS bucket,
+                   MIN(price), MAX(price), AVG(price)::float8,
+                   (ARRAY_AGG(price ORDER BY observed_at DESC))[1],
+                   COUNT(*)
+            FROM price_history
+            WHERE product_id = $1 AND observed_at >= $2 AND observed_at < $3
+              AND in_stock AND price > 0
+            GROUP BY bucket
+            ORDER BY bucket`
+
+	rows, err := r.db.Query(ctx, query, productID, from, to, step.Seconds())
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+
+	var buckets []*domain.PriceBucket
+	for rows.Next() {
+		b := &domain.PriceBucket{}
+		if err := rows.Scan(&b.Start, &b.Min, &b.Max, &b.Avg, &b.Last, &b.Count); err != nil {
+			return nil, dbError(err)
+		}
+		buckets = append(buckets, b)
+	}
+	return buckets, dbError(rows.Err())
+}
+
+// GetLatest returns the most recent observation, or nil if the product was never checked
+func (r *PriceHistoryRepo) GetLatest(ctx context.Context, productID int64) (*domain.PricePoint, error) {
+	query := `
+            SELECT product_id, price, currency, in_stock, source, observed_at
+            FROM price_history
+            WHERE product_id = $1
+            ORDER BY observed_at DESC
+            LIMIT 1`
+
+	p := &domain.PricePoint{}
+	err := r.db.QueryRow(ctx, query, productID).Scan(&p.ProductID, &p.Price, &p.Currency, &p.InStock, &p.Source, &p.ObservedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, dbError(err)
+	}
+	return p, nil
+}
+
+// GetUnchangedSince returns when the current run of in-stock observations at price started:
+// the first one after the latest observation at another price or out of stock. It is
+// zero when the latest observation already breaks the run or there is none.
+func (r *PriceHistoryRepo) GetUnchangedSince(ctx context.Context, productID int64, price float64) (time.Time, error) {
+	query := `
+            SELECT MIN(h.observed_at)
+            FROM price_history h
+            WHERE h.product_id = $1 AND h.in_stock AND h.price = $2
+              AND h.observed_at > COALESCE((
+                  SELECT MAX(b.observed_at)
+                  FROM price_history b
+                  WHERE b.product_id = $1 AND (b.price <> $2 OR NOT b.in_stock)
+              ), '-infinity')`
+
+	var since *time.Time
+	if err := r.db.QueryRow(ctx, query, productID, price).Scan(&since); err != nil {
+		return time.Time{}, dbError(err)
+	}
+	if since == nil {
+		return time.Time{}, nil
+	}
+	return *since, nil
+}
+
+// GetLowest returns the cheapest in-stock observation ever, or nil if there is none yet
+func (r *PriceHistoryRepo) GetLowest(ctx context.Context, productID int64) (*domain.PricePoint, error) {
+	query := `
+            SELECT product_id, price, currency, in_stock, source, observed_at
+            FROM price_history
+            WHERE product_id = $1 AND in_stock AND price > 0
+            ORDER BY price, observed_at
+            LIMIT 1`
+
+	p := &domain.PricePoint{}
+	err := r.db.QueryRow(ctx, query, productID).Scan(&p.ProductID, &p.Price, &p.Currency, &p.InStock, &p.Source, &p.ObservedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, dbError(err)
+	}
+	return p, nil
+}
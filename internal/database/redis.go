@@ -6,19 +6,35 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/derkres11/price-pulse/internal/config"
 	"github.com/derkres11/price-pulse/internal/domain"
 	"github.com/redis/go-redis/v9"
 )
 
+// RedisOptions turns the configuration into client options, loading the TLS files
+func RedisOptions(cfg config.RedisConfig) (*redis.Options, error) {
+	tlsCfg, err := cfg.TLS.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load redis tls: %w", err)
+	}
+	return &redis.Options{
+		Addr:        cfg.Addr,
+		Username:    cfg.Username,
+		Password:    cfg.Password,
+		DB:          cfg.DB,
+		PoolSize:    cfg.PoolSize,
+		DialTimeout: cfg.DialTimeout,
+		TLSConfig:   tlsCfg,
+	}, nil
+}
+
 type Cache struct {
 	client *redis.Client
 }
 
-func NewCache(addr string) *Cache {
+func NewCache(opts *redis.Options) *Cache {
 	return &Cache{
-		client: redis.NewClient(&redis.Options{
-			Addr: addr,
-		}),
+		client: redis.NewClient(opts),
 	}
 }
 
@@ -0,0 +1,151 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/derkres11/price-pulse/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ScheduleRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewScheduleRepo(db *pgxpool.Pool) *ScheduleRepo {
+	return &ScheduleRepo{db: db}
+}
+
+// dueCheckQuery selects the rows scanned by scanDueCheck, the caller adds WHERE and the rest
+const dueCheckQuery = `
+            SELECT p.id, COALESCE(p.host, ''),
+                   COALESCE(p.check_interval_seconds, h.check_interval_seconds, 0),
+                   CASE WHEN p.check_interval_seconds IS NOT NULL THEN 'product'
+                        WHEN h.check_interval_seconds IS NOT NULL THEN 'host'
+                        ELSE 'default' END,
+                   p.next_check_at IS NULL
+            FROM products p
+            LEFT JOIN host_check_intervals h ON h.host IN (p.host, substring(p.host from '^www\.(.+)$'))`
+
+func scanDueCheck(row pgx.Row) (*domain.DueCheck, error) {
+	var (
+		d       = &domain.DueCheck{}
+		seconds int64
+	)
+	err := row.Scan(&d.ProductID, &d.Host, &seconds, &d.Source, &d.FirstQueued)
+	d.Interval = time.Duration(seconds) * time.Second
+	return d, err
+}
+
+func (r *ScheduleRepo) LockDue(ctx context.Context, limit int) ([]*domain.DueCheck, error) {
+	// SKIP LOCKED lets several schedulers run without queueing a product twice
+	query := dueCheckQuery + `
+            WHERE p.next_check_at IS NULL OR p.next_check_at <= NOW()
+            ORDER BY p.next_check_at NULLS FIRST, p.id
+            LIMIT $1
+            FOR UPDATE OF p SKIP LOCKED`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, limit)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	due, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*domain.DueCheck, error) {
+		return scanDueCheck(row)
+	})
+	return due, dbError(err)
+}
+
+func (r *ScheduleRepo) GetCheck(ctx context.Context, productID int64) (*domain.DueCheck, error) {
+	d, err := scanDueCheck(conn(ctx, r.db).QueryRow(ctx, dueCheckQuery+` WHERE p.id = $1`, productID))
+	if err != nil {
+		return nil, dbError(err)
+	}
+	return d, nil
+}
+
+func (r *ScheduleRepo) Signals(ctx context.Context, productIDs []int64, since time.Time) (map[int64]*domain.CheckSignals, error) {
+	// A change is an observation whose price differs from the one before it, the first
+	// observation in the window is compared with nothing and never counts
+	query := `
+            SELECT p.id, COALESCE(p.current_price, 0),
+                   COALESCE(LEAST(NULLIF(p.target_price, 0), s.target), 0),
+                   COALESCE(s.subscribers, 0),
+                   COALESCE(o.observations, 0), COALESCE(o.changes, 0)
+            FROM products p
+            LEFT JOIN LATERAL (
+                SELECT COUNT(*) AS subscribers, MIN(target_price) FILTER (WHERE target_price > 0) AS target
+                FROM subscriptions WHERE product_id = p.id
+            ) s ON TRUE
+            LEFT JOIN LATERAL (
+                SELECT COUNT(*) AS observations, COUNT(*) FILTER (WHERE price <> previous) AS changes
+                FROM (
+                    SELECT price, LAG(price) OVER (ORDER BY observed_at) AS previous
+                    FROM price_history
+                    WHERE product_id = p.id AND observed_at >= $2
+                ) w
+            ) o ON TRUE
+            WHERE p.id = ANY($1)`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, productIDs, since)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+
+	signals := make(map[int64]*domain.CheckSignals, len(productIDs))
+	for rows.Next() {
+		var (
+			id int64
+			s  = &domain.CheckSignals{}
+		)
+		if err := rows.Scan(&id, &s.Price, &s.Target, &s.Subscribers, &s.Observations, &s.Changes); err != nil {
+			return nil, dbError(err)
+		}
+		signals[id] = s
+	}
+	return signals, dbError(rows.Err())
+}
+
+func (r *ScheduleRepo) Reschedule(ctx context.Context, productID int64, next time.Time) error {
+	_, err := conn(ctx, r.db).Exec(ctx, `UPDATE products SET next_check_at = $1 WHERE id = $2`, next, productID)
+	return dbError(err)
+}
+
+func (r *ScheduleRepo) SetHostInterval(ctx context.Context, h *domain.HostInterval) error {
+	query := `
+            INSERT INTO host_check_intervals(host, check_interval_seconds)
+            VALUES ($1, $2)
+            ON CONFLICT (host) DO UPDATE SET check_interval_seconds = EXCLUDED.check_interval_seconds, updated_at = NOW()
+            RETURNING updated_at`
+
+	return dbError(conn(ctx, r.db).QueryRow(ctx, query, h.Host, intervalSeconds(h.Interval)).Scan(&h.UpdatedAt))
+}
+
+func (r *ScheduleRepo) DeleteHostInterval(ctx context.Context, host string) error {
+	tag, err := conn(ctx, r.db).Exec(ctx, `DELETE FROM host_check_intervals WHERE host = $1`, host)
+	if err != nil {
+		return dbError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *ScheduleRepo) ListHostIntervals(ctx context.Context) ([]*domain.HostInterval, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, `SELECT host, check_interval_seconds, updated_at FROM host_check_intervals ORDER BY host`)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	intervals, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*domain.HostInterval, error) {
+		var (
+			h       = &domain.HostInterval{}
+			seconds int64
+		)
+		err := row.Scan(&h.Host, &seconds, &h.UpdatedAt)
+		h.Interval = domain.Interval(time.Duration(seconds) * time.Second)
+		return h, err
+	})
+	return intervals, dbError(err)
+}
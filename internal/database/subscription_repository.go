@@ -0,0 +1,110 @@
+package database
+
+import (
+	"context"
+
+	"github.com/derkres11/price-pulse/internal/domain"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type SubscriptionRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewSubscriptionRepo(db *pgxpool.Pool) *SubscriptionRepo {
+	return &SubscriptionRepo{db: db}
+}
+
+func (r *SubscriptionRepo) Upsert(ctx context.Context, s *domain.Subscription) error {
+	query := `
+            INSERT INTO subscriptions(user_id, product_id, target_price)
+            VALUES ($1, $2, $3)
+            ON CONFLICT (user_id, product_id)
+            DO UPDATE SET target_price = EXCLUDED.target_price, updated_at = NOW()
+            RETURNING id, cooldown_minutes, rearm, created_at, updated_at`
+
+	return dbError(conn(ctx, r.db).QueryRow(ctx, query, s.UserID, s.ProductID, s.TargetPrice).
+		Scan(&s.ID, &s.CooldownMinutes, &s.Rearm, &s.CreatedAt, &s.UpdatedAt))
+}
+
+func (r *SubscriptionRepo) GetByID(ctx context.Context, userID, id int64) (*domain.Subscription, error) {
+	subs, err := r.list(ctx, `WHERE s.id = $1 AND s.user_id = $2`, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(subs) == 0 {
+		return nil, domain.ErrNotFound
+	}
+	return subs[0], nil
+}
+
+func (r *SubscriptionRepo) ListByUser(ctx context.Context, userID int64) ([]*domain.Subscription, error) {
+	return r.list(ctx, `WHERE s.user_id = $1`, userID)
+}
+
+func (r *SubscriptionRepo) ListByProduct(ctx context.Context, productID int64) ([]*domain.Subscription, error) {
+	return r.list(ctx, `WHERE s.product_id = $1`, productID)
+}
+
+func (r *SubscriptionRepo) UpdateTarget(ctx context.Context, userID, id int64, targetPrice float64) error {
+	query := `UPDATE subscriptions SET target_price = $1, updated_at = NOW() WHERE id = $2 AND user_id = $3`
+	tag, err := conn(ctx, r.db).Exec(ctx, query, targetPrice, id, userID)
+	if err != nil {
+		return dbError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *SubscriptionRepo) UpdatePolicy(ctx context.Context, userID, id int64, cooldownMinutes int, rearm bool) error {
+	query := `UPDATE subscriptions SET cooldown_minutes = $1, rearm = $2, updated_at = NOW() WHERE id = $3 AND user_id = $4`
+	tag, err := conn(ctx, r.db).Exec(ctx, query, cooldownMinutes, rearm, id, userID)
+	if err != nil {
+		return dbError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *SubscriptionRepo) Delete(ctx context.Context, userID, id int64) error {
+	tag, err := conn(ctx, r.db).Exec(ctx, `DELETE FROM subscriptions WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return dbError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// list joins the product so a watchlist renders without one query per row
+func (r *SubscriptionRepo) list(ctx context.Context, where string, args ...any) ([]*domain.Subscription, error) {
+	query := `
+            SELECT s.id, s.user_id, s.product_id, s.target_price, s.cooldown_minutes, s.rearm, s.created_at, s.updated_at,
+                p.id, p.url, p.title, p.current_price, p.target_price, p.created_at, p.updated_at
+            FROM subscriptions s
+            JOIN products p ON p.id = s.product_id ` + where + `
+            ORDER BY s.id`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, args...)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+
+	var subs []*domain.Subscription
+	for rows.Next() {
+		s := &domain.Subscription{Product: &domain.Product{}}
+		p := s.Product
+		if err := rows.Scan(&s.ID, &s.UserID, &s.ProductID, &s.TargetPrice, &s.CooldownMinutes, &s.Rearm, &s.CreatedAt, &s.UpdatedAt,
+			&p.ID, &p.URL, &p.Title, &p.CurrentPrice, &p.TargetPrice, &p.CreatedAt, &p.UpdatedAt); err != nil {
+			return nil, dbError(err)
+		}
+		subs = append(subs, s)
+	}
+	return subs, dbError(rows.Err())
+}
@@ -0,0 +1,57 @@
+package database
+
+import (
+	"context"
+
+	"github.com/derkres11/price-pulse/internal/domain"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type UserRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewUserRepo(db *pgxpool.Pool) *UserRepo {
+	return &UserRepo{db: db}
+}
+
+func (r *UserRepo) Create(ctx context.Context, u *domain.User) error {
+	query := `
+            INSERT INTO users(email, name)
+            VALUES ($1, $2)
+            RETURNING id, created_at, quiet_start, quiet_end, timezone`
+
+	return dbError(conn(ctx, r.db).QueryRow(ctx, query, u.Email, u.Name).
+		Scan(&u.ID, &u.CreatedAt, &u.QuietStart, &u.QuietEnd, &u.Timezone))
+}
+
+func (r *UserRepo) GetByID(ctx context.Context, id int64) (*domain.User, error) {
+	return r.get(ctx, `WHERE id = $1`, id)
+}
+
+func (r *UserRepo) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	return r.get(ctx, `WHERE email = $1`, email)
+}
+
+func (r *UserRepo) get(ctx context.Context, where string, args ...any) (*domain.User, error) {
+	query := `SELECT id, email, name, created_at, quiet_start, quiet_end, timezone FROM users ` + where
+
+	u := &domain.User{}
+	if err := conn(ctx, r.db).QueryRow(ctx, query, args...).
+		Scan(&u.ID, &u.Email, &u.Name, &u.CreatedAt, &u.QuietStart, &u.QuietEnd, &u.Timezone); err != nil {
+		return nil, dbError(err)
+	}
+	return u, nil
+}
+
+func (r *UserRepo) UpdateQuietHours(ctx context.Context, id int64, q domain.QuietHours) error {
+	query := `UPDATE users SET quiet_start = $1, quiet_end = $2, timezone = $3 WHERE id = $4`
+	tag, err := r.db.Exec(ctx, query, q.QuietStart, q.QuietEnd, q.Timezone, id)
+	if err != nil {
+		return dbError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type AlertRuleType string
+
+const (
+	AlertThreshold      AlertRuleType = "threshold"       // price at or below Threshold
+	AlertPercentDrop    AlertRuleType = "percent_drop"    // price Percent% or more below Baseline
+	AlertAllTimeLow     AlertRuleType = "all_time_low"    // cheaper than ever observed before
+	AlertPriceIncrease  AlertRuleType = "price_increase"  // price went up by at least Percent% since the last check
+	AlertBackInStock    AlertRuleType = "back_in_stock"   // previous check was out of stock, this one is not
+	AlertPriceUnchanged AlertRuleType = "price_unchanged" // same price for at least Days days
+)
+
+// AlertRule is a condition a product is watched for.
+// Only the fields relevant to Type are used. Rules with a SubscriptionID
+// only notify the subscription's owner.
+type AlertRule struct {
+	ID             int64         `json:"id"`
+	ProductID      int64         `json:"product_id"`
+	SubscriptionID int64         `json:"subscription_id,omitempty"`
+	Type           AlertRuleType `json:"type"`
+	Threshold      float64       `json:"threshold,omitempty"`
+	Percent        float64       `json:"percent,omitempty"`
+	Baseline       float64       `json:"baseline,omitempty"`
+	Days           int           `json:"days,omitempty"`
+	Enabled        bool          `json:"enabled"`
+	CreatedAt      time.Time     `json:"created_at"`
+}
+
+// AlertEvent is produced every time a rule matches an observation.
+// RuleID is 0 for the implicit rules built from a target price.
+// UserID is set for subscription events and picks the channels they go to.
+type AlertEvent struct {
+	ID             int64         `json:"id"`
+	RuleID         int64         `json:"rule_id,omitempty"`
+	ProductID      int64         `json:"product_id"`
+	SubscriptionID int64         `json:"subscription_id,omitempty"`
+	UserID         int64         `json:"user_id,omitempty"`
+	Type           AlertRuleType `json:"type"`
+	Price          float64       `json:"price"`
+	PreviousPrice  float64       `json:"previous_price,omitempty"`
+	Reason         string        `json:"reason"`
+	CreatedAt      time.Time     `json:"created_at"`
+}
+
+// AlertRepository defines the behavior for storing alert rules and the events they produce
+type AlertRepository interface {
+	CreateRule(ctx context.Context, r *AlertRule) error
+	// ListRules returns every rule of the product, subscription rules included
+	ListRules(ctx context.Context, productID int64) ([]*AlertRule, error)
+	ListSubscriptionRules(ctx context.Context, subscriptionID int64) ([]*AlertRule, error)
+	DeleteRule(ctx context.Context, productID, ruleID int64) error
+	DeleteSubscriptionRule(ctx context.Context, subscriptionID, ruleID int64) error
+	SaveEvent(ctx context.Context, e *AlertEvent) error
+	// ListEvents returns the events of the product's own rules and of userID's subscription
+	ListEvents(ctx context.Context, productID, userID int64, limit int) ([]*AlertEvent, error)
+	// RearmRules marks every rule of the product except the matched ones as cleared,
+	// so subscriptions using hysteresis may alert on them again. Matches are told apart
+	// by rule and subscription, since every subscription has its own implicit target rule.
+	RearmRules(ctx context.Context, productID int64, matched []*AlertEvent) error
+}
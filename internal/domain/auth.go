@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type AuthMethod string
+
+const (
+	AuthAPIKey AuthMethod = "api_key"
+	AuthJWT    AuthMethod = "jwt"
+)
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	UserID int64
+	Admin  bool
+	Method AuthMethod
+	KeyID  int64 // set for API keys
+}
+
+// APIKey is a long lived credential. The secret is only shown once, at creation.
+type APIKey struct {
+	ID         int64      `json:"id"`
+	UserID     int64      `json:"user_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Hash       string     `json:"-"`
+	Admin      bool       `json:"admin"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// APIKeyRepository defines the behavior for storing API keys
+type APIKeyRepository interface {
+	Create(ctx context.Context, k *APIKey) error
+	GetByPrefix(ctx context.Context, prefix string) (*APIKey, error)
+	ListByUser(ctx context.Context, userID int64) ([]*APIKey, error)
+	Revoke(ctx context.Context, userID, id int64) error
+	Touch(ctx context.Context, id int64) error
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a context carrying the authenticated caller
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext returns the authenticated caller, nil for anonymous calls
+func PrincipalFromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+	return p
+}
+
+// UserIDFromContext returns the calling user, false for anonymous calls
+func UserIDFromContext(ctx context.Context) (int64, bool) {
+	p := PrincipalFromContext(ctx)
+	if p == nil || p.UserID <= 0 {
+		return 0, false
+	}
+	return p.UserID, true
+}
@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// DeadLetter is a message that kept failing and was parked on the dead-letter topic.
+// Partition and Offset locate it there, Topic is where it came from.
+type DeadLetter struct {
+	Partition int       `json:"partition"`
+	Offset    int64     `json:"offset"`
+	Topic     string    `json:"topic"`
+	Key       string    `json:"key,omitempty"`
+	Value     string    `json:"value"`
+	Error     string    `json:"error"`
+	Attempts  int       `json:"attempts"`
+	FailedAt  time.Time `json:"failed_at"`
+}
+
+// DeadLetterQueue defines the behavior for inspecting and replaying dead letters
+type DeadLetterQueue interface {
+	// List returns up to limit of the newest dead letters of every partition
+	List(ctx context.Context, limit int) ([]*DeadLetter, error)
+	// Replay sends the dead letter back to its topic. It stays on the dead-letter topic.
+	Replay(ctx context.Context, partition int, offset int64) error
+}
@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"errors"
+	"slices"
+)
+
+// Error kinds. Repositories translate driver errors into them and the more specific
+// errors of other packages wrap them, so the transports pick a status from the kind alone.
+var (
+	ErrNotFound         = errors.New("not found")
+	ErrAlreadyExists    = errors.New("already exists")
+	ErrInvalidArgument  = errors.New("invalid argument")
+	ErrPermissionDenied = errors.New("permission denied")
+	ErrUnavailable      = errors.New("unavailable")
+)
+
+// NewError returns an error with its own message that still matches kind with errors.Is
+func NewError(kind error, msg string) error {
+	return &kindError{kind: kind, msg: msg}
+}
+
+type kindError struct {
+	kind error
+	msg  string
+}
+
+func (e *kindError) Error() string {
+	return e.msg
+}
+
+func (e *kindError) Unwrap() error {
+	return e.kind
+}
+
+// PublicMessage is what a client may see of err, an error of kind ErrInvalidArgument or
+// ErrPermissionDenied. Errors built on NewError word their own message for clients and
+// keep it with the context added around them. Anything else, e.g. a driver error the
+// repositories translated, is reduced to its kind, the details belong in the logs.
+func PublicMessage(err error) string {
+	var k *kindError
+	if errors.As(err, &k) {
+		return err.Error()
+	}
+	kinds := []error{ErrNotFound, ErrAlreadyExists, ErrInvalidArgument, ErrPermissionDenied, ErrUnavailable}
+	if i := slices.IndexFunc(kinds, func(kind error) bool { return errors.Is(err, kind) }); i >= 0 {
+		return kinds[i].Error()
+	}
+	return "request failed"
+}
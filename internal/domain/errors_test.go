@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestPublicMessage(t *testing.T) {
+	invalidUser := NewError(ErrInvalidArgument, "invalid user")
+	driver := errors.New(`ERROR: value "-1" violates check constraint "products_price_check" (SQLSTATE 23514)`)
+
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"Own message", fmt.Errorf("%w: email must be an email address", invalidUser), "invalid user: email must be an email address"},
+		{"Context around it", fmt.Errorf("error creating user: %w", invalidUser), "error creating user: invalid user"},
+		{"Translated driver error", fmt.Errorf("error updating product: %w", fmt.Errorf("%w: %w", ErrInvalidArgument, driver)), "invalid argument"},
+		{"Bare kind", ErrPermissionDenied, "permission denied"},
+		{"No kind", driver, "request failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PublicMessage(tt.err); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// PriceInfo is a single observation scraped from a product page
+type PriceInfo struct {
+	Price    float64 `json:"price"`
+	Currency string  `json:"currency"`
+	Title    string  `json:"title"`
+	InStock  bool    `json:"in_stock"`
+	Source   string  `json:"source"` // which extractor produced the observation
+}
+
+// Errors of a fetch that was not attempted out of politeness. The check is skipped,
+// the scheduler tries again at the next one.
+var (
+	ErrFetchDisallowed = NewError(ErrPermissionDenied, "fetching is disallowed by robots.txt")
+	ErrHostThrottled   = NewError(ErrUnavailable, "host is throttled")
+	ErrCircuitOpen     = NewError(ErrUnavailable, "circuit of host is open")
+)
+
+// PriceFetcher defines the behavior for loading the current price of a product page.
+// The HTTP implementation lives in internal/fetcher, tests can swap in a fake.
+type PriceFetcher interface {
+	Fetch(ctx context.Context, url string) (*PriceInfo, error)
+}
+
+// HostLimiter paces the requests to each host, across every replica
+type HostLimiter interface {
+	// Acquire waits for a request to host, one per interval on average, and a free
+	// concurrency slot. release frees the slot. It returns an error matching
+	// ErrHostThrottled right away when the wait would outlast ctx.
+	Acquire(ctx context.Context, host string, interval time.Duration) (release func(), err error)
+	// Block holds back every request to host for d, e.g. after a 429
+	Block(ctx context.Context, host string, d time.Duration) error
+}
+
+// CircuitState is the state of a host's circuit breaker
+type CircuitState string
+
+const (
+	CircuitClosed   CircuitState = "closed"    // requests go through
+	CircuitOpen     CircuitState = "open"      // the host failed, requests fail fast
+	CircuitHalfOpen CircuitState = "half_open" // a few trial requests decide
+)
+
+// HostCircuit is the circuit breaker of one host
+type HostCircuit struct {
+	Host     string       `json:"host"`
+	State    CircuitState `json:"state"`
+	Failures int          `json:"failures"` // in a row
+	OpenedAt *time.Time   `json:"opened_at,omitempty"`
+	RetryAt  *time.Time   `json:"retry_at,omitempty"` // when an open circuit lets a trial through
+}
+
+// HostCircuits tells which hosts are failing
+type HostCircuits interface {
+	// RetryAt returns when host may be tried again if its circuit is open
+	RetryAt(ctx context.Context, host string) (time.Time, bool, error)
+	Circuits(ctx context.Context) ([]*HostCircuit, error)
+}
+
+// Circuit is the stored state of a host's circuit breaker
+type Circuit struct {
+	Host       string
+	State      CircuitState
+	Generation int64 // bumped by every transition
+	Failures   int   // in a row
+	OpenedAt   time.Time
+	ChangedAt  time.Time // of the last transition
+	Trials     int       // in flight while half-open
+	Succeeded  int       // trials that succeeded while half-open
+}
+
+// CircuitStore keeps the circuit of every host where all replicas see it
+type CircuitStore interface {
+	// Get returns the circuit of host, a closed one without failures if it has none
+	Get(ctx context.Context, host string) (*Circuit, error)
+	// Update applies fn to the circuit of host and stores the result unless fn fails,
+	// whose error it returns. fn runs again if another replica changed the circuit meanwhile.
+	Update(ctx context.Context, host string, fn func(c *Circuit) error) error
+	// List returns the circuits that are not closed or have failures
+	List(ctx context.Context) ([]*Circuit, error)
+}
+
+// ExtractionRules defines the behavior for managing per-host scraping rules at runtime
+type ExtractionRules interface {
+	Reload() error
+	Hosts() []string
+}
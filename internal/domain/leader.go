@@ -0,0 +1,13 @@
+package domain
+
+import "context"
+
+// LeaderLock is what replicas compete for to run the periodic jobs. At most one holds
+// it at a time. Implementations are used from a single goroutine.
+type LeaderLock interface {
+	// TryAcquire takes the lock, or confirms and renews it when already held. It reports
+	// false when another replica holds it.
+	TryAcquire(ctx context.Context) (bool, error)
+	// Release gives the lock up so another replica can take over right away
+	Release(ctx context.Context) error
+}
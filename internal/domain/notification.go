@@ -0,0 +1,110 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type ChannelType string
+
+const (
+	ChannelWebhook  ChannelType = "webhook"
+	ChannelEmail    ChannelType = "email"
+	ChannelTelegram ChannelType = "telegram"
+	ChannelSlack    ChannelType = "slack"
+)
+
+// NotificationChannel is a destination for alerts.
+// Target depends on Type: webhook URL, email address, Telegram chat id or Slack channel.
+type NotificationChannel struct {
+	ID        int64       `json:"id"`
+	UserID    int64       `json:"user_id,omitempty"` // 0 for operator channels, which get product level alerts
+	Name      string      `json:"name"`
+	Type      ChannelType `json:"type"`
+	Target    string      `json:"target"`
+	Secret    string      `json:"-"`        // HMAC key for webhooks, never returned by the API
+	Template  string      `json:"template"` // text/template rendered with a Notification, empty means the default
+	Enabled   bool        `json:"enabled"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// QuietHours hold alerts back from QuietStart to QuietEnd ("22:00" to "07:30") in
+// Timezone, they are sent as one digest afterwards
+type QuietHours struct {
+	QuietStart string `json:"quiet_start,omitempty"`
+	QuietEnd   string `json:"quiet_end,omitempty"`
+	Timezone   string `json:"timezone"`
+}
+
+// AlertPolicy is how a subscription alerts: no repeat of the same alert within the
+// cooldown, with Rearm a rule only alerts again once its condition has cleared.
+// The quiet hours are the subscriber's.
+type AlertPolicy struct {
+	CooldownMinutes int  `json:"cooldown_minutes"`
+	Rearm           bool `json:"rearm"`
+	QuietHours
+}
+
+// Notification is what travels over the notifications topic.
+// It carries a product snapshot so channels can render it without hitting the database.
+type Notification struct {
+	Event    *AlertEvent     `json:"event,omitempty"`
+	Title    string          `json:"title"`
+	URL      string          `json:"url"`
+	Currency string          `json:"currency"`
+	Digest   []*Notification `json:"digest,omitempty"` // set instead of Event for quiet hours digests
+}
+
+// AlertState remembers what a subscriber was last told about a product rule
+type AlertState struct {
+	SubscriptionID int64     `json:"subscription_id"` // 0 for product level alerts
+	ProductID      int64     `json:"product_id"`
+	RuleID         int64     `json:"rule_id"`
+	LastPrice      float64   `json:"last_price"`
+	LastSentAt     time.Time `json:"last_sent_at"`
+	Armed          bool      `json:"armed"` // false until the rule condition clears after an alert
+	Version        int64     `json:"version"`
+	ClaimedUntil   time.Time `json:"claimed_until"` // a replica is delivering the alert until then
+}
+
+// Notifier delivers an already rendered message to one channel
+type Notifier interface {
+	Send(ctx context.Context, ch *NotificationChannel, text string, n *Notification) error
+}
+
+// NotificationProducer defines the behavior for queueing notifications for async delivery
+type NotificationProducer interface {
+	SendNotification(ctx context.Context, n *Notification) error
+}
+
+// ChannelRepository defines the behavior for storing notification channels
+type ChannelRepository interface {
+	Create(ctx context.Context, ch *NotificationChannel) error
+	GetEnabled(ctx context.Context) ([]*NotificationChannel, error)
+	// ListByUser and Delete treat user 0 as the operator channels
+	ListByUser(ctx context.Context, userID int64) ([]*NotificationChannel, error)
+	Delete(ctx context.Context, userID, id int64) error
+}
+
+// AlertStateRepository defines the behavior for the per subscription delivery state and
+// the per user digest queue. Subscription and user 0 stand for product level alerts.
+// Nothing is delivered while a transaction is open: alerts and digests are claimed first.
+type AlertStateRepository interface {
+	// Policy returns the cooldown and re-arm of a subscription with its owner's quiet hours
+	Policy(ctx context.Context, subscriptionID int64) (*AlertPolicy, error)
+	// WithState passes the current state (nil if none) to fn while the row is locked and
+	// stores what fn returns, setting its new Version. A nil result leaves the state
+	// untouched, an error rolls everything back. ctx of fn carries the transaction.
+	WithState(ctx context.Context, subscriptionID, productID, ruleID int64, fn func(ctx context.Context, st *AlertState) (*AlertState, error)) error
+	// RecordSent stores a delivery made under claim and ends the claim. The rule is only
+	// disarmed while the state is still at the claim's version, a re-arm in between wins.
+	RecordSent(ctx context.Context, claim, sent *AlertState) error
+	// ReleaseClaim ends a claim whose delivery failed
+	ReleaseClaim(ctx context.Context, claim *AlertState) error
+	QueueDigest(ctx context.Context, userID int64, n *Notification) error
+	// UsersWithDigest returns the users with queued alerts, only their ID and quiet hours set
+	UsersWithDigest(ctx context.Context) ([]*User, error)
+	// FlushDigest claims the queued notifications of a user until the given time and
+	// passes them to fn outside of any transaction. They are removed only if fn succeeds.
+	FlushDigest(ctx context.Context, userID int64, until time.Time, fn func(items []*Notification) error) error
+}
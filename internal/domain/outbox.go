@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Outbox message kinds, the relay knows how to publish each of them
+const (
+	OutboxProductUpdate  = "product_update"
+	OutboxProductDeleted = "product_deleted"
+)
+
+// ErrUnpublishable marks a publish error retrying can't fix, e.g. an unknown kind or an
+// event that doesn't encode. The relay parks such a message right away.
+var ErrUnpublishable = errors.New("message can't be published")
+
+// OutboxMessage is a Kafka message written in the same transaction as the change that
+// causes it. The relay publishes it afterwards, at least once.
+type OutboxMessage struct {
+	ID          int64     `json:"id"`
+	Kind        string    `json:"kind"`
+	AggregateID int64     `json:"aggregate_id"`
+	Attempts    int       `json:"attempts"`
+	LastError   string    `json:"last_error,omitempty"`
+	RequestID   string    `json:"request_id,omitempty"`  // of the request that queued it
+	Traceparent string    `json:"traceparent,omitempty"` // W3C trace context of that request
+	AvailableAt time.Time `json:"available_at"`          // not published before
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// EventPublisher defines the behavior for publishing outbox messages to Kafka. at is
+// when the message was queued, i.e. when the change behind it was made.
+type EventPublisher interface {
+	SendProductUpdate(ctx context.Context, id int64) error
+	SendProductDeleted(ctx context.Context, id int64, at time.Time) error
+}
+
+// OutboxRepository defines the behavior for storing and draining the outbox
+type OutboxRepository interface {
+	// Enqueue joins the transaction ctx carries, if any
+	Enqueue(ctx context.Context, m *OutboxMessage) error
+	// ClaimDue takes up to limit due messages and makes them due again only at until, so
+	// other relays skip them while this one publishes. The claim commits right away, a relay
+	// that dies mid-batch leaves its messages to be claimed again once until passed.
+	ClaimDue(ctx context.Context, limit int, until time.Time) ([]*OutboxMessage, error)
+	// MarkSent records that Kafka took the messages
+	MarkSent(ctx context.Context, ids []int64) error
+	// Retry counts a failed attempt and makes the message due again at at
+	Retry(ctx context.Context, id int64, lastErr string, at time.Time) error
+	// Park counts a failed attempt and gives up on the message, it stays for inspection
+	Park(ctx context.Context, id int64, lastErr string) error
+	// DeleteSent removes messages sent or parked before the cutoff
+	DeleteSent(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Transactor runs fn in one database transaction. Repositories called with the context
+// passed to fn take part in it; a nested call becomes a savepoint.
+type Transactor interface {
+	InTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type PriceEventType string
+
+const (
+	PriceEventObserved PriceEventType = "price" // a check recorded a new observation
+	PriceEventAlert    PriceEventType = "alert" // a rule matched, Alert is set
+)
+
+// PriceEvent is pushed to live subscribers after every check of a product.
+// Alert events with a UserID belong to that user's watchlist and are only shown to them.
+// ID is assigned by the hub of each replica, it orders events for SSE resumption.
+type PriceEvent struct {
+	ID         int64          `json:"id,omitempty"`
+	Type       PriceEventType `json:"type"`
+	ProductID  int64          `json:"product_id"`
+	Title      string         `json:"title"`
+	Price      float64        `json:"price"`
+	Currency   string         `json:"currency"`
+	InStock    bool           `json:"in_stock"`
+	Alert      *AlertEvent    `json:"alert,omitempty"`
+	ObservedAt time.Time      `json:"observed_at"`
+}
+
+// PriceEventPublisher fans price events out to everybody streaming them, on every replica
+type PriceEventPublisher interface {
+	Publish(ctx context.Context, e *PriceEvent) error
+}
@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// PricePoint is one stored observation of a product page
+type PricePoint struct {
+	ProductID  int64     `json:"product_id"`
+	Price      float64   `json:"price"`
+	Currency   string    `json:"currency"`
+	InStock    bool      `json:"in_stock"`
+	Source     string    `json:"source"`
+	ObservedAt time.Time `json:"observed_at"`
+}
+
+// PriceBucket aggregates the in-stock observations that fall into one time step
+type PriceBucket struct {
+	Start time.Time `json:"start"`
+	Min   float64   `json:"min"`
+	Max   float64   `json:"max"`
+	Avg   float64   `json:"avg"`
+	Last  float64   `json:"last"`
+	Count int       `json:"count"`
+}
+
+// PriceHistory is the answer to "how did the price move between From and To".
+// Points are returned when no step was requested, Buckets otherwise.
+type PriceHistory struct {
+	ProductID  int64          `json:"product_id"`
+	From       time.Time      `json:"from"`
+	To         time.Time      `json:"to"`
+	Step       string         `json:"step,omitempty"`
+	Points     []*PricePoint  `json:"points,omitempty"`
+	Buckets    []*PriceBucket `json:"buckets,omitempty"`
+	AllTimeLow *PricePoint    `json:"all_time_low,omitempty"`
+}
+
+// PriceHistoryRepository defines the behavior for storing price observations
+type PriceHistoryRepository interface {
+	AddPoint(ctx context.Context, p *PricePoint) error
+	GetRange(ctx context.Context, productID int64, from, to time.Time) ([]*PricePoint, error)
+	GetBuckets(ctx context.Context, productID int64, from, to time.Time, step time.Duration) ([]*PriceBucket, error)
+	GetLowest(ctx context.Context, productID int64) (*PricePoint, error)
+	GetLatest(ctx context.Context, productID int64) (*PricePoint, error)
+	GetUnchangedSince(ctx context.Context, productID int64, price float64) (time.Time, error)
+}
@@ -5,6 +5,9 @@ import (
 	"time"
 )
 
+// PendingTitle is the placeholder title of a tracked product until its page is fetched
+const PendingTitle = "Pending..."
+
 // Product represents the core business entity of our system
 // We use float64 for simplicity, but in real fintech, you'd use decimal strings or integers (cents)
 type Product struct {
@@ -15,6 +18,60 @@ type Product struct {
 	TargetPrice  float64   `json:"target_price"`
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
+	// CheckInterval overrides the host's and the default interval, 0 keeps them
+	CheckInterval Interval   `json:"check_interval,omitempty"`
+	NextCheckAt   *time.Time `json:"next_check_at,omitempty"` // nil until the scheduler planned one
+}
+
+// ProductSort is a key product listings can be ordered by
+type ProductSort string
+
+const (
+	SortByID      ProductSort = "id"
+	SortByCreated ProductSort = "created_at"
+	SortByUpdated ProductSort = "updated_at"
+	SortByPrice   ProductSort = "price"
+	SortByTitle   ProductSort = "title"
+)
+
+// ProductFilter narrows a product listing, zero values mean no filter
+type ProductFilter struct {
+	Host         string
+	MinPrice     float64
+	MaxPrice     float64
+	BelowTarget  bool // current price at or below the product's target
+	UpdatedSince time.Time
+	Search       string // words that must appear in the title
+}
+
+// ProductQuery asks for one page of a keyset paginated listing
+type ProductQuery struct {
+	ProductFilter
+	Sort  ProductSort
+	Desc  bool
+	Limit int
+	After *ProductCursor // nil for the first page
+}
+
+// ProductCursor is the position of the last row of the previous page.
+// Value is that row's sort key in text form, ID breaks ties.
+type ProductCursor struct {
+	Value string `json:"v"`
+	ID    int64  `json:"id"`
+}
+
+// ProductPage is one page of products, NextCursor is empty on the last page
+type ProductPage struct {
+	Items      []*Product `json:"items"`
+	NextCursor string     `json:"next_cursor,omitempty"`
+}
+
+// ProductPatch holds the fields of a partial update, nil means unchanged
+type ProductPatch struct {
+	Title       *string  `json:"title"`
+	TargetPrice *float64 `json:"target_price"`
+	// CheckInterval "0s" goes back to the host's or the default interval
+	CheckInterval *Interval `json:"check_interval"`
 }
 
 // ProductRepository defines the behavior for storing and retrieving products.
@@ -22,22 +79,43 @@ type Product struct {
 type ProductRepository interface {
 	Create(ctx context.Context, p *Product) error
 	GetByID(ctx context.Context, id int64) (*Product, error)
+	// GetForUpdate locks the product until the transaction ctx carries ends
+	GetForUpdate(ctx context.Context, id int64) (*Product, error)
+	GetByURL(ctx context.Context, url string) (*Product, error)
 	UpdatePrice(ctx context.Context, id int64, newPrice float64) error
-	GetAll(ctx context.Context) ([]*Product, error)
+	UpdateTitle(ctx context.Context, id int64, title string) error
+	Update(ctx context.Context, p *Product) error
+	Delete(ctx context.Context, id int64) error
+	// List returns up to q.Limit products after q.After, it never loads the whole table
+	List(ctx context.Context, q ProductQuery) ([]*Product, error)
 }
 
 // ProductService defines the business logic operations
 type ProductService interface {
 	Create(ctx context.Context, p *Product) error
 	GetByID(ctx context.Context, id int64) (*Product, error)
-	TrackProduct(ctx context.Context, url string, targetPrice float64) error
+	ListProducts(ctx context.Context, q ProductQuery, cursor string) (*ProductPage, error)
+	UpdateProduct(ctx context.Context, id int64, patch ProductPatch) (*Product, error)
+	DeleteProduct(ctx context.Context, id int64) error
+	TrackProduct(ctx context.Context, userID int64, url string, targetPrice float64) (*Subscription, error)
+	ListWatchlist(ctx context.Context, userID int64) ([]*Subscription, error)
+	UpdateSubscription(ctx context.Context, userID, id int64, targetPrice float64) (*Subscription, error)
+	UntrackProduct(ctx context.Context, userID, id int64) error
 	CheckPrices(ctx context.Context) error
 	ProcessSingleProduct(ctx context.Context, id int64) error
+	GetPriceHistory(ctx context.Context, id int64, from, to time.Time, step time.Duration) (*PriceHistory, error)
 }
 
 // TaskProducer defines the behavior for sending async tasks to Kafka
 type TaskProducer interface {
 	SendProductUpdate(ctx context.Context, id int64) error // changed name
+	// SendProductDeleted tells consumers to drop what they hold about the product
+	SendProductDeleted(ctx context.Context, id int64) error
+}
+
+// ObservationProducer defines the behavior for announcing the outcome of every check
+type ObservationProducer interface {
+	SendPriceObserved(ctx context.Context, productID int64, info *PriceInfo, at time.Time) error
 }
 
 // ProductCache defines the behavior for caching product data in Redis
@@ -0,0 +1,107 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Interval is a duration written as a Go duration string in JSON, e.g. "6h" or "90m"
+type Interval time.Duration
+
+func (i Interval) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(i).String())
+}
+
+func (i *Interval) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("interval must be a duration string like \"6h\": %w", err)
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*i = Interval(d)
+	return nil
+}
+
+// HostInterval is how often the products of a shop are checked unless they set their own.
+// The host is stored without "www.", so both spellings share it.
+type HostInterval struct {
+	Host      string    `json:"host"`
+	Interval  Interval  `json:"interval"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// IntervalSource tells where a product's base check interval comes from
+type IntervalSource string
+
+const (
+	IntervalFromProduct IntervalSource = "product"
+	IntervalFromHost    IntervalSource = "host"
+	IntervalFromDefault IntervalSource = "default"
+)
+
+// DueCheck is a product whose next check is due
+type DueCheck struct {
+	ProductID int64
+	Host      string
+	Interval  time.Duration // of the product or else its host, 0 for the default
+	Source    IntervalSource
+	// FirstQueued is set for a product that was never scheduled, its first check was
+	// queued when it was created
+	FirstQueued bool
+}
+
+// CheckSignals are what an adaptive interval is derived from
+type CheckSignals struct {
+	Observations int     // checks within the volatility window
+	Changes      int     // of those, the ones that saw a different price than the check before
+	Price        float64 // current price
+	Target       float64 // lowest target of the product and its subscribers, 0 without one
+	Subscribers  int
+}
+
+// PlanFactor is one adjustment of the base interval, Multiplier below 1 checks more often
+type PlanFactor struct {
+	Name       string  `json:"name"`
+	Multiplier float64 `json:"multiplier"`
+	Reason     string  `json:"reason"`
+}
+
+// CheckPlan explains how a product's check interval was arrived at
+type CheckPlan struct {
+	ProductID    int64          `json:"product_id"`
+	Source       IntervalSource `json:"source"`
+	BaseInterval Interval       `json:"base_interval"`
+	Adaptive     bool           `json:"adaptive"`
+	Signals      *CheckSignals  `json:"signals,omitempty"`
+	Factors      []PlanFactor   `json:"factors,omitempty"`
+	// Bounded is "min" or "max" when the interval was raised or lowered to that bound
+	Bounded     string     `json:"bounded,omitempty"`
+	Interval    Interval   `json:"interval"`
+	NextCheckAt *time.Time `json:"next_check_at,omitempty"`
+}
+
+// CheckPlanner works out the interval of a product's checks
+type CheckPlanner interface {
+	Explain(ctx context.Context, productID int64) (*CheckPlan, error)
+}
+
+// ScheduleRepository defines the behavior for storing when products are checked next
+type ScheduleRepository interface {
+	// LockDue locks up to limit due products, other schedulers skip them until the
+	// transaction ctx carries ends. It must be called in one.
+	LockDue(ctx context.Context, limit int) ([]*DueCheck, error)
+	// GetCheck is LockDue for a single product, due or not, without locking it
+	GetCheck(ctx context.Context, productID int64) (*DueCheck, error)
+	// Signals returns the signals of the given products, prices observed before since
+	// are left out
+	Signals(ctx context.Context, productIDs []int64, since time.Time) (map[int64]*CheckSignals, error)
+	Reschedule(ctx context.Context, productID int64, next time.Time) error
+	SetHostInterval(ctx context.Context, h *HostInterval) error
+	DeleteHostInterval(ctx context.Context, host string) error
+	ListHostIntervals(ctx context.Context) ([]*HostInterval, error)
+}
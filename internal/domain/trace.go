@@ -0,0 +1,131 @@
+package domain
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// TraceContext is the W3C trace context of a request, carried in the traceparent header
+// of HTTP requests and Kafka messages: https://www.w3.org/TR/trace-context/
+type TraceContext struct {
+	TraceID [16]byte
+	SpanID  [8]byte
+	Flags   byte
+}
+
+// traceSampled is the only flag defined by the spec
+const traceSampled = 0x01
+
+// NewTraceContext starts a trace, for requests that arrive without one
+func NewTraceContext() TraceContext {
+	var t TraceContext
+	rand.Read(t.TraceID[:])
+	rand.Read(t.SpanID[:])
+	t.Flags = traceSampled
+	return t
+}
+
+// Child is the context of a span within t, e.g. the consumer of a message
+func (t TraceContext) Child() TraceContext {
+	rand.Read(t.SpanID[:])
+	return t
+}
+
+// Traceparent formats t as version 00 of the traceparent header
+func (t TraceContext) Traceparent() string {
+	return fmt.Sprintf("00-%x-%x-%02x", t.TraceID, t.SpanID, t.Flags)
+}
+
+var errTraceparent = errors.New("malformed traceparent")
+
+// ParseTraceparent reads a traceparent header. Versions after 00 may append fields,
+// which are ignored.
+func ParseTraceparent(s string) (TraceContext, error) {
+	var t TraceContext
+	if len(s) < 55 || s[2] != '-' || s[35] != '-' || s[52] != '-' {
+		return t, errTraceparent
+	}
+	version := s[:2]
+	if version == "ff" || (version == "00" && len(s) != 55) || (len(s) > 55 && s[55] != '-') {
+		return t, errTraceparent
+	}
+
+	var flags [1]byte
+	for _, f := range []struct {
+		dst []byte
+		src string
+	}{{nil, version}, {t.TraceID[:], s[3:35]}, {t.SpanID[:], s[36:52]}, {flags[:], s[53:55]}} {
+		if !isLowerHex(f.src) {
+			return t, errTraceparent
+		}
+		if f.dst != nil {
+			hex.Decode(f.dst, []byte(f.src))
+		}
+	}
+	t.Flags = flags[0]
+
+	if t.TraceID == [16]byte{} || t.SpanID == [8]byte{} {
+		return t, errTraceparent
+	}
+	return t, nil
+}
+
+func isLowerHex(s string) bool {
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidRequestID accepts caller supplied IDs of printable ASCII up to 128 bytes, they
+// end up in logs and Kafka headers
+func ValidRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// NewRequestID returns a random ID for requests that arrive without one
+func NewRequestID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+type (
+	traceKey     struct{}
+	requestIDKey struct{}
+)
+
+// WithTrace returns a context carrying the trace context
+func WithTrace(ctx context.Context, t TraceContext) context.Context {
+	return context.WithValue(ctx, traceKey{}, t)
+}
+
+// TraceFromContext returns the trace context, false outside a traced request
+func TraceFromContext(ctx context.Context) (TraceContext, bool) {
+	t, ok := ctx.Value(traceKey{}).(TraceContext)
+	return t, ok
+}
+
+// WithRequestID returns a context carrying the request, or correlation, ID
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID, empty outside a request
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
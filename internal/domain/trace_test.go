@@ -0,0 +1,45 @@
+package domain
+
+import "testing"
+
+func TestParseTraceparent(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		wantErr bool
+	}{
+		{"Valid", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", false},
+		{"Not sampled", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", false},
+		{"Future version with extra fields", "cc-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-what-the-future-holds", false},
+		{"Version 00 with extra fields", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", true},
+		{"Forbidden version", "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", true},
+		{"Uppercase", "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", true},
+		{"Zero trace id", "00-00000000000000000000000000000000-00f067aa0ba902b7-01", true},
+		{"Zero span id", "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", true},
+		{"Too short", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7", true},
+		{"Empty", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTraceparent(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTraceparent() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && tt.in[:2] == "00" && got.Traceparent() != tt.in {
+				t.Errorf("Traceparent() = %q, want %q", got.Traceparent(), tt.in)
+			}
+		})
+	}
+}
+
+func TestTraceContext_Child(t *testing.T) {
+	parent := NewTraceContext()
+	child := parent.Child()
+	if child.TraceID != parent.TraceID || child.SpanID == parent.SpanID {
+		t.Errorf("Child() = %s, want the trace of %s with a new span", child.Traceparent(), parent.Traceparent())
+	}
+	if _, err := ParseTraceparent(child.Traceparent()); err != nil {
+		t.Errorf("ParseTraceparent(Child()) error = %v", err)
+	}
+}
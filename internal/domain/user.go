@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// User owns subscriptions and notification channels
+type User struct {
+	ID        int64     `json:"id"`
+	Email     string    `json:"email"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	QuietHours
+}
+
+// Subscription is one user watching one product with their own target price.
+// Product is filled when listing a watchlist.
+type Subscription struct {
+	ID          int64   `json:"id"`
+	UserID      int64   `json:"user_id"`
+	ProductID   int64   `json:"product_id"`
+	TargetPrice float64 `json:"target_price"`
+	// CooldownMinutes and Rearm stop repeats of the same alert, see AlertPolicy
+	CooldownMinutes int       `json:"cooldown_minutes"`
+	Rearm           bool      `json:"rearm"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+	Product         *Product  `json:"product,omitempty"`
+}
+
+// UserRepository defines the behavior for storing users
+type UserRepository interface {
+	Create(ctx context.Context, u *User) error
+	GetByID(ctx context.Context, id int64) (*User, error)
+	GetByEmail(ctx context.Context, email string) (*User, error)
+	UpdateQuietHours(ctx context.Context, id int64, q QuietHours) error
+}
+
+// SubscriptionRepository defines the behavior for storing watchlists
+type SubscriptionRepository interface {
+	// Upsert creates the subscription or updates the target of an existing one
+	Upsert(ctx context.Context, s *Subscription) error
+	GetByID(ctx context.Context, userID, id int64) (*Subscription, error)
+	ListByUser(ctx context.Context, userID int64) ([]*Subscription, error)
+	ListByProduct(ctx context.Context, productID int64) ([]*Subscription, error)
+	UpdateTarget(ctx context.Context, userID, id int64, targetPrice float64) error
+	UpdatePolicy(ctx context.Context, userID, id int64, cooldownMinutes int, rearm bool) error
+	Delete(ctx context.Context, userID, id int64) error
+}
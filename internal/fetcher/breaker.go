@@ -0,0 +1,255 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	neturl "net/url"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/derkres11/price-pulse/internal/domain"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	circuitState = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "pricepulse_circuit_state",
+		Help: "Circuit breaker state by host: 0 closed, 1 half-open, 2 open.",
+	}, []string{"host"})
+
+	circuitRejected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "pricepulse_circuit_rejected_total",
+		Help: "Fetches failed fast because the host's circuit was open.",
+	}, []string{"host"})
+
+	circuitStoreErrors = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "pricepulse_circuit_store_errors_total",
+		Help: "Circuit store calls that failed, the fetches went through unchecked.",
+	})
+)
+
+var stateValues = map[domain.CircuitState]float64{
+	domain.CircuitClosed:   0,
+	domain.CircuitHalfOpen: 1,
+	domain.CircuitOpen:     2,
+}
+
+// BreakerOptions are the thresholds of every host's circuit
+type BreakerOptions struct {
+	FailureThreshold int           // failures in a row that open the circuit
+	OpenTimeout      time.Duration // before an open circuit lets trial requests through
+	HalfOpenRequests int           // trial requests, all of them must succeed to close it
+}
+
+// BreakerFetcher fails fast for hosts that keep failing instead of waiting for every
+// product's timeout. Only failures of the host count: errors, timeouts and 5xx, not a
+// page without a price or a fetch held back out of politeness. The circuits are kept
+// in store, so all replicas count the failures of a host together. While the store is
+// unreachable the breaker fails open: fetches go through as if there was none.
+type BreakerFetcher struct {
+	next      domain.PriceFetcher
+	store     domain.CircuitStore
+	opts      BreakerOptions
+	now       func() time.Time
+	storeDown atomic.Bool // the last store call failed
+}
+
+func NewBreakerFetcher(next domain.PriceFetcher, store domain.CircuitStore, opts BreakerOptions) *BreakerFetcher {
+	return &BreakerFetcher{
+		next:  next,
+		store: store,
+		opts:  opts,
+		now:   time.Now,
+	}
+}
+
+func (b *BreakerFetcher) Fetch(ctx context.Context, url string) (*domain.PriceInfo, error) {
+	u, err := neturl.Parse(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid product url %q: %w", url, err)
+	}
+	host := strings.ToLower(u.Hostname())
+
+	t, err := b.allow(ctx, host)
+	if errors.Is(err, domain.ErrCircuitOpen) {
+		circuitRejected.WithLabelValues(host).Inc()
+	}
+	if err != nil {
+		return nil, err
+	}
+	info, err := b.next.Fetch(ctx, url)
+	b.record(ctx, host, t, err)
+	return info, err
+}
+
+// ticket is what allow knew of the circuit when it let a request through
+type ticket struct {
+	generation int64
+	halfOpen   bool // the request is a trial
+	unchecked  bool // the store was unreachable, the outcome is not recorded
+}
+
+// allow lets a request to host through unless its circuit is open or all trials of a
+// half-open one are taken
+func (b *BreakerFetcher) allow(ctx context.Context, host string) (ticket, error) {
+	var t ticket
+	err := b.store.Update(ctx, host, func(c *domain.Circuit) error {
+		now := b.now()
+		switch c.State {
+		case domain.CircuitOpen:
+			retryAt := c.OpenedAt.Add(b.opts.OpenTimeout)
+			if now.Before(retryAt) {
+				return fmt.Errorf("%w: %s, retrying after %s", domain.ErrCircuitOpen, host, retryAt.Format(time.RFC3339))
+			}
+			b.transition(c, domain.CircuitHalfOpen)
+		case domain.CircuitHalfOpen:
+			// Trials of a replica that died never report, they are given up on after
+			// OpenTimeout and their outcomes ignored should they turn up after all
+			if c.Trials >= b.opts.HalfOpenRequests && !now.Before(c.ChangedAt.Add(b.opts.OpenTimeout)) {
+				b.transition(c, domain.CircuitHalfOpen)
+			}
+		}
+		if c.State == domain.CircuitHalfOpen {
+			if c.Trials >= b.opts.HalfOpenRequests {
+				return fmt.Errorf("%w: %s, trial requests in flight", domain.ErrCircuitOpen, host)
+			}
+			c.Trials++
+		}
+		t = ticket{generation: c.Generation, halfOpen: c.State == domain.CircuitHalfOpen}
+		return nil
+	})
+	if errors.Is(err, domain.ErrCircuitOpen) {
+		b.storeReached()
+		return ticket{}, err
+	}
+	if err != nil {
+		b.storeFailed(err)
+		return ticket{unchecked: true}, nil
+	}
+	b.storeReached()
+	return t, nil
+}
+
+// record moves the circuit on by the outcome of a request that allow let through. An
+// outcome of a request admitted before the circuit last changed state is ignored, e.g.
+// a slow request let through while closed must not decide a half-open circuit. The
+// request's context may be gone by now, the outcome is recorded anyway.
+func (b *BreakerFetcher) record(ctx context.Context, host string, t ticket, err error) {
+	if t.unchecked {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), time.Second)
+	defer cancel()
+
+	// A lost outcome only delays the circuit, a trial it held is given up on in time
+	storeErr := b.store.Update(ctx, host, func(c *domain.Circuit) error {
+		if c.Generation != t.generation {
+			return nil
+		}
+		if t.halfOpen {
+			c.Trials--
+		}
+
+		switch {
+		case !hostFailed(err) && err != nil:
+			// Says nothing about the host, the trial is given back
+		case err == nil && t.halfOpen:
+			c.Succeeded++
+			if c.Succeeded >= b.opts.HalfOpenRequests {
+				c.Failures = 0
+				b.transition(c, domain.CircuitClosed)
+			}
+		case err == nil:
+			c.Failures = 0
+		case t.halfOpen:
+			c.Failures++
+			b.transition(c, domain.CircuitOpen)
+		default:
+			c.Failures++
+			if c.State == domain.CircuitClosed && c.Failures >= b.opts.FailureThreshold {
+				b.transition(c, domain.CircuitOpen)
+			}
+		}
+		return nil
+	})
+	if storeErr != nil {
+		b.storeFailed(storeErr)
+		return
+	}
+	b.storeReached()
+}
+
+// storeFailed counts a failed store call and logs the first of a row
+func (b *BreakerFetcher) storeFailed(err error) {
+	circuitStoreErrors.Inc()
+	if !b.storeDown.Swap(true) {
+		slog.Warn("Circuit breaker: store unreachable, fetching without circuits", slog.String("error", err.Error()))
+	}
+}
+
+func (b *BreakerFetcher) storeReached() {
+	if b.storeDown.Swap(false) {
+		slog.Info("Circuit breaker: store reachable again")
+	}
+}
+
+func (b *BreakerFetcher) transition(c *domain.Circuit, state domain.CircuitState) {
+	c.State = state
+	c.Generation++
+	c.ChangedAt = b.now()
+	c.Trials, c.Succeeded = 0, 0
+	if state == domain.CircuitOpen {
+		c.OpenedAt = c.ChangedAt
+	}
+	circuitState.WithLabelValues(c.Host).Set(stateValues[state])
+}
+
+// hostFailed tells the errors that mean the host is in trouble
+func hostFailed(err error) bool {
+	if err == nil ||
+		errors.Is(err, ErrPriceNotFound) ||
+		errors.Is(err, domain.ErrFetchDisallowed) ||
+		errors.Is(err, domain.ErrHostThrottled) ||
+		errors.Is(err, context.Canceled) {
+		return false
+	}
+	var status *StatusError
+	if errors.As(err, &status) {
+		return status.Code >= 500
+	}
+	return true
+}
+
+func (b *BreakerFetcher) RetryAt(ctx context.Context, host string) (time.Time, bool, error) {
+	c, err := b.store.Get(ctx, strings.ToLower(host))
+	if err != nil || c.State != domain.CircuitOpen {
+		return time.Time{}, false, err
+	}
+	retryAt := c.OpenedAt.Add(b.opts.OpenTimeout)
+	return retryAt, b.now().Before(retryAt), nil
+}
+
+// Circuits lists the hosts whose circuit is not closed or that failed lately
+func (b *BreakerFetcher) Circuits(ctx context.Context) ([]*domain.HostCircuit, error) {
+	stored, err := b.store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	circuits := make([]*domain.HostCircuit, 0, len(stored))
+	for _, c := range stored {
+		hc := &domain.HostCircuit{Host: c.Host, State: c.State, Failures: c.Failures}
+		if c.State != domain.CircuitClosed {
+			openedAt, retryAt := c.OpenedAt, c.OpenedAt.Add(b.opts.OpenTimeout)
+			hc.OpenedAt, hc.RetryAt = &openedAt, &retryAt
+		}
+		circuits = append(circuits, hc)
+	}
+	sort.Slice(circuits, func(i, j int) bool { return circuits[i].Host < circuits[j].Host })
+	return circuits, nil
+}
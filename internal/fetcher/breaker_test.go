@@ -0,0 +1,257 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/derkres11/price-pulse/internal/domain"
+)
+
+// scriptedFetcher answers with err and counts the requests that reached it
+type scriptedFetcher struct {
+	err   error
+	calls int
+}
+
+func (f *scriptedFetcher) Fetch(ctx context.Context, url string) (*domain.PriceInfo, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &domain.PriceInfo{Price: 10, InStock: true}, nil
+}
+
+// circuitsMock keeps the circuits in memory, Update applies fn to a copy like the
+// Redis store does
+type circuitsMock struct {
+	circuits map[string]domain.Circuit
+}
+
+func (m *circuitsMock) Get(ctx context.Context, host string) (*domain.Circuit, error) {
+	c, ok := m.circuits[host]
+	if !ok {
+		c = domain.Circuit{Host: host, State: domain.CircuitClosed}
+	}
+	return &c, nil
+}
+
+func (m *circuitsMock) Update(ctx context.Context, host string, fn func(c *domain.Circuit) error) error {
+	c, _ := m.Get(ctx, host)
+	if err := fn(c); err != nil {
+		return err
+	}
+	m.circuits[host] = *c
+	return nil
+}
+
+func (m *circuitsMock) List(ctx context.Context) ([]*domain.Circuit, error) {
+	var list []*domain.Circuit
+	for _, c := range m.circuits {
+		if c.State != domain.CircuitClosed || c.Failures > 0 {
+			list = append(list, &c)
+		}
+	}
+	return list, nil
+}
+
+func newCircuitsMock() *circuitsMock {
+	return &circuitsMock{circuits: map[string]domain.Circuit{}}
+}
+
+func TestBreakerFetcher(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	next := &scriptedFetcher{err: errors.New("connection refused")}
+	b := NewBreakerFetcher(next, newCircuitsMock(), BreakerOptions{FailureThreshold: 3, OpenTimeout: time.Minute, HalfOpenRequests: 1})
+	b.now = func() time.Time { return now }
+	const url = "https://Shop.example:8443/p/1"
+
+	fetch := func() error {
+		_, err := b.Fetch(context.Background(), url)
+		return err
+	}
+	circuits := func() []*domain.HostCircuit {
+		list, err := b.Circuits(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return list
+	}
+	state := func() domain.CircuitState {
+		for _, c := range circuits() {
+			if c.Host == "shop.example" {
+				return c.State
+			}
+		}
+		return domain.CircuitClosed
+	}
+
+	for range 3 {
+		if err := fetch(); errors.Is(err, domain.ErrCircuitOpen) {
+			t.Fatalf("circuit open before the threshold: %v", err)
+		}
+	}
+	if state() != domain.CircuitOpen {
+		t.Fatalf("state after 3 failures = %s, want open", state())
+	}
+	if err := fetch(); !errors.Is(err, domain.ErrCircuitOpen) || next.calls != 3 {
+		t.Fatalf("open circuit: err = %v after %d calls, want ErrCircuitOpen without a call", err, next.calls)
+	}
+	if at, ok, _ := b.RetryAt(context.Background(), "shop.example"); !ok || !at.Equal(now.Add(time.Minute)) {
+		t.Errorf("RetryAt() = %s, %v; want %s", at, ok, now.Add(time.Minute))
+	}
+
+	// A failed trial opens it again
+	now = now.Add(time.Minute)
+	if err := fetch(); errors.Is(err, domain.ErrCircuitOpen) || next.calls != 4 {
+		t.Fatalf("trial: err = %v after %d calls, want the request to go through", err, next.calls)
+	}
+	if state() != domain.CircuitOpen {
+		t.Fatalf("state after a failed trial = %s, want open", state())
+	}
+
+	// A successful trial closes it
+	now = now.Add(time.Minute)
+	next.err = nil
+	if err := fetch(); err != nil {
+		t.Fatalf("trial: %v", err)
+	}
+	if state() != domain.CircuitClosed || len(circuits()) != 0 {
+		t.Errorf("circuits after a successful trial = %+v, want none", circuits())
+	}
+	if _, ok, _ := b.RetryAt(context.Background(), "shop.example"); ok {
+		t.Error("RetryAt() reports a closed circuit as open")
+	}
+}
+
+func TestBreakerFetcher_IgnoresStaleOutcomes(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	store := newCircuitsMock()
+	b := NewBreakerFetcher(&scriptedFetcher{}, store, BreakerOptions{FailureThreshold: 1, OpenTimeout: time.Minute, HalfOpenRequests: 1})
+	b.now = func() time.Time { return now }
+	const host = "shop.example"
+	failure := errors.New("connection refused")
+
+	slow, err := b.allow(ctx, host) // let through while closed, answers late
+	if err != nil {
+		t.Fatal(err)
+	}
+	failed, _ := b.allow(ctx, host)
+	b.record(ctx, host, failed, failure)
+
+	now = now.Add(time.Minute)
+	trial, err := b.allow(ctx, host)
+	if err != nil || !trial.halfOpen {
+		t.Fatalf("allow() = %+v, %v; want a trial", trial, err)
+	}
+
+	// The slow request neither closes the half-open circuit nor frees the trial
+	b.record(ctx, host, slow, nil)
+	if c := store.circuits[host]; c.State != domain.CircuitHalfOpen || c.Trials != 1 {
+		t.Fatalf("circuit after a stale success = %+v, want half-open with the trial taken", c)
+	}
+	if _, err := b.allow(ctx, host); !errors.Is(err, domain.ErrCircuitOpen) {
+		t.Errorf("allow() = %v, want the trial still in flight", err)
+	}
+
+	b.record(ctx, host, trial, nil)
+	if c := store.circuits[host]; c.State != domain.CircuitClosed {
+		t.Errorf("circuit after the trial succeeded = %+v, want closed", c)
+	}
+}
+
+func TestBreakerFetcher_GivesUpLostTrials(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	store := newCircuitsMock()
+	b := NewBreakerFetcher(&scriptedFetcher{}, store, BreakerOptions{FailureThreshold: 1, OpenTimeout: time.Minute, HalfOpenRequests: 1})
+	b.now = func() time.Time { return now }
+	const host = "shop.example"
+
+	failed, _ := b.allow(ctx, host)
+	b.record(ctx, host, failed, errors.New("connection refused"))
+	now = now.Add(time.Minute)
+	lost, err := b.allow(ctx, host) // the replica running it dies
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := b.allow(ctx, host); !errors.Is(err, domain.ErrCircuitOpen) {
+		t.Fatalf("allow() = %v, want the trial in flight", err)
+	}
+	now = now.Add(time.Minute)
+	trial, err := b.allow(ctx, host)
+	if err != nil || trial.generation == lost.generation {
+		t.Fatalf("allow() = %+v, %v; want a trial of a new generation", trial, err)
+	}
+
+	// The lost trial turning up after all changes nothing
+	b.record(ctx, host, lost, errors.New("connection refused"))
+	if c := store.circuits[host]; c.State != domain.CircuitHalfOpen || c.Trials != 1 {
+		t.Errorf("circuit after the lost trial reported = %+v, want half-open with the new trial taken", c)
+	}
+}
+
+// brokenCircuits is a store that cannot be reached
+type brokenCircuits struct {
+	domain.CircuitStore
+	calls int
+}
+
+func (s *brokenCircuits) Update(ctx context.Context, host string, fn func(c *domain.Circuit) error) error {
+	s.calls++
+	return errors.New("redis: connection refused")
+}
+
+func TestBreakerFetcher_FailsOpen(t *testing.T) {
+	next := &scriptedFetcher{err: errors.New("connection refused")}
+	store := &brokenCircuits{}
+	b := NewBreakerFetcher(next, store, BreakerOptions{FailureThreshold: 1, OpenTimeout: time.Minute, HalfOpenRequests: 1})
+
+	// Every request reaches the shop and gets its own error, no outcome is recorded
+	for range 3 {
+		if _, err := b.Fetch(context.Background(), "https://shop.example/p/1"); errors.Is(err, domain.ErrCircuitOpen) || err != next.err {
+			t.Fatalf("Fetch() error = %v, want the shop's", err)
+		}
+	}
+	if next.calls != 3 || store.calls != 3 {
+		t.Errorf("%d fetches and %d store calls, want 3 of each", next.calls, store.calls)
+	}
+	if !b.storeDown.Load() {
+		t.Error("store not reported unreachable")
+	}
+
+	next.err = nil
+	if info, err := b.Fetch(context.Background(), "https://shop.example/p/1"); err != nil || info == nil {
+		t.Errorf("Fetch() = %v, %v; want the price", info, err)
+	}
+}
+
+func TestHostFailed(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"Success", nil, false},
+		{"Network error", errors.New("dial tcp: connection refused"), true},
+		{"Timeout", context.DeadlineExceeded, true},
+		{"Server error", fmt.Errorf("load: %w", &StatusError{Code: 502}), true},
+		{"Not found", &StatusError{Code: 404}, false},
+		{"No price", fmt.Errorf("%w: url", ErrPriceNotFound), false},
+		{"Throttled", fmt.Errorf("%w: shop", domain.ErrHostThrottled), false},
+		{"Disallowed", domain.ErrFetchDisallowed, false},
+		{"Canceled", context.Canceled, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hostFailed(tt.err); got != tt.want {
+				t.Errorf("hostFailed(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,359 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/derkres11/price-pulse/internal/domain"
+)
+
+const (
+	defaultUserAgent   = "Mozilla/5.0 (compatible; PricePulse/1.0)"
+	defaultRobotsAgent = "PricePulse"
+	maxBodySize        = 5 << 20 // 5 MiB is plenty for a product page
+	// robotsErrorTTL is how long a robots.txt that failed with a server error keeps the
+	// host disallowed
+	robotsErrorTTL = 10 * time.Minute
+)
+
+// Extraction sources recorded with every observation
+const (
+	SourceJSONLD     = "json-ld"
+	SourceMicrodata  = "microdata"
+	SourceOpenGraph  = "opengraph"
+	SourceRule       = "rule"
+	SourceHeuristics = "heuristics"
+)
+
+// ErrPriceNotFound is returned when the page was loaded but no price could be extracted
+var ErrPriceNotFound = errors.New("price not found on page")
+
+// StatusError is a page answered with a status other than 200
+type StatusError struct {
+	URL  string
+	Code int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status %d from %s", e.Code, e.URL)
+}
+
+var outOfStockPhrases = []string{
+	"out of stock",
+	"sold out",
+	"currently unavailable",
+	"нет в наличии",
+}
+
+// Politeness limits how hard a single host is hit
+type Politeness struct {
+	HostInterval time.Duration // between requests to one host, robots.txt may ask for more
+	MaxWait      time.Duration // for the host's turn before the check is skipped, 0 for no limit
+	Backoff      time.Duration // after a 429 or 503 without Retry-After
+	MaxBackoff   time.Duration // caps Retry-After, 0 for no cap
+	RobotsTTL    time.Duration
+	RobotsAgent  string // picks the robots.txt group, defaults to PricePulse
+	IgnoreRobots bool
+}
+
+// HTTPFetcher downloads product pages and extracts price data from the HTML.
+// Hosts with a rule in the registry are scraped with it, the rest use the generic heuristic.
+// Requests keep to the host's robots.txt and are paced by the limiter, a nil limiter
+// does not pace them.
+type HTTPFetcher struct {
+	client    *http.Client
+	userAgent string
+	rules     *RuleRegistry
+	limiter   domain.HostLimiter
+	polite    Politeness
+	robots    robotsCache
+}
+
+func NewHTTPFetcher(timeout time.Duration, userAgent string, rules *RuleRegistry, limiter domain.HostLimiter, polite Politeness) *HTTPFetcher {
+	if userAgent == "" {
+		userAgent = defaultUserAgent
+	}
+	if polite.RobotsAgent == "" {
+		polite.RobotsAgent = defaultRobotsAgent
+	}
+
+	return &HTTPFetcher{
+		client:    &http.Client{Timeout: timeout},
+		userAgent: userAgent,
+		rules:     rules,
+		limiter:   limiter,
+		polite:    polite,
+	}
+}
+
+func (f *HTTPFetcher) Fetch(ctx context.Context, url string) (*domain.PriceInfo, error) {
+	u, err := neturl.Parse(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid product url %q: %w", url, err)
+	}
+
+	interval := f.polite.HostInterval
+	if !f.polite.IgnoreRobots {
+		robots, err := f.robotsFor(ctx, u)
+		if err != nil {
+			return nil, err
+		}
+		if !robots.allowed(u.RequestURI()) {
+			fetchSkipped.WithLabelValues(hostLabel(u), "robots").Inc()
+			return nil, fmt.Errorf("%w: %s", domain.ErrFetchDisallowed, url)
+		}
+		interval = max(interval, robots.crawlDelay)
+	}
+
+	doc, err := f.load(ctx, u, interval)
+	if err != nil {
+		return nil, err
+	}
+
+	// Structured data is the most reliable source, host rules cover the shops without it
+	info, ok := extractStructured(doc)
+	if !ok {
+		if rule := f.rules.match(u.Hostname()); rule != nil {
+			info, ok = extractWithRule(doc, rule)
+		}
+	}
+	// A rule that finds nothing is most likely outdated, give the heuristic a chance
+	if !ok {
+		info, ok = extractGeneric(doc)
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrPriceNotFound, url)
+	}
+
+	if info.Title == "" {
+		info.Title = pageTitle(doc)
+	}
+
+	return info, nil
+}
+
+func (f *HTTPFetcher) load(ctx context.Context, u *neturl.URL, interval time.Duration) (*goquery.Document, error) {
+	var doc *goquery.Document
+	err := f.get(ctx, u, interval, "text/html,application/xhtml+xml", func(resp *http.Response) error {
+		if resp.StatusCode != http.StatusOK {
+			return &StatusError{URL: u.String(), Code: resp.StatusCode}
+		}
+
+		var err error
+		doc, err = goquery.NewDocumentFromReader(io.LimitReader(resp.Body, maxBodySize))
+		if err != nil {
+			return fmt.Errorf("failed to parse page %s: %w", u, err)
+		}
+		return nil
+	})
+	return doc, err
+}
+
+// robotsFor returns the host's robots.txt rules, loading them when the cached ones
+// expired. Client errors mean there are no rules, server errors that the host wants
+// no visits for now, as RFC 9309 asks.
+func (f *HTTPFetcher) robotsFor(ctx context.Context, u *neturl.URL) (*robotsRules, error) {
+	origin := u.Scheme + "://" + u.Host
+	now := time.Now()
+	if rules, ok := f.robots.get(origin, now); ok {
+		return rules, nil
+	}
+
+	var rules *robotsRules
+	robotsURL := &neturl.URL{Scheme: u.Scheme, Host: u.Host, Path: "/robots.txt"}
+	err := f.get(ctx, robotsURL, f.polite.HostInterval, "text/plain", func(resp *http.Response) error {
+		switch {
+		case resp.StatusCode >= 200 && resp.StatusCode < 300:
+			rules = parseRobots(resp.Body, f.polite.RobotsAgent)
+		case resp.StatusCode >= 400 && resp.StatusCode < 500:
+			rules = allowAll
+		default:
+			rules = disallowAll
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load robots.txt of %s: %w", u.Host, err)
+	}
+
+	ttl := f.polite.RobotsTTL
+	if rules == disallowAll {
+		ttl = min(ttl, robotsErrorTTL)
+	}
+	f.robots.put(origin, rules, now.Add(ttl))
+	return rules, nil
+}
+
+// get sends a GET to u once the host's turn comes and hands the response to read. A
+// 429 or 503 holds back the host's requests for Retry-After or the backoff.
+func (f *HTTPFetcher) get(ctx context.Context, u *neturl.URL, interval time.Duration, accept string, read func(resp *http.Response) error) error {
+	host := hostLabel(u)
+	if f.limiter != nil {
+		release, err := f.acquire(ctx, host, interval)
+		if err != nil {
+			return err
+		}
+		defer release()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("User-Agent", f.userAgent)
+	req.Header.Set("Accept", accept)
+
+	start := time.Now()
+	resp, err := f.client.Do(req)
+	fetchDuration.WithLabelValues(host).Observe(time.Since(start).Seconds())
+	if err != nil {
+		fetchRequests.WithLabelValues(host, "error").Inc()
+		return fmt.Errorf("failed to fetch %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+	fetchRequests.WithLabelValues(host, strconv.Itoa(resp.StatusCode)).Inc()
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		d := f.backoff(ctx, host, resp.Header.Get("Retry-After"))
+		fetchSkipped.WithLabelValues(host, "backoff").Inc()
+		return fmt.Errorf("%w: %s answered %d, backing off for %s", domain.ErrHostThrottled, host, resp.StatusCode, d)
+	}
+
+	return read(resp)
+}
+
+// acquire waits up to MaxWait for the host's turn
+func (f *HTTPFetcher) acquire(ctx context.Context, host string, interval time.Duration) (func(), error) {
+	if f.polite.MaxWait > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, f.polite.MaxWait)
+		defer cancel()
+	}
+
+	start := time.Now()
+	release, err := f.limiter.Acquire(ctx, host, interval)
+	fetchWait.WithLabelValues(host).Observe(time.Since(start).Seconds())
+	if errors.Is(err, domain.ErrHostThrottled) {
+		fetchSkipped.WithLabelValues(host, "rate_limit").Inc()
+	}
+	return release, err
+}
+
+// backoff holds back the host's requests and returns for how long
+func (f *HTTPFetcher) backoff(ctx context.Context, host, header string) time.Duration {
+	d := retryAfter(header, time.Now())
+	if d <= 0 {
+		d = f.polite.Backoff
+	}
+	if f.polite.MaxBackoff > 0 {
+		d = min(d, f.polite.MaxBackoff)
+	}
+	if f.limiter != nil && d > 0 {
+		// Without the block the next check of the host asks again, nothing worse
+		_ = f.limiter.Block(ctx, host, d)
+	}
+	return d
+}
+
+// retryAfter reads Retry-After in seconds or as an HTTP date, 0 when it has neither
+func retryAfter(header string, now time.Time) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		return time.Duration(max(seconds, 0)) * time.Second
+	}
+	if at, err := http.ParseTime(header); err == nil {
+		return max(at.Sub(now), 0)
+	}
+	return 0
+}
+
+// hostLabel names a host for the limiter and the metrics
+func hostLabel(u *neturl.URL) string {
+	return strings.ToLower(u.Host)
+}
+
+// extractGeneric is a best-effort heuristic for pages we know nothing about.
+// It looks at elements whose class or id mentions "price".
+func extractGeneric(doc *goquery.Document) (*domain.PriceInfo, bool) {
+	info := &domain.PriceInfo{InStock: true, Source: SourceHeuristics}
+
+	doc.Find(`[data-price], [class*="price"], [id*="price"]`).EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		text, ok := s.Attr("data-price")
+		if !ok {
+			text = s.Text()
+		}
+
+		price, currency, ok := ParsePrice(text)
+		if !ok {
+			return true
+		}
+
+		info.Price = price
+		info.Currency = currency
+		return false
+	})
+
+	stockText := doc.Find(`[class*="stock"], [class*="availability"], [id*="availability"]`).Text()
+	info.InStock = !containsOutOfStockPhrase(stockText)
+
+	return info, info.Price > 0 || !info.InStock
+}
+
+func extractWithRule(doc *goquery.Document, rule *compiledRule) (*domain.PriceInfo, bool) {
+	info := &domain.PriceInfo{InStock: true, Source: SourceRule}
+
+	if price, currency, ok := ParsePrice(rule.price.value(doc)); ok {
+		info.Price = price
+		info.Currency = currency
+	}
+	if info.Currency == "" {
+		info.Currency = rule.Currency
+	}
+
+	if rule.title != nil {
+		info.Title = rule.title.value(doc)
+	}
+
+	if rule.stock != nil {
+		stock := rule.stock.value(doc)
+		if rule.outOfStock != nil {
+			info.InStock = !rule.outOfStock.MatchString(stock)
+		} else {
+			info.InStock = !containsOutOfStockPhrase(stock)
+		}
+	}
+
+	return info, info.Price > 0 || !info.InStock
+}
+
+// pageTitle falls back to the first heading, then to the document title
+func pageTitle(doc *goquery.Document) string {
+	if title := cleanText(doc.Find("h1").First().Text()); title != "" {
+		return title
+	}
+	return cleanText(doc.Find("title").First().Text())
+}
+
+func containsOutOfStockPhrase(text string) bool {
+	text = strings.ToLower(text)
+	for _, phrase := range outOfStockPhrases {
+		if strings.Contains(text, phrase) {
+			return true
+		}
+	}
+	return false
+}
+
+func cleanText(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,188 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/derkres11/price-pulse/internal/domain"
+)
+
+func newFixtureServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.Handle("/pages/", http.StripPrefix("/pages/", http.FileServer(http.Dir("testdata"))))
+	mux.HandleFunc("/broken", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestHTTPFetcher_Fetch(t *testing.T) {
+	srv := newFixtureServer(t)
+	f := NewHTTPFetcher(5*time.Second, "", nil, nil, Politeness{})
+
+	tests := []struct {
+		name         string
+		path         string
+		wantPrice    float64
+		wantCurrency string
+		wantTitle    string
+		wantInStock  bool
+		wantErr      error
+	}{
+		{"Generic page", "/pages/generic.html", 1299.99, "USD", "Wireless Headphones", true, nil},
+		{"Out of stock", "/pages/out_of_stock.html", 0, "", "Coffee Grinder", false, nil},
+		{"No price", "/pages/no_price.html", 0, "", "", false, ErrPriceNotFound},
+		{"JSON-LD wins over page text", "/pages/jsonld.html", 349.9, "EUR", "Espresso Machine X200", true, nil},
+		{"Microdata", "/pages/microdata.html", 89, "PLN", "Trail Running Shoes", false, nil},
+		{"OpenGraph", "/pages/opengraph.html", 24.99, "GBP", "LED Desk Lamp", true, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := f.Fetch(context.Background(), srv.URL+tt.path)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected error %v, got: %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if info.Price != tt.wantPrice || info.Currency != tt.wantCurrency {
+				t.Errorf("expected %.2f %s, got %.2f %s", tt.wantPrice, tt.wantCurrency, info.Price, info.Currency)
+			}
+			if info.Title != tt.wantTitle {
+				t.Errorf("expected title %q, got %q", tt.wantTitle, info.Title)
+			}
+			if info.InStock != tt.wantInStock {
+				t.Errorf("expected in stock %v, got %v", tt.wantInStock, info.InStock)
+			}
+		})
+	}
+
+	t.Run("Bad status", func(t *testing.T) {
+		if _, err := f.Fetch(context.Background(), srv.URL+"/broken"); err == nil {
+			t.Error("expected error for 503 response")
+		}
+	})
+}
+
+// limiterMock records what the fetcher asks of the host limiter
+type limiterMock struct {
+	mu        sync.Mutex
+	err       error
+	intervals []time.Duration
+	blocked   map[string]time.Duration
+}
+
+func (m *limiterMock) Acquire(ctx context.Context, host string, interval time.Duration) (func(), error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return nil, m.err
+	}
+	m.intervals = append(m.intervals, interval)
+	return func() {}, nil
+}
+
+func (m *limiterMock) Block(ctx context.Context, host string, d time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.blocked[host] = d
+	return nil
+}
+
+func TestHTTPFetcher_Politeness(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "User-agent: *\nDisallow: /private\nCrawl-delay: 5\n")
+	})
+	mux.Handle("/pages/", http.StripPrefix("/pages/", http.FileServer(http.Dir("testdata"))))
+	mux.HandleFunc("/busy", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	host := strings.TrimPrefix(srv.URL, "http://")
+	polite := Politeness{HostInterval: time.Second, Backoff: time.Minute, MaxBackoff: time.Hour, RobotsTTL: time.Hour}
+
+	t.Run("Crawl delay paces the host", func(t *testing.T) {
+		limiter := &limiterMock{blocked: map[string]time.Duration{}}
+		f := NewHTTPFetcher(5*time.Second, "", nil, limiter, polite)
+		if _, err := f.Fetch(context.Background(), srv.URL+"/pages/generic.html"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		// robots.txt itself is fetched before its crawl delay is known
+		if len(limiter.intervals) != 2 || limiter.intervals[0] != time.Second || limiter.intervals[1] != 5*time.Second {
+			t.Errorf("intervals = %v, want [1s 5s]", limiter.intervals)
+		}
+	})
+
+	t.Run("Disallowed", func(t *testing.T) {
+		f := NewHTTPFetcher(5*time.Second, "", nil, &limiterMock{blocked: map[string]time.Duration{}}, polite)
+		if _, err := f.Fetch(context.Background(), srv.URL+"/private/1"); !errors.Is(err, domain.ErrFetchDisallowed) {
+			t.Errorf("expected ErrFetchDisallowed, got: %v", err)
+		}
+	})
+
+	t.Run("Retry-After blocks the host", func(t *testing.T) {
+		limiter := &limiterMock{blocked: map[string]time.Duration{}}
+		f := NewHTTPFetcher(5*time.Second, "", nil, limiter, polite)
+		if _, err := f.Fetch(context.Background(), srv.URL+"/busy"); !errors.Is(err, domain.ErrHostThrottled) {
+			t.Errorf("expected ErrHostThrottled, got: %v", err)
+		}
+		if d := limiter.blocked[host]; d != 2*time.Minute {
+			t.Errorf("host blocked for %s, want 2m", d)
+		}
+	})
+
+	t.Run("Throttled by the limiter", func(t *testing.T) {
+		limiter := &limiterMock{err: fmt.Errorf("%w: %s", domain.ErrHostThrottled, host)}
+		f := NewHTTPFetcher(5*time.Second, "", nil, limiter, polite)
+		if _, err := f.Fetch(context.Background(), srv.URL+"/pages/generic.html"); !errors.Is(err, domain.ErrHostThrottled) {
+			t.Errorf("expected ErrHostThrottled, got: %v", err)
+		}
+	})
+}
+
+func TestParsePrice(t *testing.T) {
+	tests := []struct {
+		text         string
+		wantPrice    float64
+		wantCurrency string
+		wantOK       bool
+	}{
+		{"$1,299.99", 1299.99, "USD", true},
+		{"1 299,00 ₽", 1299, "RUB", true},
+		{"€12,50", 12.5, "EUR", true},
+		{"EUR 1.000.000", 1000000, "EUR", true},
+		{"Price: 49.90 zł", 49.9, "PLN", true},
+		{"1,299", 1299, "", true},
+		{"free", 0, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			price, currency, ok := ParsePrice(tt.text)
+			if ok != tt.wantOK || price != tt.wantPrice || currency != tt.wantCurrency {
+				t.Errorf("ParsePrice(%q) = %v, %q, %v; want %v, %q, %v",
+					tt.text, price, currency, ok, tt.wantPrice, tt.wantCurrency, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package fetcher
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	fetchRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "pricepulse_fetch_requests_total",
+		Help: "Requests sent to shops by host and status code, \"error\" when there was no response.",
+	}, []string{"host", "code"})
+
+	fetchDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "pricepulse_fetch_duration_seconds",
+		Help: "Time a shop took to answer a request.",
+	}, []string{"host"})
+
+	fetchWait = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "pricepulse_fetch_wait_seconds",
+		Help:    "Time a request waited for its turn at the host.",
+		Buckets: []float64{0.01, 0.1, 0.5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"host"})
+
+	fetchSkipped = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "pricepulse_fetch_skipped_total",
+		Help: "Fetches not made by host and reason: robots, rate_limit or backoff.",
+	}, []string{"host", "reason"})
+)
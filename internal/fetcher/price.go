@@ -0,0 +1,106 @@
+package fetcher
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var numberRe = regexp.MustCompile(`\d[\d\s\x{00A0}\x{202F},.'’]*`)
+
+// currencySymbols maps symbols and local spellings to ISO 4217 codes.
+// Multi-character entries go first so "zł" wins over a bare letter match.
+var currencySymbols = []struct {
+	symbol string
+	code   string
+}{
+	{"руб", "RUB"},
+	{"zł", "PLN"},
+	{"US$", "USD"},
+	{"$", "USD"},
+	{"€", "EUR"},
+	{"£", "GBP"},
+	{"₽", "RUB"},
+	{"¥", "JPY"},
+	{"₴", "UAH"},
+	{"₸", "KZT"},
+	{"₹", "INR"},
+}
+
+var currencyCodes = []string{"USD", "EUR", "GBP", "RUB", "JPY", "CNY", "UAH", "KZT", "PLN", "INR", "CHF", "CAD", "AUD"}
+
+// ParsePrice extracts a number and currency from a human formatted price
+// like "$1,299.99", "1 299,00 ₽" or "EUR 12.50".
+// Currency is empty when the text does not mention one.
+func ParsePrice(text string) (float64, string, bool) {
+	raw := numberRe.FindString(text)
+	if raw == "" {
+		return 0, "", false
+	}
+
+	price, err := strconv.ParseFloat(normalizeNumber(raw), 64)
+	if err != nil || price <= 0 {
+		return 0, "", false
+	}
+
+	return price, detectCurrency(text), true
+}
+
+// normalizeNumber turns a localized number into the form strconv understands.
+func normalizeNumber(raw string) string {
+	s := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\u00a0', '\u202f', '\'', '’', '\t', '\n':
+			return -1
+		}
+		return r
+	}, raw)
+	s = strings.TrimRight(s, ".,")
+
+	lastDot := strings.LastIndex(s, ".")
+	lastComma := strings.LastIndex(s, ",")
+
+	switch {
+	case lastDot >= 0 && lastComma >= 0:
+		// Both present: whichever comes last is the decimal separator
+		if lastComma > lastDot {
+			s = strings.ReplaceAll(s, ".", "")
+			s = strings.Replace(s, ",", ".", 1)
+		} else {
+			s = strings.ReplaceAll(s, ",", "")
+		}
+	case lastComma >= 0:
+		s = normalizeSingleSeparator(s, ",")
+	case lastDot >= 0:
+		s = normalizeSingleSeparator(s, ".")
+	}
+
+	return s
+}
+
+// normalizeSingleSeparator decides whether sep is used for thousands or decimals.
+// Repeated separators or exactly three trailing digits mean thousands ("1,299", "1.000.000").
+func normalizeSingleSeparator(s, sep string) string {
+	last := strings.LastIndex(s, sep)
+	if strings.Count(s, sep) > 1 || len(s)-last-1 == 3 {
+		return strings.ReplaceAll(s, sep, "")
+	}
+	return strings.Replace(s, sep, ".", 1)
+}
+
+func detectCurrency(text string) string {
+	for _, code := range currencyCodes {
+		if strings.Contains(text, code) {
+			return code
+		}
+	}
+
+	lower := strings.ToLower(text)
+	for _, c := range currencySymbols {
+		if strings.Contains(lower, strings.ToLower(c.symbol)) {
+			return c.code
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,165 @@
+package fetcher
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const maxRobotsSize = 500 << 10 // RFC 9309 asks crawlers to read at least 500 KiB
+
+// robotsRules are the rules of robots.txt that apply to our agent
+type robotsRules struct {
+	rules      []robotsRule
+	crawlDelay time.Duration
+}
+
+type robotsRule struct {
+	allow   bool
+	pattern string
+}
+
+var (
+	allowAll    = &robotsRules{}
+	disallowAll = &robotsRules{rules: []robotsRule{{allow: false, pattern: "/"}}}
+)
+
+// parseRobots reads the groups for agent, or for "*" when none names it. Groups for
+// the same agent are merged, unknown lines are ignored.
+func parseRobots(r io.Reader, agent string) *robotsRules {
+	agent = strings.ToLower(agent)
+	var (
+		own, star      robotsRules
+		ownFound       bool
+		forOwn, forAny bool // the current group applies to agent or "*"
+		inRules        bool // the current group's user-agent lines are over
+		scanner        = bufio.NewScanner(io.LimitReader(r, maxRobotsSize))
+	)
+
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		if key == "user-agent" {
+			if inRules {
+				forOwn, forAny, inRules = false, false, false
+			}
+			switch ua := strings.ToLower(value); {
+			case ua == "*":
+				forAny = true
+			case ua == agent:
+				forOwn, ownFound = true, true
+			}
+			continue
+		}
+
+		inRules = true
+		if forOwn {
+			own.add(key, value)
+		}
+		if forAny {
+			star.add(key, value)
+		}
+	}
+
+	if ownFound {
+		return &own
+	}
+	return &star
+}
+
+func (r *robotsRules) add(key, value string) {
+	switch key {
+	case "allow", "disallow":
+		// An empty Disallow allows everything, which is what no rule does as well
+		if value != "" {
+			r.rules = append(r.rules, robotsRule{allow: key == "allow", pattern: value})
+		}
+	case "crawl-delay":
+		if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+			r.crawlDelay = time.Duration(seconds * float64(time.Second))
+		}
+	}
+}
+
+// allowed applies the longest matching rule to path, Allow wins a tie
+func (r *robotsRules) allowed(path string) bool {
+	allow, longest := true, -1
+	for _, rule := range r.rules {
+		if !robotsMatch(rule.pattern, path) {
+			continue
+		}
+		if n := len(rule.pattern); n > longest || (n == longest && rule.allow) {
+			allow, longest = rule.allow, n
+		}
+	}
+	return allow
+}
+
+// robotsMatch matches path against a pattern with "*" for any characters and a
+// trailing "$" for the end of the path
+func robotsMatch(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+
+	parts := strings.Split(pattern, "*")
+	rest, ok := strings.CutPrefix(path, parts[0])
+	if !ok {
+		return false
+	}
+	if len(parts) == 1 {
+		return !anchored || rest == ""
+	}
+
+	// The leftmost match of every middle part leaves the most room for the rest
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(rest, part)
+		if i < 0 {
+			return false
+		}
+		rest = rest[i+len(part):]
+	}
+	last := parts[len(parts)-1]
+	if anchored {
+		return strings.HasSuffix(rest, last)
+	}
+	return strings.Contains(rest, last)
+}
+
+// robotsCache keeps the rules of every host for a while, per replica
+type robotsCache struct {
+	mu      sync.Mutex
+	entries map[string]robotsEntry // by scheme and host
+}
+
+type robotsEntry struct {
+	rules   *robotsRules
+	expires time.Time
+}
+
+func (c *robotsCache) get(origin string, now time.Time) (*robotsRules, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[origin]
+	if !ok || now.After(e.expires) {
+		return nil, false
+	}
+	return e.rules, true
+}
+
+func (c *robotsCache) put(origin string, rules *robotsRules, expires time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[string]robotsEntry)
+	}
+	c.entries[origin] = robotsEntry{rules: rules, expires: expires}
+}
@@ -0,0 +1,102 @@
+package fetcher
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const robotsFixture = `# shop robots
+User-agent: *
+Disallow: /cart
+Disallow: /search
+Allow: /search/about$
+
+User-agent: BadBot
+User-agent: PricePulse
+Disallow: /private/
+Allow: /private/*/public
+Disallow: /*.pdf$
+Crawl-delay: 2.5
+
+Sitemap: https://shop.example/sitemap.xml
+`
+
+func TestParseRobots(t *testing.T) {
+	tests := []struct {
+		name  string
+		agent string
+		path  string
+		want  bool
+	}{
+		{"Own group ignores star", "PricePulse", "/cart", true},
+		{"Own disallow", "pricepulse", "/private/orders", false},
+		{"Longer allow wins", "PricePulse", "/private/a/public/1", true},
+		{"Anchored pattern", "PricePulse", "/docs/manual.pdf", false},
+		{"Anchored pattern with query", "PricePulse", "/docs/manual.pdf?v=2", true},
+		{"Star group", "OtherBot", "/cart/1", false},
+		{"Star allow anchored", "OtherBot", "/search/about", true},
+		{"Star disallow past anchor", "OtherBot", "/search/about/more", false},
+		{"No rule", "OtherBot", "/products/1", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := parseRobots(strings.NewReader(robotsFixture), tt.agent)
+			if got := rules.allowed(tt.path); got != tt.want {
+				t.Errorf("allowed(%q) for %s = %v, want %v", tt.path, tt.agent, got, tt.want)
+			}
+		})
+	}
+
+	if d := parseRobots(strings.NewReader(robotsFixture), "PricePulse").crawlDelay; d != 2500*time.Millisecond {
+		t.Errorf("crawl delay = %s, want 2.5s", d)
+	}
+	if d := parseRobots(strings.NewReader(robotsFixture), "OtherBot").crawlDelay; d != 0 {
+		t.Errorf("crawl delay of the star group = %s, want none", d)
+	}
+}
+
+func TestRobotsMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"/", "/anything", true},
+		{"/shop", "/shopping", true},
+		{"/shop$", "/shopping", false},
+		{"/*/item", "/a/b/item/1", true},
+		{"/*/item$", "/a/item/b/item", true},
+		{"/*/item$", "/a/item/b", false},
+		{"/a*b*c", "/a-c-b", false},
+		{"*.php", "/index.php?x=1", true},
+	}
+
+	for _, tt := range tests {
+		if got := robotsMatch(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("robotsMatch(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		header string
+		want   time.Duration
+	}{
+		{"", 0},
+		{"120", 2 * time.Minute},
+		{"Sun, 10 Mar 2024 12:30:00 GMT", 30 * time.Minute},
+		{"Sun, 10 Mar 2024 11:00:00 GMT", 0},
+		{"soon", 0},
+	}
+
+	for _, tt := range tests {
+		if got := retryAfter(tt.header, now); got != tt.want {
+			t.Errorf("retryAfter(%q) = %s, want %s", tt.header, got, tt.want)
+		}
+	}
+}
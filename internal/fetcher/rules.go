@@ -0,0 +1,289 @@
+package fetcher
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/andybalholm/cascadia"
+	"github.com/antchfx/htmlquery"
+	"github.com/antchfx/xpath"
+	"gopkg.in/yaml.v3"
+)
+
+// Selector points at a single value on the page.
+// Exactly one of CSS or XPath must be set. Attr reads an attribute instead of the text,
+// Regex cleans the value up: the first capture group is used if there is one, else the whole match.
+type Selector struct {
+	CSS   string `yaml:"css" json:"css"`
+	XPath string `yaml:"xpath" json:"xpath"`
+	Attr  string `yaml:"attr" json:"attr"`
+	Regex string `yaml:"regex" json:"regex"`
+}
+
+// Rule describes how to scrape one retailer
+type Rule struct {
+	Host       string    `yaml:"host" json:"host"`
+	Price      Selector  `yaml:"price" json:"price"`
+	Title      *Selector `yaml:"title" json:"title"`
+	Stock      *Selector `yaml:"stock" json:"stock"`
+	OutOfStock string    `yaml:"out_of_stock" json:"out_of_stock"` // regex matched against the stock value
+	Currency   string    `yaml:"currency" json:"currency"`         // used when the price text has no currency
+}
+
+type rulesFile struct {
+	Rules []Rule `yaml:"rules" json:"rules"`
+}
+
+type compiledSelector struct {
+	css   cascadia.Selector
+	xpath *xpath.Expr
+	attr  string
+	regex *regexp.Regexp
+}
+
+type compiledRule struct {
+	Rule
+	price      *compiledSelector
+	title      *compiledSelector
+	stock      *compiledSelector
+	outOfStock *regexp.Regexp
+}
+
+// RuleRegistry holds the extraction rules keyed by host.
+// It is safe for concurrent use, Reload swaps the whole set at once.
+type RuleRegistry struct {
+	mu    sync.RWMutex
+	path  string
+	rules map[string]*compiledRule
+}
+
+// NewRuleRegistry loads and validates rules from a YAML or JSON file.
+// An empty path gives an empty registry so every host uses the generic extractors.
+func NewRuleRegistry(path string) (*RuleRegistry, error) {
+	r := &RuleRegistry{path: path, rules: map[string]*compiledRule{}}
+	if path == "" {
+		return r, nil
+	}
+
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload re-reads the rules file. On any error the current rules stay in place.
+func (r *RuleRegistry) Reload() error {
+	if r.path == "" {
+		return errors.New("no rules file configured")
+	}
+
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		return fmt.Errorf("failed to read rules file: %w", err)
+	}
+
+	var file rulesFile
+	switch strings.ToLower(filepath.Ext(r.path)) {
+	case ".json":
+		err = json.Unmarshal(data, &file)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &file)
+	default:
+		return fmt.Errorf("unsupported rules file format %q", filepath.Ext(r.path))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to decode rules file: %w", err)
+	}
+
+	rules, err := compileRules(file.Rules)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.rules = rules
+	r.mu.Unlock()
+
+	return nil
+}
+
+// Hosts returns the hosts that currently have a rule, sorted
+func (r *RuleRegistry) Hosts() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	hosts := make([]string, 0, len(r.rules))
+	for h := range r.rules {
+		hosts = append(hosts, h)
+	}
+	sort.Strings(hosts)
+	return hosts
+}
+
+// match finds the rule for host, trying parent domains so a rule for
+// "shop.com" also covers "www.shop.com" and "m.shop.com".
+func (r *RuleRegistry) match(host string) *compiledRule {
+	if r == nil {
+		return nil
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	host = strings.ToLower(host)
+	for {
+		if rule, ok := r.rules[host]; ok {
+			return rule
+		}
+
+		dot := strings.IndexByte(host, '.')
+		if dot < 0 {
+			return nil
+		}
+		host = host[dot+1:]
+	}
+}
+
+func compileRules(rules []Rule) (map[string]*compiledRule, error) {
+	compiled := make(map[string]*compiledRule, len(rules))
+	seen := make(map[string]bool, len(rules))
+	var errs []error
+
+	for i, rule := range rules {
+		host := strings.ToLower(strings.TrimSpace(rule.Host))
+		if host == "" {
+			errs = append(errs, fmt.Errorf("rule #%d: host is required", i+1))
+			continue
+		}
+		if seen[host] {
+			errs = append(errs, fmt.Errorf("rule %s: duplicate host", host))
+			continue
+		}
+		seen[host] = true
+
+		c, err := compileRule(rule)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("rule %s: %w", host, err))
+			continue
+		}
+		c.Host = host
+		compiled[host] = c
+	}
+
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid extraction rules: %w", errors.Join(errs...))
+	}
+	return compiled, nil
+}
+
+func compileRule(rule Rule) (*compiledRule, error) {
+	c := &compiledRule{Rule: rule}
+
+	var err error
+	if c.price, err = compileSelector(&rule.Price); err != nil {
+		return nil, fmt.Errorf("price: %w", err)
+	}
+	if rule.Title != nil {
+		if c.title, err = compileSelector(rule.Title); err != nil {
+			return nil, fmt.Errorf("title: %w", err)
+		}
+	}
+	if rule.Stock != nil {
+		if c.stock, err = compileSelector(rule.Stock); err != nil {
+			return nil, fmt.Errorf("stock: %w", err)
+		}
+	}
+	if rule.OutOfStock != "" {
+		if c.outOfStock, err = regexp.Compile("(?i)" + rule.OutOfStock); err != nil {
+			return nil, fmt.Errorf("out_of_stock: %w", err)
+		}
+	}
+
+	return c, nil
+}
+
+func compileSelector(s *Selector) (*compiledSelector, error) {
+	c := &compiledSelector{attr: s.Attr}
+
+	switch {
+	case s.CSS != "" && s.XPath != "":
+		return nil, errors.New("set either css or xpath, not both")
+	case s.CSS != "":
+		sel, err := cascadia.Compile(s.CSS)
+		if err != nil {
+			return nil, fmt.Errorf("bad css selector %q: %w", s.CSS, err)
+		}
+		c.css = sel
+	case s.XPath != "":
+		expr, err := xpath.Compile(s.XPath)
+		if err != nil {
+			return nil, fmt.Errorf("bad xpath %q: %w", s.XPath, err)
+		}
+		c.xpath = expr
+	default:
+		return nil, errors.New("css or xpath is required")
+	}
+
+	if s.Regex != "" {
+		re, err := regexp.Compile(s.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("bad regex %q: %w", s.Regex, err)
+		}
+		c.regex = re
+	}
+
+	return c, nil
+}
+
+// value returns the cleaned up value the selector points at, or "" if nothing matched
+func (s *compiledSelector) value(doc *goquery.Document) string {
+	var raw string
+
+	if s.css != nil {
+		sel := doc.FindMatcher(s.css).First()
+		if sel.Length() == 0 {
+			return ""
+		}
+		if s.attr != "" {
+			raw, _ = sel.Attr(s.attr)
+		} else {
+			raw = sel.Text()
+		}
+	} else {
+		if len(doc.Nodes) == 0 {
+			return ""
+		}
+		node := htmlquery.QuerySelector(doc.Nodes[0], s.xpath)
+		if node == nil {
+			return ""
+		}
+		if s.attr != "" {
+			raw = htmlquery.SelectAttr(node, s.attr)
+		} else {
+			raw = htmlquery.InnerText(node)
+		}
+	}
+
+	raw = cleanText(raw)
+	if s.regex == nil {
+		return raw
+	}
+
+	m := s.regex.FindStringSubmatch(raw)
+	switch {
+	case m == nil:
+		return ""
+	case len(m) > 1:
+		return m[1]
+	default:
+		return m[0]
+	}
+}
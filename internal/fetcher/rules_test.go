@@ -0,0 +1,87 @@
+package fetcher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRuleRegistry_Fetch(t *testing.T) {
+	srv := newFixtureServer(t)
+
+	rules, err := NewRuleRegistry("testdata/rules.yaml")
+	if err != nil {
+		t.Fatalf("failed to load rules: %v", err)
+	}
+
+	f := NewHTTPFetcher(5*time.Second, "", rules, nil, Politeness{})
+	info, err := f.Fetch(context.Background(), srv.URL+"/pages/retailer.html")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if info.Price != 149 || info.Currency != "EUR" {
+		t.Errorf("expected 149.00 EUR, got %.2f %s", info.Price, info.Currency)
+	}
+	if info.Title != "Mechanical Keyboard" {
+		t.Errorf("expected title from xpath, got %q", info.Title)
+	}
+	if !info.InStock {
+		t.Error("expected product to be in stock")
+	}
+}
+
+func TestRuleRegistry_Validation(t *testing.T) {
+	_, err := NewRuleRegistry("testdata/rules_invalid.yaml")
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
+
+	for _, want := range []string{"bad css selector", "duplicate host", "host is required"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error to mention %q, got: %v", want, err)
+		}
+	}
+}
+
+func TestRuleRegistry_Reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.json")
+	write := func(content string) {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(`{"rules": [{"host": "shop.com", "price": {"css": ".price"}}]}`)
+	rules, err := NewRuleRegistry(path)
+	if err != nil {
+		t.Fatalf("failed to load rules: %v", err)
+	}
+
+	if rules.match("www.shop.com") == nil {
+		t.Error("expected subdomain to match parent rule")
+	}
+	if rules.match("othershop.com") != nil {
+		t.Error("expected unrelated host not to match")
+	}
+
+	// A broken file must not wipe the rules we already have
+	write(`{"rules": [{"host": "shop.com"}]}`)
+	if err := rules.Reload(); err == nil {
+		t.Error("expected reload to fail")
+	}
+	if got := rules.Hosts(); len(got) != 1 || got[0] != "shop.com" {
+		t.Errorf("expected old rules to survive, got %v", got)
+	}
+
+	write(`{"rules": [{"host": "a.com", "price": {"xpath": "//b"}}, {"host": "B.com", "price": {"css": "b"}}]}`)
+	if err := rules.Reload(); err != nil {
+		t.Fatalf("unexpected reload error: %v", err)
+	}
+	if got := rules.Hosts(); len(got) != 2 || got[0] != "a.com" || got[1] != "b.com" {
+		t.Errorf("expected reloaded hosts, got %v", got)
+	}
+}
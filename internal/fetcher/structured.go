@@ -0,0 +1,250 @@
+package fetcher
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/derkres11/price-pulse/internal/domain"
+)
+
+// extractStructured reads machine readable product data that most shops embed for
+// search engines. Sources are tried in order: JSON-LD, microdata, OpenGraph.
+func extractStructured(doc *goquery.Document) (*domain.PriceInfo, bool) {
+	for _, extract := range []func(*goquery.Document) (*domain.PriceInfo, bool){
+		extractJSONLD,
+		extractMicrodata,
+		extractOpenGraph,
+	} {
+		if info, ok := extract(doc); ok {
+			return info, true
+		}
+	}
+	return nil, false
+}
+
+// --- JSON-LD ---
+
+func extractJSONLD(doc *goquery.Document) (*domain.PriceInfo, bool) {
+	var found *domain.PriceInfo
+
+	doc.Find(`script[type="application/ld+json"]`).EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		var data any
+		if err := json.Unmarshal([]byte(s.Text()), &data); err != nil {
+			return true // broken blocks are common, just skip them
+		}
+
+		product := findLDProduct(data)
+		if product == nil {
+			return true
+		}
+
+		if info, ok := ldProductInfo(product); ok {
+			found = info
+			return false
+		}
+		return true
+	})
+
+	return found, found != nil
+}
+
+// findLDProduct walks arrays and @graph containers looking for a schema.org Product
+func findLDProduct(data any) map[string]any {
+	switch v := data.(type) {
+	case []any:
+		for _, item := range v {
+			if p := findLDProduct(item); p != nil {
+				return p
+			}
+		}
+	case map[string]any:
+		if ldHasType(v, "Product") {
+			return v
+		}
+		if graph, ok := v["@graph"]; ok {
+			return findLDProduct(graph)
+		}
+	}
+	return nil
+}
+
+func ldHasType(node map[string]any, want string) bool {
+	switch t := node["@type"].(type) {
+	case string:
+		return strings.EqualFold(t, want)
+	case []any:
+		for _, item := range t {
+			if s, ok := item.(string); ok && strings.EqualFold(s, want) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func ldProductInfo(product map[string]any) (*domain.PriceInfo, bool) {
+	info := &domain.PriceInfo{
+		Title:   cleanText(ldString(product["name"])),
+		InStock: true,
+		Source:  SourceJSONLD,
+	}
+
+	offer := firstLDOffer(product["offers"])
+	if offer == nil {
+		return nil, false
+	}
+
+	price := ldString(offer["price"])
+	if price == "" {
+		price = ldString(offer["lowPrice"]) // AggregateOffer
+	}
+	if p, currency, ok := parseMachinePrice(price); ok {
+		info.Price = p
+		info.Currency = currency
+	}
+	if c := ldString(offer["priceCurrency"]); c != "" {
+		info.Currency = strings.ToUpper(c)
+	}
+	if a := ldString(offer["availability"]); a != "" {
+		info.InStock = isInStock(a)
+	}
+
+	return info, info.Price > 0 || !info.InStock
+}
+
+func firstLDOffer(offers any) map[string]any {
+	switch v := offers.(type) {
+	case map[string]any:
+		// AggregateOffer may nest the real offers
+		if nested, ok := v["offers"]; ok && v["price"] == nil && v["lowPrice"] == nil {
+			return firstLDOffer(nested)
+		}
+		return v
+	case []any:
+		for _, item := range v {
+			if o := firstLDOffer(item); o != nil {
+				return o
+			}
+		}
+	}
+	return nil
+}
+
+// ldString flattens the scalar forms JSON-LD values come in
+func ldString(v any) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case map[string]any:
+		if id, ok := t["@id"].(string); ok {
+			return id
+		}
+	}
+	return ""
+}
+
+// --- Microdata ---
+
+func extractMicrodata(doc *goquery.Document) (*domain.PriceInfo, bool) {
+	scope := doc.Find(`[itemtype*="schema.org/Product"]`).First()
+	if scope.Length() == 0 {
+		return nil, false
+	}
+
+	info := &domain.PriceInfo{
+		Title:   cleanText(scope.Find(`[itemprop="name"]`).First().Text()),
+		InStock: true,
+		Source:  SourceMicrodata,
+	}
+
+	if p, currency, ok := parseMachinePrice(itemValue(scope.Find(`[itemprop="price"]`).First())); ok {
+		info.Price = p
+		info.Currency = currency
+	}
+	if c := itemValue(scope.Find(`[itemprop="priceCurrency"]`).First()); c != "" {
+		info.Currency = strings.ToUpper(c)
+	}
+	if a := itemValue(scope.Find(`[itemprop="availability"]`).First()); a != "" {
+		info.InStock = isInStock(a)
+	}
+
+	return info, info.Price > 0 || !info.InStock
+}
+
+// itemValue follows the microdata rules: content, then href, then text
+func itemValue(s *goquery.Selection) string {
+	if s.Length() == 0 {
+		return ""
+	}
+	if v, ok := s.Attr("content"); ok {
+		return strings.TrimSpace(v)
+	}
+	if v, ok := s.Attr("href"); ok {
+		return strings.TrimSpace(v)
+	}
+	return cleanText(s.Text())
+}
+
+// --- OpenGraph ---
+
+func extractOpenGraph(doc *goquery.Document) (*domain.PriceInfo, bool) {
+	info := &domain.PriceInfo{
+		Title:   metaContent(doc, "og:title"),
+		InStock: true,
+		Source:  SourceOpenGraph,
+	}
+
+	price := metaContent(doc, "product:price:amount", "og:price:amount")
+	if p, currency, ok := parseMachinePrice(price); ok {
+		info.Price = p
+		info.Currency = currency
+	}
+	if c := metaContent(doc, "product:price:currency", "og:price:currency"); c != "" {
+		info.Currency = strings.ToUpper(c)
+	}
+	if a := metaContent(doc, "product:availability", "og:availability"); a != "" {
+		info.InStock = isInStock(a)
+	}
+
+	return info, info.Price > 0
+}
+
+func metaContent(doc *goquery.Document, properties ...string) string {
+	for _, prop := range properties {
+		sel := doc.Find(fmt.Sprintf(`meta[property="%s"], meta[name="%s"]`, prop, prop)).First()
+		if v, ok := sel.Attr("content"); ok && strings.TrimSpace(v) != "" {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
+
+// parseMachinePrice prefers the plain "1299.00" form structured data is supposed to use
+// and only falls back to the localized parser for shops that put display text there.
+func parseMachinePrice(s string) (float64, string, bool) {
+	if p, err := strconv.ParseFloat(strings.TrimSpace(s), 64); err == nil && p > 0 {
+		return p, "", true
+	}
+	return ParsePrice(s)
+}
+
+// isInStock understands schema.org URLs ("https://schema.org/OutOfStock")
+// as well as the free-form OpenGraph values ("instock", "oos").
+func isInStock(availability string) bool {
+	a := strings.ToLower(availability)
+	if i := strings.LastIndexByte(a, '/'); i >= 0 {
+		a = a[i+1:]
+	}
+	a = strings.NewReplacer(" ", "", "_", "", "-", "").Replace(a)
+
+	switch a {
+	case "outofstock", "oos", "soldout", "discontinued", "unavailable":
+		return false
+	}
+	return true
+}
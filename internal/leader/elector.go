@@ -0,0 +1,129 @@
+package leader
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/derkres11/price-pulse/internal/domain"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var leading = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "pricepulse_leader",
+	Help: "1 on the instance that runs the periodic jobs, 0 on the others.",
+}, []string{"instance"})
+
+// releaseTimeout bounds giving up the lock on shutdown
+const releaseTimeout = 5 * time.Second
+
+// Elector keeps trying to take the lock and tells the callbacks when this instance
+// becomes or stops being the leader
+type Elector struct {
+	lock       domain.LeaderLock
+	instanceID string
+	interval   time.Duration // between attempts, and between renewals while leading
+	logger     *slog.Logger
+
+	leader    atomic.Bool
+	callbacks []func(leading bool)
+}
+
+func New(lock domain.LeaderLock, instanceID string, interval time.Duration, logger *slog.Logger) *Elector {
+	leading.WithLabelValues(instanceID).Set(0)
+	return &Elector{
+		lock:       lock,
+		instanceID: instanceID,
+		interval:   interval,
+		logger:     logger,
+	}
+}
+
+func (e *Elector) IsLeader() bool {
+	return e.leader.Load()
+}
+
+// OnChange registers fn for leadership changes. Callbacks run one after another on
+// the elector's goroutine, in the order they were registered, and must be registered
+// before Run.
+func (e *Elector) OnChange(fn func(leading bool)) {
+	e.callbacks = append(e.callbacks, fn)
+}
+
+// WhileLeading runs job whenever this instance leads. Its context ends when leadership
+// is lost or the elector stops, and the elector waits for job to return.
+func (e *Elector) WhileLeading(job func(ctx context.Context)) {
+	var (
+		cancel context.CancelFunc
+		done   chan struct{}
+	)
+	e.OnChange(func(leading bool) {
+		if leading {
+			var ctx context.Context
+			ctx, cancel = context.WithCancel(context.Background())
+			done = make(chan struct{})
+			go func() {
+				defer close(done)
+				job(ctx)
+			}()
+			return
+		}
+		if cancel != nil {
+			cancel()
+			<-done
+			cancel = nil
+		}
+	})
+}
+
+// Run campaigns until ctx is done, then stops the jobs and releases the lock so
+// another instance takes over without waiting for it to expire
+func (e *Elector) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		e.campaign(ctx)
+
+		select {
+		case <-ctx.Done():
+			e.set(false)
+			ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), releaseTimeout)
+			defer cancel()
+			if err := e.lock.Release(ctx); err != nil {
+				e.logger.Error("Leader: failed to release", slog.String("error", err.Error()))
+			}
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// campaign takes or renews the lock once. An error counts as lost leadership, the
+// lock may be gone with the connection.
+func (e *Elector) campaign(ctx context.Context) {
+	ok, err := e.lock.TryAcquire(ctx)
+	if err != nil && ctx.Err() == nil {
+		e.logger.Error("Leader: election failed", slog.String("error", err.Error()))
+	}
+	e.set(ok && err == nil)
+}
+
+func (e *Elector) set(leader bool) {
+	if e.leader.Swap(leader) == leader {
+		return
+	}
+
+	if leader {
+		e.logger.Info("Leader: elected", slog.String("instance", e.instanceID))
+		leading.WithLabelValues(e.instanceID).Set(1)
+	} else {
+		e.logger.Info("Leader: stepped down", slog.String("instance", e.instanceID))
+		leading.WithLabelValues(e.instanceID).Set(0)
+	}
+	for _, fn := range e.callbacks {
+		fn(leader)
+	}
+}
@@ -0,0 +1,150 @@
+package leader
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+)
+
+// lockMock answers TryAcquire from a script, then keeps the last answer
+type lockMock struct {
+	mu       sync.Mutex
+	answers  []error // nil acquires, errNotLeader loses, anything else fails
+	released bool
+}
+
+var errNotLeader = errors.New("held by another instance")
+
+func (l *lockMock) TryAcquire(ctx context.Context) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	answer := l.answers[0]
+	if len(l.answers) > 1 {
+		l.answers = l.answers[1:]
+	}
+	switch answer {
+	case nil:
+		return true, nil
+	case errNotLeader:
+		return false, nil
+	default:
+		return false, answer
+	}
+}
+
+func (l *lockMock) Release(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.released = true
+	return nil
+}
+
+func discard() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func TestElector_Campaign(t *testing.T) {
+	lock := &lockMock{answers: []error{errNotLeader, nil, nil, errors.New("connection reset"), nil}}
+	e := New(lock, "test-campaign", time.Hour, discard())
+
+	var changes []bool
+	e.OnChange(func(leading bool) { changes = append(changes, leading) })
+
+	want := []bool{false, true, true, false, true}
+	for i, w := range want {
+		e.campaign(context.Background())
+		if e.IsLeader() != w {
+			t.Errorf("round %d: IsLeader() = %v, want %v", i, e.IsLeader(), w)
+		}
+	}
+
+	// Only real changes are reported, an error steps down
+	wantChanges := []bool{true, false, true}
+	if len(changes) != len(wantChanges) {
+		t.Fatalf("changes = %v, want %v", changes, wantChanges)
+	}
+	for i := range changes {
+		if changes[i] != wantChanges[i] {
+			t.Errorf("changes = %v, want %v", changes, wantChanges)
+			break
+		}
+	}
+}
+
+func TestElector_WhileLeading(t *testing.T) {
+	lock := &lockMock{answers: []error{nil, errNotLeader, nil}}
+	e := New(lock, "test-jobs", time.Hour, discard())
+
+	var (
+		mu      sync.Mutex
+		started int
+		stopped int
+	)
+	e.WhileLeading(func(ctx context.Context) {
+		mu.Lock()
+		started++
+		mu.Unlock()
+		<-ctx.Done()
+		mu.Lock()
+		stopped++
+		mu.Unlock()
+	})
+	counts := func() (int, int) {
+		mu.Lock()
+		defer mu.Unlock()
+		return started, stopped
+	}
+
+	e.campaign(context.Background()) // elected
+	e.campaign(context.Background()) // lost, the job has returned when set() does
+	if s, st := counts(); st != 1 || s != 1 {
+		t.Fatalf("started %d, stopped %d after losing leadership, want 1 and 1", s, st)
+	}
+
+	e.campaign(context.Background()) // elected again, the job restarts
+	deadline := time.Now().Add(time.Second)
+	for s, _ := counts(); s != 2 && time.Now().Before(deadline); s, _ = counts() {
+		time.Sleep(time.Millisecond)
+	}
+	if s, _ := counts(); s != 2 {
+		t.Fatalf("job started %d times, want 2", s)
+	}
+}
+
+func TestElector_RunReleasesOnShutdown(t *testing.T) {
+	lock := &lockMock{answers: []error{nil}}
+	e := New(lock, "test-shutdown", time.Millisecond, discard())
+
+	stopped := make(chan struct{})
+	e.WhileLeading(func(ctx context.Context) {
+		<-ctx.Done()
+		close(stopped)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		e.Run(ctx)
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for !e.IsLeader() && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	select {
+	case <-stopped:
+	default:
+		t.Error("job still running after Run returned")
+	}
+	if e.IsLeader() || !lock.released {
+		t.Errorf("IsLeader() = %v, released = %v after shutdown, want false and true", e.IsLeader(), lock.released)
+	}
+}
@@ -0,0 +1,116 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/derkres11/price-pulse/internal/domain"
+)
+
+const (
+	DefaultTelegramURL = "https://api.telegram.org"
+	DefaultSlackURL    = "https://slack.com"
+)
+
+// TelegramNotifier posts to a chat through the Bot API.
+// baseURL is configurable for self-hosted Bot API servers and tests.
+type TelegramNotifier struct {
+	client  *http.Client
+	baseURL string
+	token   string
+}
+
+func NewTelegramNotifier(baseURL, token string, timeout time.Duration) *TelegramNotifier {
+	if baseURL == "" {
+		baseURL = DefaultTelegramURL
+	}
+	return &TelegramNotifier{
+		client:  &http.Client{Timeout: timeout},
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+	}
+}
+
+func (t *TelegramNotifier) Send(ctx context.Context, ch *domain.NotificationChannel, text string, n *domain.Notification) error {
+	body, _ := json.Marshal(map[string]any{
+		"chat_id":                  ch.Target,
+		"text":                     text,
+		"disable_web_page_preview": true,
+	})
+
+	url := fmt.Sprintf("%s/bot%s/sendMessage", t.baseURL, t.token)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build telegram request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	return doChatRequest(t.client, req)
+}
+
+// SlackNotifier posts with chat.postMessage using a bot token
+type SlackNotifier struct {
+	client  *http.Client
+	baseURL string
+	token   string
+}
+
+func NewSlackNotifier(baseURL, token string, timeout time.Duration) *SlackNotifier {
+	if baseURL == "" {
+		baseURL = DefaultSlackURL
+	}
+	return &SlackNotifier{
+		client:  &http.Client{Timeout: timeout},
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+	}
+}
+
+func (s *SlackNotifier) Send(ctx context.Context, ch *domain.NotificationChannel, text string, n *domain.Notification) error {
+	body, _ := json.Marshal(map[string]any{
+		"channel": ch.Target,
+		"text":    text,
+	})
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.baseURL+"/api/chat.postMessage", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build slack request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer "+s.token)
+
+	return doChatRequest(s.client, req)
+}
+
+// doChatRequest handles the {"ok": false, "description"/"error": ...} envelope both bot APIs use,
+// they report failures with a 200 status more often than not.
+func doChatRequest(client *http.Client, req *http.Request) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request to %s failed: %w", req.URL.Host, stripURL(err))
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		OK          bool   `json:"ok"`
+		Error       string `json:"error"`
+		Description string `json:"description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("unexpected response from %s (status %d): %w", req.URL.Host, resp.StatusCode, err)
+	}
+
+	if !result.OK {
+		reason := result.Description
+		if reason == "" {
+			reason = result.Error
+		}
+		return fmt.Errorf("%s rejected the message: %s", req.URL.Host, reason)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/derkres11/price-pulse/internal/domain"
+)
+
+// SMTPNotifier sends plain text email through a relay
+type SMTPNotifier struct {
+	addr     string
+	from     string
+	username string
+	password string
+}
+
+func NewSMTPNotifier(addr, from, username, password string) *SMTPNotifier {
+	return &SMTPNotifier{
+		addr:     addr,
+		from:     from,
+		username: username,
+		password: password,
+	}
+}
+
+func (s *SMTPNotifier) Send(ctx context.Context, ch *domain.NotificationChannel, text string, n *domain.Notification) error {
+	var auth smtp.Auth
+	if s.username != "" {
+		host, _, _ := net.SplitHostPort(s.addr)
+		auth = smtp.PlainAuth("", s.username, s.password, host)
+	}
+
+	subject := "Price alert: " + n.Title
+	msg := strings.Join([]string{
+		"From: " + s.from,
+		"To: " + ch.Target,
+		"Subject: " + sanitizeHeader(subject),
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		text,
+	}, "\r\n")
+
+	// net/smtp has no context support, run it aside so cancellation is honoured
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(s.addr, auth, s.from, []string{ch.Target}, []byte(msg))
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("failed to send email to %s: %w", ch.Target, err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// sanitizeHeader keeps product titles from injecting extra headers
+func sanitizeHeader(v string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(v)
+}
@@ -0,0 +1,66 @@
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// ErrBlockedAddress is returned for webhook targets inside our own network
+var ErrBlockedAddress = errors.New("address is not public")
+
+// Ranges IsPrivate and friends leave out: "this network", carrier-grade NAT (cloud
+// metadata lives there on some providers) and the IPv4 benchmark block
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+}
+
+// PublicAddr reports whether ip may be the target of a webhook: not loopback,
+// private, link-local (169.254.169.254 included), multicast or unspecified
+func PublicAddr(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if !ip.IsValid() || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	for _, p := range blockedPrefixes {
+		if p.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// publicOnly is a net.Dialer Control that refuses addresses PublicAddr rejects. It sees
+// the resolved address, so a name that resolves to an internal one is caught on every
+// dial, DNS rebinding and redirects included.
+func publicOnly(network, address string, _ syscall.RawConn) error {
+	addr, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, address)
+	}
+	if !PublicAddr(addr.Addr()) {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, addr.Addr())
+	}
+	return nil
+}
+
+// guardedClient dials with control, nil allows every address. It ignores proxy
+// settings, a proxy would dial the target for us unchecked.
+func guardedClient(timeout time.Duration, control func(network, address string, c syscall.RawConn) error) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, Control: control}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConnsPerHost: 2,
+		},
+	}
+}
@@ -0,0 +1,259 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/derkres11/price-pulse/internal/domain"
+)
+
+func testNotification() *domain.Notification {
+	return &domain.Notification{
+		Event: &domain.AlertEvent{
+			ProductID:     42,
+			Type:          domain.AlertThreshold,
+			Price:         79.5,
+			PreviousPrice: 99,
+			Reason:        "price 79.50 is at or below target 80.00",
+		},
+		Title:    "Desk Lamp",
+		URL:      "https://shop.example/lamp",
+		Currency: "EUR",
+	}
+}
+
+func TestRender(t *testing.T) {
+	n := testNotification()
+
+	text, err := Render(&domain.NotificationChannel{}, n)
+	if err != nil {
+		t.Fatalf("default template failed: %v", err)
+	}
+	for _, want := range []string{"Desk Lamp", "79.50 EUR", "was 99.00 EUR", "https://shop.example/lamp"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in default message, got:\n%s", want, text)
+		}
+	}
+
+	text, err = Render(&domain.NotificationChannel{Template: "{{.Title}} -> {{price .Event.Price .Currency}}"}, n)
+	if err != nil || text != "Desk Lamp -> 79.50 EUR" {
+		t.Errorf("unexpected custom render: %q, %v", text, err)
+	}
+
+	if _, err := Render(&domain.NotificationChannel{Template: "{{.Nope}}"}, n); err == nil {
+		t.Error("expected unknown field to fail")
+	}
+}
+
+func TestWebhookNotifier_Send(t *testing.T) {
+	var gotBody []byte
+	var gotSig, gotTS string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotBody, _ = io.ReadAll(r.Body)
+		gotSig = r.Header.Get(SignatureHeader)
+		gotTS = r.Header.Get(TimestampHeader)
+	}))
+	defer srv.Close()
+
+	ch := &domain.NotificationChannel{ID: 1, Type: domain.ChannelWebhook, Target: srv.URL, Secret: "s3cret"}
+	if err := (&WebhookNotifier{client: guardedClient(time.Second, nil)}).Send(context.Background(), ch, "hello", testNotification()); err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+
+	if want := "sha256=" + Sign("s3cret", gotTS, gotBody); gotSig != want {
+		t.Errorf("signature mismatch: got %q, want %q", gotSig, want)
+	}
+
+	var payload webhookPayload
+	if err := json.Unmarshal(gotBody, &payload); err != nil || payload.Text != "hello" || payload.Event.ProductID != 42 {
+		t.Errorf("unexpected payload %s (%v)", gotBody, err)
+	}
+}
+
+func TestWebhookNotifier_ErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	ch := &domain.NotificationChannel{Target: srv.URL}
+	if err := (&WebhookNotifier{client: guardedClient(time.Second, nil)}).Send(context.Background(), ch, "hello", testNotification()); err == nil {
+		t.Error("expected error on 502")
+	}
+}
+
+func TestWebhookNotifier_RefusesInternalAddresses(t *testing.T) {
+	var called bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer srv.Close()
+
+	ch := &domain.NotificationChannel{Target: srv.URL}
+	if err := NewWebhookNotifier(time.Second).Send(context.Background(), ch, "hello", testNotification()); !errors.Is(err, ErrBlockedAddress) || called {
+		t.Errorf("expected ErrBlockedAddress for loopback without a request, got %v (called %v)", err, called)
+	}
+}
+
+func TestPublicAddr(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:4700::1111", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"100.100.100.200", false},
+		{"0.0.0.0", false},
+		{"fe80::1", false},
+		{"fd00:ec2::254", false},
+		{"::ffff:127.0.0.1", false},
+		{"224.0.0.1", false},
+	}
+
+	for _, tt := range tests {
+		if got := PublicAddr(netip.MustParseAddr(tt.addr)); got != tt.want {
+			t.Errorf("PublicAddr(%s) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestChatNotifiers_Send(t *testing.T) {
+	var gotPath, gotAuth string
+	var gotBody map[string]any
+	ok := true
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotAuth = r.Header.Get("Authorization")
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		_ = json.NewEncoder(w).Encode(map[string]any{"ok": ok, "description": "chat not found"})
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name      string
+		notifier  domain.Notifier
+		target    string
+		wantPath  string
+		wantAuth  string
+		targetKey string
+	}{
+		{"Telegram", NewTelegramNotifier(srv.URL, "123:abc", time.Second), "-100500",
+			"/bot123:abc/sendMessage", "", "chat_id"},
+		{"Slack", NewSlackNotifier(srv.URL+"/", "xoxb-token", time.Second), "#deals",
+			"/api/chat.postMessage", "Bearer xoxb-token", "channel"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok = true
+			ch := &domain.NotificationChannel{Target: tt.target}
+			if err := tt.notifier.Send(context.Background(), ch, "hello", testNotification()); err != nil {
+				t.Fatalf("send failed: %v", err)
+			}
+			if gotPath != tt.wantPath || gotAuth != tt.wantAuth {
+				t.Errorf("unexpected request %s (auth %q)", gotPath, gotAuth)
+			}
+			if gotBody[tt.targetKey] != tt.target || gotBody["text"] != "hello" {
+				t.Errorf("unexpected body %v", gotBody)
+			}
+
+			ok = false
+			err := tt.notifier.Send(context.Background(), ch, "hello", testNotification())
+			if err == nil || !strings.Contains(err.Error(), "chat not found") {
+				t.Errorf("expected API rejection, got %v", err)
+			}
+		})
+	}
+}
+
+// fakeSMTP speaks just enough SMTP for net/smtp and returns the DATA it received
+func fakeSMTP(t *testing.T) (string, <-chan string) {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { lis.Close() })
+
+	data := make(chan string, 1)
+	go func() {
+		conn, err := lis.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		reply := func(s string) { _, _ = conn.Write([]byte(s + "\r\n")) }
+		reply("220 localhost ESMTP")
+
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch cmd := strings.ToUpper(strings.TrimSpace(line)); {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				reply("250 localhost")
+			case strings.HasPrefix(cmd, "DATA"):
+				reply("354 go ahead")
+				var sb strings.Builder
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil || l == ".\r\n" {
+						break
+					}
+					sb.WriteString(l)
+				}
+				data <- sb.String()
+				reply("250 queued")
+			case strings.HasPrefix(cmd, "QUIT"):
+				reply("221 bye")
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+
+	return lis.Addr().String(), data
+}
+
+func TestSMTPNotifier_Send(t *testing.T) {
+	addr, data := fakeSMTP(t)
+
+	n := testNotification()
+	n.Title = "Lamp\r\nBcc: everyone@example.com"
+
+	ch := &domain.NotificationChannel{Type: domain.ChannelEmail, Target: "buyer@example.com"}
+	if err := NewSMTPNotifier(addr, "alerts@pricepulse.local", "", "").Send(context.Background(), ch, "cheap now", n); err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+
+	msg := <-data
+	if !strings.Contains(msg, "To: buyer@example.com") || !strings.Contains(msg, "cheap now") {
+		t.Errorf("unexpected message:\n%s", msg)
+	}
+	if strings.Contains(msg, "\r\nBcc:") {
+		t.Errorf("title injected a header:\n%s", msg)
+	}
+}
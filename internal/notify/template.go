@@ -0,0 +1,60 @@
+package notify
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/derkres11/price-pulse/internal/domain"
+)
+
+// DefaultTemplate is used by channels without their own template
+const DefaultTemplate = `Price alert: {{.Title}}
+{{.Event.Reason}}
+Now: {{price .Event.Price .Currency}}{{if .Event.PreviousPrice}} (was {{price .Event.PreviousPrice .Currency}}){{end}}
+{{.URL}}`
+
+var funcs = template.FuncMap{
+	"price": func(v float64, currency string) string {
+		return strings.TrimSpace(fmt.Sprintf("%.2f %s", v, currency))
+	},
+}
+
+// ParseTemplate validates a channel template, an empty one falls back to DefaultTemplate
+func ParseTemplate(text string) (*template.Template, error) {
+	if text == "" {
+		text = DefaultTemplate
+	}
+	return template.New("notification").Funcs(funcs).Option("missingkey=error").Parse(text)
+}
+
+// Render produces the message text for a channel
+func Render(ch *domain.NotificationChannel, n *domain.Notification) (string, error) {
+	tmpl, err := ParseTemplate(ch.Template)
+	if err != nil {
+		return "", fmt.Errorf("bad template for channel %d: %w", ch.ID, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, n); err != nil {
+		return "", fmt.Errorf("failed to render template for channel %d: %w", ch.ID, err)
+	}
+	return buf.String(), nil
+}
+
+// RenderDigest renders every queued notification with the channel's template
+// and joins them into one message
+func RenderDigest(ch *domain.NotificationChannel, items []*domain.Notification) (string, error) {
+	parts := make([]string, 0, len(items)+1)
+	parts = append(parts, fmt.Sprintf("%d price alerts during quiet hours", len(items)))
+
+	for _, n := range items {
+		text, err := Render(ch, n)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, text)
+	}
+	return strings.Join(parts, "\n\n"), nil
+}
@@ -0,0 +1,94 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/derkres11/price-pulse/internal/domain"
+)
+
+const (
+	SignatureHeader = "X-PricePulse-Signature"
+	TimestampHeader = "X-PricePulse-Timestamp"
+)
+
+// WebhookNotifier POSTs a JSON payload to the channel URL.
+// When the channel has a secret the body is signed with HMAC-SHA256 over "<timestamp>.<body>".
+// Targets are user supplied, so only public addresses are dialed.
+type WebhookNotifier struct {
+	client *http.Client
+}
+
+func NewWebhookNotifier(timeout time.Duration) *WebhookNotifier {
+	return &WebhookNotifier{client: guardedClient(timeout, publicOnly)}
+}
+
+type webhookPayload struct {
+	Text   string                 `json:"text"`
+	Title  string                 `json:"title"`
+	URL    string                 `json:"url"`
+	Event  *domain.AlertEvent     `json:"event,omitempty"`
+	Digest []*domain.Notification `json:"digest,omitempty"`
+}
+
+func (w *WebhookNotifier) Send(ctx context.Context, ch *domain.NotificationChannel, text string, n *domain.Notification) error {
+	body, err := json.Marshal(webhookPayload{Text: text, Title: n.Title, URL: n.URL, Event: n.Event, Digest: n.Digest})
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ch.Target, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if ch.Secret != "" {
+		ts := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(TimestampHeader, ts)
+		req.Header.Set(SignatureHeader, "sha256="+Sign(ch.Secret, ts, body))
+	}
+
+	return doRequest(w.client, req)
+}
+
+// Sign returns the hex HMAC a webhook receiver should compare against the signature header
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func doRequest(client *http.Client, req *http.Request) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request to %s failed: %w", req.URL.Host, stripURL(err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d from %s", resp.StatusCode, req.URL.Host)
+	}
+	return nil
+}
+
+// stripURL drops the request URL from transport errors, it may carry a bot token
+func stripURL(err error) error {
+	var ue *url.Error
+	if errors.As(err, &ue) {
+		return ue.Err
+	}
+	return err
+}
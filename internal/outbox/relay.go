@@ -0,0 +1,210 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/derkres11/price-pulse/internal/domain"
+)
+
+// Producer queues tasks in the outbox instead of sending them to Kafka. Called inside
+// a transaction the message commits, or rolls back, with the change that caused it.
+type Producer struct {
+	repo domain.OutboxRepository
+}
+
+func NewProducer(repo domain.OutboxRepository) *Producer {
+	return &Producer{repo: repo}
+}
+
+func (p *Producer) SendProductUpdate(ctx context.Context, productID int64) error {
+	if err := p.enqueue(ctx, domain.OutboxProductUpdate, productID); err != nil {
+		return fmt.Errorf("error queueing product update: %w", err)
+	}
+	return nil
+}
+
+func (p *Producer) SendProductDeleted(ctx context.Context, productID int64) error {
+	if err := p.enqueue(ctx, domain.OutboxProductDeleted, productID); err != nil {
+		return fmt.Errorf("error queueing product deletion: %w", err)
+	}
+	return nil
+}
+
+// enqueue keeps the request that caused the message, the relay publishes on its behalf
+func (p *Producer) enqueue(ctx context.Context, kind string, aggregateID int64) error {
+	m := &domain.OutboxMessage{
+		Kind:        kind,
+		AggregateID: aggregateID,
+		RequestID:   domain.RequestIDFromContext(ctx),
+	}
+	if t, ok := domain.TraceFromContext(ctx); ok {
+		m.Traceparent = t.Traceparent()
+	}
+	return p.repo.Enqueue(ctx, m)
+}
+
+// Settings of the relay loop
+type Options struct {
+	PollInterval time.Duration
+	BatchSize    int
+	MaxBackoff   time.Duration // the retry delay doubles per attempt up to this
+	MaxAttempts  int           // failed attempts after which a message is parked
+	// ClaimTimeout bounds the publishing of a batch. Messages left over are claimed again
+	// after it, by this relay or the next leader's.
+	ClaimTimeout time.Duration
+	Retention    time.Duration // how long sent and parked messages are kept for inspection
+}
+
+// Relay publishes the outbox to Kafka. A message is marked sent only after Kafka took it,
+// so a crash in between publishes it again: consumers must tolerate duplicates.
+type Relay struct {
+	repo     domain.OutboxRepository
+	producer domain.EventPublisher
+	opts     Options
+	logger   *slog.Logger
+	now      func() time.Time
+}
+
+func NewRelay(repo domain.OutboxRepository, producer domain.EventPublisher, opts Options, logger *slog.Logger) *Relay {
+	return &Relay{
+		repo:     repo,
+		producer: producer,
+		opts:     opts,
+		logger:   logger,
+		now:      time.Now,
+	}
+}
+
+// Run relays until ctx is done. A full batch is followed by the next one right away.
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.opts.PollInterval)
+	defer ticker.Stop()
+	cleanup := time.NewTicker(time.Hour)
+	defer cleanup.Stop()
+
+	for {
+		sent, err := r.RelayOnce(ctx)
+		if err != nil {
+			r.logger.Error("Outbox: relay failed", slog.String("error", err.Error()))
+		}
+		if err == nil && sent == r.opts.BatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-cleanup.C:
+			r.cleanup(ctx)
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayOnce publishes one batch of due messages and returns how many went out. The
+// messages are claimed first, so no transaction stays open while Kafka is written.
+func (r *Relay) RelayOnce(ctx context.Context) (int, error) {
+	msgs, err := r.repo.ClaimDue(ctx, r.opts.BatchSize, r.now().Add(r.opts.ClaimTimeout))
+	if err != nil {
+		return 0, fmt.Errorf("error claiming outbox messages: %w", err)
+	}
+
+	pubCtx, cancel := context.WithTimeout(ctx, r.opts.ClaimTimeout)
+	defer cancel()
+
+	var (
+		sent    = make([]int64, 0, len(msgs))
+		failErr error
+	)
+	for _, m := range msgs {
+		if pubCtx.Err() != nil {
+			break // the rest is claimed again once the claim runs out
+		}
+		if err := r.publish(pubCtx, m); err != nil {
+			if failErr = r.fail(ctx, m, err); failErr != nil {
+				break
+			}
+			continue
+		}
+		sent = append(sent, m.ID)
+	}
+
+	if len(sent) > 0 {
+		if err := r.repo.MarkSent(ctx, sent); err != nil {
+			return 0, fmt.Errorf("error marking outbox messages sent: %w", err)
+		}
+	}
+	return len(sent), failErr
+}
+
+// fail parks m when retrying can't help or its attempts are used up, else schedules the
+// next one
+func (r *Relay) fail(ctx context.Context, m *domain.OutboxMessage, pubErr error) error {
+	attempt := m.Attempts + 1
+	if errors.Is(pubErr, domain.ErrUnpublishable) || attempt >= r.opts.MaxAttempts {
+		r.logger.Error("Outbox: publish failed, message parked",
+			slog.Int64("id", m.ID),
+			slog.String("kind", m.Kind),
+			slog.Int("attempt", attempt),
+			slog.String("error", pubErr.Error()))
+		if err := r.repo.Park(ctx, m.ID, pubErr.Error()); err != nil {
+			return fmt.Errorf("error parking outbox message %d: %w", m.ID, err)
+		}
+		return nil
+	}
+
+	retryAt := r.now().Add(r.backoff(attempt))
+	r.logger.Warn("Outbox: publish failed, will retry",
+		slog.Int64("id", m.ID),
+		slog.String("kind", m.Kind),
+		slog.Int("attempt", attempt),
+		slog.Time("retry_at", retryAt),
+		slog.String("error", pubErr.Error()))
+	if err := r.repo.Retry(ctx, m.ID, pubErr.Error(), retryAt); err != nil {
+		return fmt.Errorf("error rescheduling outbox message %d: %w", m.ID, err)
+	}
+	return nil
+}
+
+// publish sends m on behalf of the request that queued it
+func (r *Relay) publish(ctx context.Context, m *domain.OutboxMessage) error {
+	if m.RequestID != "" {
+		ctx = domain.WithRequestID(ctx, m.RequestID)
+	}
+	if t, err := domain.ParseTraceparent(m.Traceparent); err == nil {
+		ctx = domain.WithTrace(ctx, t)
+	}
+
+	switch m.Kind {
+	case domain.OutboxProductUpdate:
+		return r.producer.SendProductUpdate(ctx, m.AggregateID)
+	case domain.OutboxProductDeleted:
+		return r.producer.SendProductDeleted(ctx, m.AggregateID, m.CreatedAt)
+	default:
+		return fmt.Errorf("%w: unknown outbox message kind %q", domain.ErrUnpublishable, m.Kind)
+	}
+}
+
+// backoff is 1s, 2s, 4s, ... capped at MaxBackoff
+func (r *Relay) backoff(attempt int) time.Duration {
+	d := time.Second
+	for i := 1; i < attempt && d < r.opts.MaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, r.opts.MaxBackoff)
+}
+
+func (r *Relay) cleanup(ctx context.Context) {
+	n, err := r.repo.DeleteSent(ctx, r.now().Add(-r.opts.Retention))
+	if err != nil {
+		r.logger.Error("Outbox: cleanup failed", slog.String("error", err.Error()))
+		return
+	}
+	if n > 0 {
+		r.logger.Info("Outbox: removed sent and parked messages", slog.Int64("count", n))
+	}
+}
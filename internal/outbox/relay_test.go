@@ -0,0 +1,238 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/derkres11/price-pulse/internal/domain"
+)
+
+// outboxMock keeps the table in memory and settles messages like the repo does
+type outboxMock struct {
+	msgs   []*domain.OutboxMessage
+	sent   map[int64]bool
+	parked map[int64]bool
+}
+
+func (m *outboxMock) Enqueue(ctx context.Context, msg *domain.OutboxMessage) error {
+	msg.ID = int64(len(m.msgs) + 1)
+	m.msgs = append(m.msgs, msg)
+	return nil
+}
+
+// ClaimDue ignores available_at, the tests relay again only once a retry is due
+func (m *outboxMock) ClaimDue(ctx context.Context, limit int, until time.Time) ([]*domain.OutboxMessage, error) {
+	var claimed []*domain.OutboxMessage
+	for _, msg := range m.msgs {
+		if m.sent[msg.ID] || m.parked[msg.ID] || len(claimed) == limit {
+			continue
+		}
+		msg.AvailableAt = until
+		claimed = append(claimed, msg)
+	}
+	return claimed, nil
+}
+
+func (m *outboxMock) MarkSent(ctx context.Context, ids []int64) error {
+	for _, id := range ids {
+		m.msg(id).Attempts++
+		m.sent[id] = true
+	}
+	return nil
+}
+
+func (m *outboxMock) Retry(ctx context.Context, id int64, lastErr string, at time.Time) error {
+	msg := m.msg(id)
+	msg.Attempts++
+	msg.LastError, msg.AvailableAt = lastErr, at
+	return nil
+}
+
+func (m *outboxMock) Park(ctx context.Context, id int64, lastErr string) error {
+	msg := m.msg(id)
+	msg.Attempts++
+	msg.LastError = lastErr
+	m.parked[id] = true
+	return nil
+}
+
+func (m *outboxMock) msg(id int64) *domain.OutboxMessage {
+	return m.msgs[id-1]
+}
+
+func newOutboxMock() *outboxMock {
+	return &outboxMock{sent: map[int64]bool{}, parked: map[int64]bool{}}
+}
+
+func (m *outboxMock) DeleteSent(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+type producerMock struct {
+	fail    int // number of calls to fail before succeeding
+	sent    []int64
+	deleted map[int64]time.Time
+	traces  []string // request ID and traceparent of each send
+}
+
+func (p *producerMock) SendProductUpdate(ctx context.Context, id int64) error {
+	if p.fail > 0 {
+		p.fail--
+		return errors.New("kafka unavailable")
+	}
+	p.sent = append(p.sent, id)
+	t, _ := domain.TraceFromContext(ctx)
+	p.traces = append(p.traces, domain.RequestIDFromContext(ctx)+" "+t.Traceparent())
+	return nil
+}
+
+func (p *producerMock) SendProductDeleted(ctx context.Context, id int64, at time.Time) error {
+	if p.deleted == nil {
+		p.deleted = map[int64]time.Time{}
+	}
+	p.deleted[id] = at
+	return nil
+}
+
+func TestRelay(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	repo := newOutboxMock()
+	kafka := &producerMock{fail: 1}
+	relay := NewRelay(repo, kafka, Options{BatchSize: 10, MaxBackoff: 5 * time.Second, MaxAttempts: 3, ClaimTimeout: time.Minute}, logger)
+	relay.now = func() time.Time { return now }
+
+	producer := NewProducer(repo)
+	for _, id := range []int64{7, 8} {
+		if err := producer.SendProductUpdate(ctx, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Kafka rejects the first message, the second still goes out
+	sent, err := relay.RelayOnce(ctx)
+	if err != nil || sent != 1 {
+		t.Fatalf("RelayOnce() = %d, %v, want 1 sent", sent, err)
+	}
+	failed := repo.msgs[0]
+	if failed.LastError == "" || !failed.AvailableAt.Equal(now.Add(time.Second)) {
+		t.Errorf("failed message = %+v, want an error and a retry in 1s", failed)
+	}
+
+	sent, err = relay.RelayOnce(ctx)
+	if err != nil || sent != 1 {
+		t.Fatalf("RelayOnce() = %d, %v, want the retry sent", sent, err)
+	}
+	if len(kafka.sent) != 2 || kafka.sent[0] != 8 || kafka.sent[1] != 7 {
+		t.Errorf("published %v, want [8 7]", kafka.sent)
+	}
+
+	// An unknown kind can't ever be published, it is parked right away
+	repo.msgs = append(repo.msgs, &domain.OutboxMessage{ID: 3, Kind: "unknown"})
+	if sent, _ := relay.RelayOnce(ctx); sent != 0 || !repo.parked[3] || repo.msgs[2].LastError == "" {
+		t.Errorf("expected an unknown kind to be parked with an error")
+	}
+}
+
+func TestRelay_ParksAfterMaxAttempts(t *testing.T) {
+	ctx := context.Background()
+	repo := newOutboxMock()
+	kafka := &producerMock{fail: 10}
+	relay := NewRelay(repo, kafka, Options{BatchSize: 10, MaxBackoff: time.Second, MaxAttempts: 3, ClaimTimeout: time.Minute}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	if err := NewProducer(repo).SendProductUpdate(ctx, 7); err != nil {
+		t.Fatal(err)
+	}
+	for attempt := 1; attempt <= 3; attempt++ {
+		if _, err := relay.RelayOnce(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if parked := repo.parked[1]; parked != (attempt == 3) {
+			t.Errorf("attempt %d: parked = %t", attempt, parked)
+		}
+	}
+	if _, err := relay.RelayOnce(ctx); err != nil || kafka.fail != 7 {
+		t.Errorf("a parked message was published again: %d attempts", 10-kafka.fail)
+	}
+}
+
+func TestRelay_StopsAtClaimTimeout(t *testing.T) {
+	repo := newOutboxMock()
+	relay := NewRelay(repo, &producerMock{}, Options{BatchSize: 10, MaxBackoff: time.Second, MaxAttempts: 3, ClaimTimeout: time.Minute}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	for _, id := range []int64{7, 8} {
+		if err := NewProducer(repo).SendProductUpdate(context.Background(), id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// With the claim already over nothing is published, the messages stay claimed
+	// until it runs out and are neither sent nor charged an attempt
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	sent, err := relay.RelayOnce(ctx)
+	if err != nil || sent != 0 {
+		t.Fatalf("RelayOnce() = %d, %v, want nothing sent", sent, err)
+	}
+	for _, msg := range repo.msgs {
+		if msg.Attempts != 0 || repo.sent[msg.ID] {
+			t.Errorf("message %d = %+v, want it untouched", msg.ID, msg)
+		}
+	}
+}
+
+func TestRelay_KeepsRequestContext(t *testing.T) {
+	trace := domain.NewTraceContext()
+	ctx := domain.WithTrace(domain.WithRequestID(context.Background(), "req-1"), trace)
+
+	repo := newOutboxMock()
+	kafka := &producerMock{}
+	relay := NewRelay(repo, kafka, Options{BatchSize: 10, MaxBackoff: time.Second, MaxAttempts: 3, ClaimTimeout: time.Minute}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	if err := NewProducer(repo).SendProductUpdate(ctx, 7); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := relay.RelayOnce(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "req-1 " + trace.Traceparent()
+	if len(kafka.traces) != 1 || kafka.traces[0] != want {
+		t.Errorf("published with %q, want %q", kafka.traces, want)
+	}
+}
+
+func TestRelay_Backoff(t *testing.T) {
+	relay := &Relay{opts: Options{MaxBackoff: 10 * time.Second}}
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second}
+	for i, w := range want {
+		if got := relay.backoff(i + 1); got != w {
+			t.Errorf("backoff(%d) = %s, want %s", i+1, got, w)
+		}
+	}
+}
+
+func TestRelay_ProductDeleted(t *testing.T) {
+	ctx := context.Background()
+	repo := newOutboxMock()
+	kafka := &producerMock{}
+	relay := NewRelay(repo, kafka, Options{BatchSize: 10, MaxBackoff: time.Second, MaxAttempts: 3, ClaimTimeout: time.Minute}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	if err := NewProducer(repo).SendProductDeleted(ctx, 7); err != nil {
+		t.Fatal(err)
+	}
+	deletedAt := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	repo.msgs[0].CreatedAt = deletedAt // set by the database
+
+	if sent, err := relay.RelayOnce(ctx); err != nil || sent != 1 {
+		t.Fatalf("RelayOnce() = %d, %v, want 1 sent", sent, err)
+	}
+	if at, ok := kafka.deleted[7]; !ok || !at.Equal(deletedAt) || len(kafka.sent) != 0 {
+		t.Errorf("published deletions %v and updates %v, want product 7 deleted at %s", kafka.deleted, kafka.sent, deletedAt)
+	}
+}
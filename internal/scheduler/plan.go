@@ -0,0 +1,119 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/derkres11/price-pulse/internal/domain"
+)
+
+// minObservations is how many checks within the window it takes to judge volatility
+const minObservations = 3
+
+// Explain works out the product's interval the way the scheduler would at its next check
+func (s *Scheduler) Explain(ctx context.Context, productID int64) (*domain.CheckPlan, error) {
+	d, err := s.repo.GetCheck(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	var signals *domain.CheckSignals
+	if s.adapts(d) {
+		all, err := s.repo.Signals(ctx, []int64{productID}, s.now().Add(-s.opts.VolatilityWindow))
+		if err != nil {
+			return nil, fmt.Errorf("error loading signals: %w", err)
+		}
+		signals = all[productID]
+	}
+	return s.plan(d, signals), nil
+}
+
+// adapts reports whether the product's interval is adjusted. An interval set on the
+// product itself is taken as is.
+func (s *Scheduler) adapts(d *domain.DueCheck) bool {
+	return s.opts.Adaptive && d.Source != domain.IntervalFromProduct
+}
+
+// plan scales the base interval by the product's volatility, its distance to the
+// target and its subscribers, within MinInterval and MaxInterval. Without signals the
+// base interval is kept.
+func (s *Scheduler) plan(d *domain.DueCheck, signals *domain.CheckSignals) *domain.CheckPlan {
+	base := d.Interval
+	if base <= 0 {
+		base = s.opts.DefaultInterval
+	}
+	p := &domain.CheckPlan{
+		ProductID:    d.ProductID,
+		Source:       d.Source,
+		BaseInterval: domain.Interval(base),
+		Interval:     domain.Interval(base),
+	}
+	if !s.adapts(d) || signals == nil {
+		return p
+	}
+
+	p.Adaptive = true
+	p.Signals = signals
+	p.Factors = []domain.PlanFactor{
+		volatilityFactor(signals, s.opts.VolatilityWindow),
+		targetFactor(signals),
+		popularityFactor(signals),
+	}
+
+	interval := float64(base)
+	for _, f := range p.Factors {
+		interval *= f.Multiplier
+	}
+	switch {
+	case interval < float64(s.opts.MinInterval):
+		interval, p.Bounded = float64(s.opts.MinInterval), "min"
+	case interval > float64(s.opts.MaxInterval):
+		interval, p.Bounded = float64(s.opts.MaxInterval), "max"
+	}
+	p.Interval = domain.Interval(time.Duration(interval).Round(time.Second))
+	return p
+}
+
+// volatilityFactor doubles the interval of a price that never moves and shortens it
+// the more of the checks saw a change, down to about a fifth when every one did
+func volatilityFactor(s *domain.CheckSignals, window time.Duration) domain.PlanFactor {
+	f := domain.PlanFactor{Name: "volatility", Multiplier: 1}
+	if s.Observations < minObservations {
+		f.Reason = fmt.Sprintf("%d checks in the last %s, too few to judge", s.Observations, window)
+		return f
+	}
+
+	rate := float64(s.Changes) / float64(s.Observations)
+	f.Multiplier = 2 / (1 + 8*rate)
+	f.Reason = fmt.Sprintf("price changed in %d of %d checks in the last %s", s.Changes, s.Observations, window)
+	return f
+}
+
+// targetFactor halves the interval of a price right above the target and leaves it
+// alone from 20% above on, or once the target is reached
+func targetFactor(s *domain.CheckSignals) domain.PlanFactor {
+	f := domain.PlanFactor{Name: "target", Multiplier: 1}
+	switch {
+	case s.Target <= 0 || s.Price <= 0:
+		f.Reason = "no target price"
+	case s.Price <= s.Target:
+		f.Reason = fmt.Sprintf("price %.2f already reached the target %.2f", s.Price, s.Target)
+	default:
+		gap := (s.Price - s.Target) / s.Price
+		f.Multiplier = 0.5 + 0.5*math.Min(gap/0.2, 1)
+		f.Reason = fmt.Sprintf("price %.2f is %.0f%% above the target %.2f", s.Price, gap*100, s.Target)
+	}
+	return f
+}
+
+// popularityFactor checks products watched by more users more often: one subscriber
+// takes a fifth off, 15 halve the interval
+func popularityFactor(s *domain.CheckSignals) domain.PlanFactor {
+	return domain.PlanFactor{
+		Name:       "popularity",
+		Multiplier: 1 / (1 + math.Log2(1+float64(s.Subscribers))/4),
+		Reason:     fmt.Sprintf("%d subscribers", s.Subscribers),
+	}
+}
@@ -0,0 +1,163 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+
+	"github.com/derkres11/price-pulse/internal/domain"
+)
+
+// Settings of the scheduler loop
+type Options struct {
+	PollInterval    time.Duration
+	BatchSize       int
+	DefaultInterval time.Duration // for products whose host has no interval either
+	// JitterPercent spreads the next check by up to this share of the interval either
+	// way, so the products of a shop added together are not checked together
+	JitterPercent int
+	// Adaptive adjusts the host's or default interval to the product, within
+	// MinInterval and MaxInterval, from the prices seen in the last VolatilityWindow
+	Adaptive         bool
+	MinInterval      time.Duration
+	MaxInterval      time.Duration
+	VolatilityWindow time.Duration
+}
+
+// Scheduler queues a check for every product whose next_check_at has passed and plans
+// the one after. Both happen in one transaction with the outbox write, so a product is
+// neither skipped nor queued twice, even with several schedulers running. The checks of
+// hosts whose circuit is open are put off until the host may be tried again.
+type Scheduler struct {
+	repo     domain.ScheduleRepository
+	tx       domain.Transactor
+	producer domain.TaskProducer
+	circuits domain.HostCircuits
+	opts     Options
+	logger   *slog.Logger
+	now      func() time.Time
+	random   func() float64 // in [0, 1)
+}
+
+func New(repo domain.ScheduleRepository, tx domain.Transactor, producer domain.TaskProducer, circuits domain.HostCircuits, opts Options, logger *slog.Logger) *Scheduler {
+	return &Scheduler{
+		repo:     repo,
+		tx:       tx,
+		producer: producer,
+		circuits: circuits,
+		opts:     opts,
+		logger:   logger,
+		now:      time.Now,
+		random:   rand.Float64,
+	}
+}
+
+// Run schedules until ctx is done. A full batch is followed by the next one right away.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		queued, err := s.ScheduleOnce(ctx)
+		if err != nil {
+			s.logger.Error("Scheduler: run failed", slog.String("error", err.Error()))
+		}
+		if err == nil && queued == s.opts.BatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ScheduleOnce handles one batch of due products and returns how many there were
+func (s *Scheduler) ScheduleOnce(ctx context.Context) (int, error) {
+	var n int
+	err := s.tx.InTx(ctx, func(ctx context.Context) error {
+		due, err := s.repo.LockDue(ctx, s.opts.BatchSize)
+		if err != nil {
+			return fmt.Errorf("error loading due products: %w", err)
+		}
+
+		signals, err := s.signals(ctx, due)
+		if err != nil {
+			return err
+		}
+
+		var deferred int
+		for _, d := range due {
+			interval := time.Duration(s.plan(d, signals[d.ProductID]).Interval)
+			next := s.next(interval)
+
+			retryAt, down, err := s.circuits.RetryAt(ctx, d.Host)
+			if err != nil {
+				// The fetcher still fails fast if the circuit is open
+				s.logger.Warn("Scheduler: circuit of host unknown", slog.String("host", d.Host), slog.String("error", err.Error()))
+			}
+			switch {
+			case d.FirstQueued:
+				// A new product had its first check queued on creation, only plan the next
+			case down:
+				next = s.deferred(retryAt, interval)
+				deferred++
+			default:
+				if err := s.producer.SendProductUpdate(ctx, d.ProductID); err != nil {
+					return fmt.Errorf("error queueing check of product %d: %w", d.ProductID, err)
+				}
+			}
+			if err := s.repo.Reschedule(ctx, d.ProductID, next); err != nil {
+				return fmt.Errorf("error rescheduling product %d: %w", d.ProductID, err)
+			}
+		}
+		if deferred > 0 {
+			s.logger.Info("Scheduler: deferred checks of hosts with an open circuit", slog.Int("count", deferred))
+		}
+		n = len(due)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// deferred is retryAt moved later by up to JitterPercent of the interval, so the
+// products of a host that recovers do not all come back in its first second
+func (s *Scheduler) deferred(retryAt time.Time, interval time.Duration) time.Time {
+	spread := float64(interval) * float64(s.opts.JitterPercent) / 100
+	return retryAt.Add(time.Duration(s.random() * spread))
+}
+
+// signals loads the signals of the products whose interval adapts, nil if none does
+func (s *Scheduler) signals(ctx context.Context, due []*domain.DueCheck) (map[int64]*domain.CheckSignals, error) {
+	var ids []int64
+	for _, d := range due {
+		if s.adapts(d) {
+			ids = append(ids, d.ProductID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	signals, err := s.repo.Signals(ctx, ids, s.now().Add(-s.opts.VolatilityWindow))
+	if err != nil {
+		return nil, fmt.Errorf("error loading signals: %w", err)
+	}
+	return signals, nil
+}
+
+// next is one interval from now, moved by up to JitterPercent of it either way
+func (s *Scheduler) next(interval time.Duration) time.Time {
+	if interval <= 0 {
+		interval = s.opts.DefaultInterval
+	}
+	spread := float64(interval) * float64(s.opts.JitterPercent) / 100
+	return s.now().Add(interval + time.Duration((2*s.random()-1)*spread))
+}
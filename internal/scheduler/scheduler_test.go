@@ -0,0 +1,263 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/derkres11/price-pulse/internal/domain"
+)
+
+// scheduleMock hands out the due products once, like locked rows would be skipped
+type scheduleMock struct {
+	domain.ScheduleRepository
+	due     []*domain.DueCheck
+	signals map[int64]*domain.CheckSignals
+	next    map[int64]time.Time
+}
+
+func (m *scheduleMock) LockDue(ctx context.Context, limit int) ([]*domain.DueCheck, error) {
+	n := min(limit, len(m.due))
+	due := m.due[:n]
+	m.due = m.due[n:]
+	return due, nil
+}
+
+func (m *scheduleMock) Reschedule(ctx context.Context, productID int64, next time.Time) error {
+	m.next[productID] = next
+	return nil
+}
+
+func (m *scheduleMock) Signals(ctx context.Context, productIDs []int64, since time.Time) (map[int64]*domain.CheckSignals, error) {
+	signals := map[int64]*domain.CheckSignals{}
+	for _, id := range productIDs {
+		if s, ok := m.signals[id]; ok {
+			signals[id] = s
+		}
+	}
+	return signals, nil
+}
+
+type txMock struct {
+	committed int
+}
+
+func (m *txMock) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := fn(ctx); err != nil {
+		return err
+	}
+	m.committed++
+	return nil
+}
+
+type producerMock struct {
+	err  error
+	sent []int64
+}
+
+func (p *producerMock) SendProductUpdate(ctx context.Context, id int64) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.sent = append(p.sent, id)
+	return nil
+}
+
+func (p *producerMock) SendProductDeleted(ctx context.Context, id int64) error {
+	return nil
+}
+
+// circuitsMock has the circuits of the hosts in open open until the time given
+type circuitsMock struct {
+	domain.HostCircuits
+	open map[string]time.Time
+}
+
+func (m circuitsMock) RetryAt(ctx context.Context, host string) (time.Time, bool, error) {
+	at, ok := m.open[host]
+	return at, ok, nil
+}
+
+func TestScheduler_ScheduleOnce(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	repo := &scheduleMock{
+		next: map[int64]time.Time{},
+		due: []*domain.DueCheck{
+			{ProductID: 1}, // default interval
+			{ProductID: 2, Interval: 30 * time.Minute}, // own or host interval
+			{ProductID: 3, FirstQueued: true},          // just created
+		},
+	}
+	tx := &txMock{}
+	producer := &producerMock{}
+	s := New(repo, tx, producer, circuitsMock{}, Options{BatchSize: 10, DefaultInterval: 6 * time.Hour}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	s.now = func() time.Time { return now }
+
+	n, err := s.ScheduleOnce(context.Background())
+	if err != nil || n != 3 {
+		t.Fatalf("ScheduleOnce() = %d, %v, want 3", n, err)
+	}
+	if len(producer.sent) != 2 || producer.sent[0] != 1 || producer.sent[1] != 2 {
+		t.Errorf("queued %v, want [1 2]; the new product already has its check", producer.sent)
+	}
+
+	want := map[int64]time.Time{1: now.Add(6 * time.Hour), 2: now.Add(30 * time.Minute), 3: now.Add(6 * time.Hour)}
+	for id, w := range want {
+		if got := repo.next[id]; !got.Equal(w) {
+			t.Errorf("next check of %d = %s, want %s", id, got, w)
+		}
+	}
+	if tx.committed != 1 {
+		t.Errorf("committed %d transactions, want 1", tx.committed)
+	}
+}
+
+func TestScheduler_ScheduleOnceRollsBack(t *testing.T) {
+	repo := &scheduleMock{next: map[int64]time.Time{}, due: []*domain.DueCheck{{ProductID: 1}}}
+	tx := &txMock{}
+	s := New(repo, tx, &producerMock{err: errors.New("outbox full")}, circuitsMock{}, Options{BatchSize: 10, DefaultInterval: time.Hour}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	if _, err := s.ScheduleOnce(context.Background()); err == nil {
+		t.Fatal("ScheduleOnce() error = nil, want the outbox error")
+	}
+	if tx.committed != 0 || len(repo.next) != 0 {
+		t.Errorf("committed %d, rescheduled %v; want nothing when the check could not be queued", tx.committed, repo.next)
+	}
+}
+
+func TestScheduler_ScheduleOnceDefersOpenCircuits(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	retryAt := now.Add(5 * time.Minute)
+	repo := &scheduleMock{
+		next: map[int64]time.Time{},
+		due: []*domain.DueCheck{
+			{ProductID: 1, Host: "down.example"},
+			{ProductID: 2, Host: "up.example"},
+		},
+	}
+	producer := &producerMock{}
+	circuits := circuitsMock{open: map[string]time.Time{"down.example": retryAt}}
+	s := New(repo, &txMock{}, producer, circuits, Options{BatchSize: 10, DefaultInterval: time.Hour, JitterPercent: 10}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	s.now = func() time.Time { return now }
+	s.random = func() float64 { return 0.5 }
+
+	if _, err := s.ScheduleOnce(context.Background()); err != nil {
+		t.Fatalf("ScheduleOnce() error = %v", err)
+	}
+	if len(producer.sent) != 1 || producer.sent[0] != 2 {
+		t.Errorf("queued %v, want [2]; the host with the open circuit is deferred", producer.sent)
+	}
+	if got, want := repo.next[1], retryAt.Add(3*time.Minute); !got.Equal(want) {
+		t.Errorf("next check of 1 = %s, want %s, after the circuit lets trials through", got, want)
+	}
+	if got, want := repo.next[2], now.Add(time.Hour); !got.Equal(want) {
+		t.Errorf("next check of 2 = %s, want %s", got, want)
+	}
+}
+
+func TestScheduler_Next(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	s := &Scheduler{opts: Options{DefaultInterval: time.Hour, JitterPercent: 10}, now: func() time.Time { return now }}
+
+	tests := []struct {
+		name     string
+		random   float64
+		interval time.Duration
+		want     time.Duration
+	}{
+		{"Earliest", 0, 0, 54 * time.Minute},
+		{"Middle", 0.5, 0, time.Hour},
+		{"Latest", 0.999999, 0, 66 * time.Minute},
+		{"Own interval", 0.5, 10 * time.Minute, 10 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s.random = func() float64 { return tt.random }
+			got := s.next(tt.interval).Sub(now)
+			if got < tt.want-time.Second || got > tt.want+time.Second {
+				t.Errorf("next() = now + %s, want about %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduler_ScheduleOnceAdaptive(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	repo := &scheduleMock{
+		next: map[int64]time.Time{},
+		due: []*domain.DueCheck{
+			{ProductID: 1, Source: domain.IntervalFromDefault},
+			{ProductID: 2, Interval: 30 * time.Minute, Source: domain.IntervalFromProduct},
+		},
+		signals: map[int64]*domain.CheckSignals{
+			1: {Observations: 10}, // stable, no target, no subscribers: twice the default
+			2: {Observations: 10, Changes: 10, Subscribers: 100},
+		},
+	}
+	opts := Options{
+		BatchSize:        10,
+		DefaultInterval:  6 * time.Hour,
+		Adaptive:         true,
+		MinInterval:      15 * time.Minute,
+		MaxInterval:      24 * time.Hour,
+		VolatilityWindow: 7 * 24 * time.Hour,
+	}
+	s := New(repo, &txMock{}, &producerMock{}, circuitsMock{}, opts, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	s.now = func() time.Time { return now }
+
+	if _, err := s.ScheduleOnce(context.Background()); err != nil {
+		t.Fatalf("ScheduleOnce() error = %v", err)
+	}
+	want := map[int64]time.Time{1: now.Add(12 * time.Hour), 2: now.Add(30 * time.Minute)}
+	for id, w := range want {
+		if got := repo.next[id]; !got.Equal(w) {
+			t.Errorf("next check of %d = %s, want %s; an interval set on the product is kept", id, got, w)
+		}
+	}
+}
+
+func TestScheduler_Plan(t *testing.T) {
+	s := &Scheduler{opts: Options{
+		DefaultInterval:  4 * time.Hour,
+		Adaptive:         true,
+		MinInterval:      15 * time.Minute,
+		MaxInterval:      24 * time.Hour,
+		VolatilityWindow: 7 * 24 * time.Hour,
+	}}
+	host := &domain.DueCheck{ProductID: 1, Interval: 2 * time.Hour, Source: domain.IntervalFromHost}
+
+	tests := []struct {
+		name    string
+		check   *domain.DueCheck
+		signals *domain.CheckSignals
+		want    time.Duration
+		bounded string
+	}{
+		{"No signals", host, nil, 2 * time.Hour, ""},
+		{"Too little history", host, &domain.CheckSignals{Observations: 2, Changes: 2}, 2 * time.Hour, ""},
+		{"Stable", host, &domain.CheckSignals{Observations: 20}, 4 * time.Hour, ""},
+		{"Changes every eighth check", host, &domain.CheckSignals{Observations: 16, Changes: 2}, 2 * time.Hour, ""},
+		{"Right above target", host, &domain.CheckSignals{Price: 100, Target: 100 - 1e-9}, time.Hour, ""},
+		{"Far above target", host, &domain.CheckSignals{Price: 100, Target: 50}, 2 * time.Hour, ""},
+		{"Target reached", host, &domain.CheckSignals{Price: 40, Target: 50}, 2 * time.Hour, ""},
+		{"Fifteen subscribers", host, &domain.CheckSignals{Subscribers: 15}, time.Hour, ""},
+		{"Default interval", &domain.DueCheck{ProductID: 1, Source: domain.IntervalFromDefault}, &domain.CheckSignals{}, 4 * time.Hour, ""},
+		{"Raised to min", host, &domain.CheckSignals{Observations: 10, Changes: 10, Price: 10, Target: 9.99, Subscribers: 1000}, 15 * time.Minute, "min"},
+		{"Lowered to max", &domain.DueCheck{ProductID: 1, Interval: 20 * time.Hour, Source: domain.IntervalFromHost}, &domain.CheckSignals{Observations: 10}, 24 * time.Hour, "max"},
+		{"Own interval", &domain.DueCheck{ProductID: 1, Interval: time.Minute, Source: domain.IntervalFromProduct}, &domain.CheckSignals{Observations: 10}, time.Minute, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := s.plan(tt.check, tt.signals)
+			got := time.Duration(p.Interval)
+			if got < tt.want-time.Second || got > tt.want+time.Second || p.Bounded != tt.bounded {
+				t.Errorf("plan() = %s bounded %q, want %s bounded %q; factors %+v", got, p.Bounded, tt.want, tt.bounded, p.Factors)
+			}
+		})
+	}
+}
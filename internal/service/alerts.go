@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/derkres11/price-pulse/internal/alerts"
+	"github.com/derkres11/price-pulse/internal/domain"
+)
+
+const defaultAlertEventsLimit = 50
+
+// buildObservation collects the history the alert rules need.
+// Lookups that fail only weaken the rules that depend on them, so errors are logged.
+func (s *ProductService) buildObservation(ctx context.Context, productID int64, info *domain.PriceInfo) alerts.Observation {
+	obs := alerts.Observation{
+		ProductID:  productID,
+		Price:      info.Price,
+		InStock:    info.InStock,
+		ObservedAt: time.Now(),
+	}
+
+	var err error
+	if obs.Previous, err = s.history.GetLatest(ctx, productID); err != nil {
+		s.logger.Error("failed to load previous observation", slog.Int64("id", productID), slog.String("error", err.Error()))
+	}
+	if obs.AllTimeLow, err = s.history.GetLowest(ctx, productID); err != nil {
+		s.logger.Error("failed to load all-time low", slog.Int64("id", productID), slog.String("error", err.Error()))
+	}
+	if info.InStock {
+		if obs.UnchangedSince, err = s.history.GetUnchangedSince(ctx, productID, info.Price); err != nil {
+			s.logger.Error("failed to load price streak", slog.Int64("id", productID), slog.String("error", err.Error()))
+		}
+	}
+
+	return obs
+}
+
+// evaluateAlerts runs the product's rules against the new observation, stores the events
+// and queues them for the notification channels
+func (s *ProductService) evaluateAlerts(ctx context.Context, p *domain.Product, obs alerts.Observation, currency string) {
+	rules, err := s.alerts.ListRules(ctx, p.ID)
+	if err != nil {
+		s.logger.Error("failed to load alert rules", slog.Int64("id", p.ID), slog.String("error", err.Error()))
+		return
+	}
+	if target := alerts.TargetPriceRule(p); target != nil {
+		rules = append(rules, target)
+	}
+
+	// Every subscriber brings their own target, their events only go to their channels
+	subs, err := s.subs.ListByProduct(ctx, p.ID)
+	if err != nil {
+		s.logger.Error("failed to load subscriptions", slog.Int64("id", p.ID), slog.String("error", err.Error()))
+	}
+	owners := make(map[int64]int64, len(subs))
+	for _, sub := range subs {
+		owners[sub.ID] = sub.UserID
+		if target := alerts.SubscriptionTargetRule(sub); target != nil {
+			rules = append(rules, target)
+		}
+	}
+
+	var events []*domain.AlertEvent
+	for _, e := range alerts.Evaluate(rules, obs) {
+		if e.SubscriptionID != 0 {
+			userID, ok := owners[e.SubscriptionID]
+			if !ok {
+				continue // unsubscribed since the rules were loaded
+			}
+			e.UserID = userID
+		}
+		events = append(events, e)
+	}
+
+	// Rules that no longer match may alert again on channels using hysteresis.
+	// An out of stock page says nothing about the price, so it re-arms nothing.
+	if obs.InStock {
+		if err := s.alerts.RearmRules(ctx, p.ID, events); err != nil {
+			s.logger.Error("failed to re-arm alert rules", slog.Int64("id", p.ID), slog.String("error", err.Error()))
+		}
+	}
+
+	for _, e := range events {
+		if err := s.alerts.SaveEvent(ctx, e); err != nil {
+			s.logger.Error("failed to save alert event", slog.Int64("id", p.ID), slog.String("error", err.Error()))
+			continue
+		}
+
+		s.logger.Info("price alert",
+			slog.Int64("id", p.ID),
+			slog.String("type", string(e.Type)),
+			slog.String("reason", e.Reason))
+
+		s.publish(ctx, &domain.PriceEvent{
+			Type:       domain.PriceEventAlert,
+			ProductID:  p.ID,
+			Title:      p.Title,
+			Price:      e.Price,
+			Currency:   currency,
+			InStock:    obs.InStock,
+			Alert:      e,
+			ObservedAt: obs.ObservedAt,
+		})
+
+		n := &domain.Notification{Event: e, Title: p.Title, URL: p.URL, Currency: currency}
+		if err := s.notifier.SendNotification(ctx, n); err != nil {
+			s.logger.Error("failed to queue notification", slog.Int64("id", p.ID), slog.String("error", err.Error()))
+		}
+	}
+}
+
+func (s *ProductService) CreateAlertRule(ctx context.Context, rule *domain.AlertRule) error {
+	p, err := s.repo.GetByID(ctx, rule.ProductID)
+	if err != nil {
+		return err
+	}
+
+	// Without an explicit baseline a percent drop is measured from today's price
+	if rule.Type == domain.AlertPercentDrop && rule.Baseline == 0 {
+		rule.Baseline = p.CurrentPrice
+	}
+
+	if err := alerts.Validate(rule); err != nil {
+		return err
+	}
+
+	if err := s.alerts.CreateRule(ctx, rule); err != nil {
+		return fmt.Errorf("error creating alert rule: %w", err)
+	}
+	return nil
+}
+
+// ListAlertRules returns the product level rules, subscription rules belong to their owners
+func (s *ProductService) ListAlertRules(ctx context.Context, productID int64) ([]*domain.AlertRule, error) {
+	rules, err := s.alerts.ListRules(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	own := rules[:0]
+	for _, r := range rules {
+		if r.SubscriptionID == 0 {
+			own = append(own, r)
+		}
+	}
+	return own, nil
+}
+
+func (s *ProductService) DeleteAlertRule(ctx context.Context, productID, ruleID int64) error {
+	return s.alerts.DeleteRule(ctx, productID, ruleID)
+}
+
+// ListAlertEvents leaves out the alerts of other users' subscriptions
+func (s *ProductService) ListAlertEvents(ctx context.Context, userID, productID int64, limit int) ([]*domain.AlertEvent, error) {
+	if limit <= 0 || limit > defaultAlertEventsLimit {
+		limit = defaultAlertEventsLimit
+	}
+	return s.alerts.ListEvents(ctx, productID, userID, limit)
+}
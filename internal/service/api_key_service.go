@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/mail"
+	"strings"
+
+	"github.com/derkres11/price-pulse/internal/auth"
+	"github.com/derkres11/price-pulse/internal/domain"
+)
+
+// ErrForbidden is returned when a caller asks for more than it is allowed to
+var ErrForbidden = domain.NewError(domain.ErrPermissionDenied, "forbidden")
+
+type APIKeyService struct {
+	keys   domain.APIKeyRepository
+	users  domain.UserRepository
+	tx     domain.Transactor
+	logger *slog.Logger
+}
+
+func NewAPIKeyService(keys domain.APIKeyRepository, users domain.UserRepository, tx domain.Transactor, logger *slog.Logger) *APIKeyService {
+	return &APIKeyService{
+		keys:   keys,
+		users:  users,
+		tx:     tx,
+		logger: logger,
+	}
+}
+
+// CreateKey issues a key for a user and returns it in plain text, the only time it is visible.
+// Only admins may issue admin keys.
+func (s *APIKeyService) CreateKey(ctx context.Context, userID int64, name string, admin bool) (*domain.APIKey, string, error) {
+	if admin {
+		if p := domain.PrincipalFromContext(ctx); p == nil || !p.Admin {
+			return nil, "", fmt.Errorf("%w: only admins can create admin keys", ErrForbidden)
+		}
+	}
+	return s.issue(ctx, userID, name, admin)
+}
+
+func (s *APIKeyService) issue(ctx context.Context, userID int64, name string, admin bool) (*domain.APIKey, string, error) {
+	plain, prefix, hash, err := auth.GenerateAPIKey()
+	if err != nil {
+		return nil, "", err
+	}
+
+	k := &domain.APIKey{UserID: userID, Name: name, Prefix: prefix, Hash: hash, Admin: admin}
+	if err := s.keys.Create(ctx, k); err != nil {
+		return nil, "", fmt.Errorf("error creating api key: %w", err)
+	}
+
+	s.logger.Info("api key created", slog.Int64("user_id", userID), slog.Int64("key_id", k.ID), slog.Bool("admin", admin))
+	return k, plain, nil
+}
+
+// Bootstrap makes sure the user with email exists and holds key as an admin key, so the
+// first admin gets in without anybody to issue them one. It runs on every start: a key
+// already in place is left alone, a revoked one stays revoked.
+func (s *APIKeyService) Bootstrap(ctx context.Context, email, key string) error {
+	prefix, ok := auth.ParseAPIKey(key)
+	if !ok {
+		return errors.New("bootstrap admin key must look like pp_<12 hex digits>_<secret>")
+	}
+	addr, err := mail.ParseAddress(email)
+	if err != nil {
+		return fmt.Errorf("bootstrap admin email: %w", err)
+	}
+	email = strings.ToLower(addr.Address)
+	hash := auth.HashAPIKey(key)
+
+	err = s.tx.InTx(ctx, func(ctx context.Context) error {
+		existing, err := s.keys.GetByPrefix(ctx, prefix)
+		switch {
+		case err == nil:
+			if existing.Hash != hash || !existing.Admin {
+				return fmt.Errorf("bootstrap admin key: prefix %s belongs to another key", prefix)
+			}
+			if existing.RevokedAt != nil {
+				s.logger.Warn("bootstrap admin key is revoked", slog.Int64("key_id", existing.ID))
+			}
+			return nil
+		case !errors.Is(err, domain.ErrNotFound):
+			return fmt.Errorf("error looking up bootstrap key: %w", err)
+		}
+
+		u, err := s.users.GetByEmail(ctx, email)
+		if errors.Is(err, domain.ErrNotFound) {
+			u = &domain.User{Email: email, Name: "admin"}
+			err = s.users.Create(ctx, u)
+		}
+		if err != nil {
+			return fmt.Errorf("error creating bootstrap admin: %w", err)
+		}
+
+		k := &domain.APIKey{UserID: u.ID, Name: "bootstrap", Prefix: prefix, Hash: hash, Admin: true}
+		if err := s.keys.Create(ctx, k); err != nil {
+			return fmt.Errorf("error creating bootstrap key: %w", err)
+		}
+		s.logger.Info("bootstrap admin key created", slog.Int64("user_id", u.ID), slog.Int64("key_id", k.ID))
+		return nil
+	})
+	// Another replica bootstrapped at the same time
+	if errors.Is(err, domain.ErrAlreadyExists) {
+		return nil
+	}
+	return err
+}
+
+func (s *APIKeyService) ListKeys(ctx context.Context, userID int64) ([]*domain.APIKey, error) {
+	return s.keys.ListByUser(ctx, userID)
+}
+
+func (s *APIKeyService) RevokeKey(ctx context.Context, userID, id int64) error {
+	if err := s.keys.Revoke(ctx, userID, id); err != nil {
+		return err
+	}
+
+	s.logger.Info("api key revoked", slog.Int64("user_id", userID), slog.Int64("key_id", id))
+	return nil
+}
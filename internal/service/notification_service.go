@@ -0,0 +1,306 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/derkres11/price-pulse/internal/alerts"
+	"github.com/derkres11/price-pulse/internal/domain"
+	"github.com/derkres11/price-pulse/internal/notify"
+)
+
+// ErrInvalidChannel is returned for channels that could never deliver anything
+var ErrInvalidChannel = domain.NewError(domain.ErrInvalidArgument, "invalid notification channel")
+
+// Defaults for subscriptions and users that never set a delivery policy
+const (
+	DefaultCooldownMinutes = 6 * 60
+	defaultTimezone        = "UTC"
+)
+
+// claimFactor sizes a claim on an alert or digest: every channel gets the dispatch
+// timeout, in parallel, plus as much again for storing the result
+const claimFactor = 2
+
+type NotificationService struct {
+	channels  domain.ChannelRepository
+	state     domain.AlertStateRepository
+	notifiers map[domain.ChannelType]domain.Notifier
+	operator  domain.AlertPolicy
+	timeout   time.Duration
+	logger    *slog.Logger
+	now       func() time.Time
+}
+
+// NewNotificationService applies operator to product level alerts, subscriptions and
+// their owners carry their own policy
+func NewNotificationService(
+	channels domain.ChannelRepository,
+	state domain.AlertStateRepository,
+	notifiers map[domain.ChannelType]domain.Notifier,
+	operator domain.AlertPolicy,
+	timeout time.Duration,
+	logger *slog.Logger,
+) *NotificationService {
+	return &NotificationService{
+		channels:  channels,
+		state:     state,
+		notifiers: notifiers,
+		operator:  operator,
+		timeout:   timeout,
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+// CreateChannel stores a channel for ch.UserID, 0 makes it an operator channel
+func (s *NotificationService) CreateChannel(ctx context.Context, ch *domain.NotificationChannel) error {
+	if err := s.validateChannel(ch); err != nil {
+		return err
+	}
+
+	if err := s.channels.Create(ctx, ch); err != nil {
+		return fmt.Errorf("error creating channel: %w", err)
+	}
+
+	s.logger.Info("notification channel created", slog.Int64("id", ch.ID), slog.String("type", string(ch.Type)))
+	return nil
+}
+
+func (s *NotificationService) ListChannels(ctx context.Context, userID int64) ([]*domain.NotificationChannel, error) {
+	return s.channels.ListByUser(ctx, userID)
+}
+
+func (s *NotificationService) DeleteChannel(ctx context.Context, userID, id int64) error {
+	return s.channels.Delete(ctx, userID, id)
+}
+
+// Dispatch delivers one notification to every enabled channel of its subscriber.
+// The subscription's cooldown, re-arm and quiet hours are applied against its stored
+// state first, which is claimed in a short transaction: the alert is delivered after
+// the commit and recorded in a second one, no row stays locked over the network.
+// Subscription alerts only reach their owner's channels, product level alerts only
+// the operator channels.
+func (s *NotificationService) Dispatch(ctx context.Context, n *domain.Notification) error {
+	e := n.Event
+	policy, err := s.policy(ctx, e.SubscriptionID)
+	if errors.Is(err, domain.ErrNotFound) {
+		s.logger.Debug("notification for a deleted subscription dropped", slog.Int64("subscription_id", e.SubscriptionID))
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error loading delivery policy: %w", err)
+	}
+
+	var claim *domain.AlertState
+	err = s.state.WithState(ctx, e.SubscriptionID, e.ProductID, e.RuleID, func(ctx context.Context, st *domain.AlertState) (*domain.AlertState, error) {
+		now := s.now()
+
+		decision, reason := alerts.Throttle(st, e, policy, now)
+		switch decision {
+		case alerts.Suppress:
+			s.logger.Debug("notification suppressed",
+				slog.Int64("subscription_id", e.SubscriptionID),
+				slog.Int64("product_id", e.ProductID),
+				slog.String("reason", reason))
+			return nil, nil
+		case alerts.Defer:
+			if err := s.state.QueueDigest(ctx, e.UserID, n); err != nil {
+				return nil, fmt.Errorf("failed to queue digest: %w", err)
+			}
+			return alerts.Sent(e, now), nil
+		}
+
+		claim = alerts.Claim(st, e, now.Add(claimFactor*s.timeout))
+		return claim, nil
+	})
+	if err != nil || claim == nil {
+		return err
+	}
+
+	channels, err := s.channels.GetEnabled(ctx)
+	if err != nil {
+		err = fmt.Errorf("error loading channels: %w", err)
+	}
+	var delivered int
+	if err == nil {
+		delivered, err = s.send(ctx, ownedBy(channels, e.UserID), n, func(ch *domain.NotificationChannel) (string, error) {
+			return notify.Render(ch, n)
+		})
+	}
+
+	// The claim has to end even when the consumer is shutting down
+	ctx = context.WithoutCancel(ctx)
+	if delivered == 0 {
+		if releaseErr := s.state.ReleaseClaim(ctx, claim); releaseErr != nil {
+			err = errors.Join(err, fmt.Errorf("error releasing claim: %w", releaseErr))
+		}
+		return err
+	}
+	// A channel that failed is not tried again, the others already told the subscriber
+	if recordErr := s.state.RecordSent(ctx, claim, alerts.Sent(e, s.now())); recordErr != nil {
+		err = errors.Join(err, fmt.Errorf("error recording delivery: %w", recordErr))
+	}
+	return err
+}
+
+// policy is the operator's for product level alerts, else the subscription's
+func (s *NotificationService) policy(ctx context.Context, subscriptionID int64) (*domain.AlertPolicy, error) {
+	if subscriptionID == 0 {
+		p := s.operator
+		return &p, nil
+	}
+	return s.state.Policy(ctx, subscriptionID)
+}
+
+// FlushDigests sends the queued alerts of every user whose quiet hours are over.
+// Meant to be called periodically, the queue itself lives in Postgres.
+func (s *NotificationService) FlushDigests(ctx context.Context) error {
+	users, err := s.state.UsersWithDigest(ctx)
+	if err != nil || len(users) == 0 {
+		return err
+	}
+
+	channels, err := s.channels.GetEnabled(ctx)
+	if err != nil {
+		return fmt.Errorf("error loading channels: %w", err)
+	}
+
+	var errs []error
+	for _, u := range users {
+		quiet := &u.QuietHours
+		if u.ID == 0 {
+			quiet = &s.operator.QuietHours
+		}
+		owned := ownedBy(channels, u.ID)
+		// Users without an enabled channel keep their queue until they enable one
+		if len(owned) == 0 || alerts.InQuietHours(quiet, s.now()) {
+			continue
+		}
+
+		err := s.state.FlushDigest(ctx, u.ID, s.now().Add(claimFactor*s.timeout), func(items []*domain.Notification) error {
+			digest := &domain.Notification{
+				Title:  fmt.Sprintf("%d alerts during quiet hours", len(items)),
+				Digest: items,
+			}
+			delivered, err := s.send(ctx, owned, digest, func(ch *domain.NotificationChannel) (string, error) {
+				return notify.RenderDigest(ch, items)
+			})
+			if delivered > 0 {
+				return nil
+			}
+			return err
+		})
+		if err != nil {
+			s.logger.Error("digest delivery failed", slog.Int64("user_id", u.ID), slog.String("error", err.Error()))
+			errs = append(errs, fmt.Errorf("user %d: %w", u.ID, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// send delivers n to the channels in parallel, each with its own timeout, so one dead
+// endpoint only costs its own delivery. It returns how many channels got it.
+func (s *NotificationService) send(
+	ctx context.Context,
+	channels []*domain.NotificationChannel,
+	n *domain.Notification,
+	render func(ch *domain.NotificationChannel) (string, error),
+) (int, error) {
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		delivered int
+		errs      []error
+	)
+
+	for _, ch := range channels {
+		wg.Add(1)
+		go func(ch *domain.NotificationChannel) {
+			defer wg.Done()
+
+			text, err := render(ch)
+			if err == nil {
+				err = s.deliver(ctx, ch, text, n)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				s.logger.Error("notification delivery failed",
+					slog.Int64("channel_id", ch.ID),
+					slog.String("type", string(ch.Type)),
+					slog.String("error", err.Error()))
+				errs = append(errs, fmt.Errorf("channel %d: %w", ch.ID, err))
+				return
+			}
+			delivered++
+		}(ch)
+	}
+	wg.Wait()
+
+	return delivered, errors.Join(errs...)
+}
+
+func ownedBy(channels []*domain.NotificationChannel, userID int64) []*domain.NotificationChannel {
+	var owned []*domain.NotificationChannel
+	for _, ch := range channels {
+		if ch.UserID == userID {
+			owned = append(owned, ch)
+		}
+	}
+	return owned
+}
+
+func (s *NotificationService) deliver(ctx context.Context, ch *domain.NotificationChannel, text string, n *domain.Notification) error {
+	notifier, ok := s.notifiers[ch.Type]
+	if !ok {
+		return fmt.Errorf("no notifier configured for %q", ch.Type)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	return notifier.Send(ctx, ch, text, n)
+}
+
+func (s *NotificationService) validateChannel(ch *domain.NotificationChannel) error {
+	if _, ok := s.notifiers[ch.Type]; !ok {
+		return fmt.Errorf("%w: unsupported type %q", ErrInvalidChannel, ch.Type)
+	}
+	if ch.Target == "" {
+		return fmt.Errorf("%w: target is required", ErrInvalidChannel)
+	}
+
+	switch ch.Type {
+	case domain.ChannelWebhook:
+		u, err := url.Parse(ch.Target)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%w: target must be an http(s) url", ErrInvalidChannel)
+		}
+		// Names are checked again on every dial, this only turns the obvious ones away early
+		host := strings.ToLower(u.Hostname())
+		if ip, err := netip.ParseAddr(host); (err == nil && !notify.PublicAddr(ip)) || host == "localhost" || strings.HasSuffix(host, ".localhost") {
+			return fmt.Errorf("%w: target must be a public address", ErrInvalidChannel)
+		}
+	case domain.ChannelEmail:
+		// A bare address only, no display name or angle brackets
+		if addr, err := mail.ParseAddress(ch.Target); err != nil || addr.Name != "" || addr.Address != ch.Target {
+			return fmt.Errorf("%w: target must be a bare email address", ErrInvalidChannel)
+		}
+	}
+
+	if _, err := notify.ParseTemplate(ch.Template); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidChannel, err)
+	}
+	return nil
+}
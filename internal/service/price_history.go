@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/derkres11/price-pulse/internal/domain"
+)
+
+// maxHistoryBuckets keeps a single history request from aggregating into a huge response
+const maxHistoryBuckets = 5000
+
+// ErrInvalidHistoryQuery is returned for ranges and steps the history API refuses to serve
+var ErrInvalidHistoryQuery = domain.NewError(domain.ErrInvalidArgument, "invalid history query")
+
+// recordObservation appends the fetch result to price_history.
+// A failed write must not stop the price update, so it is only logged.
+func (s *ProductService) recordObservation(ctx context.Context, productID int64, info *domain.PriceInfo) {
+	point := &domain.PricePoint{
+		ProductID: productID,
+		Price:     info.Price,
+		Currency:  info.Currency,
+		InStock:   info.InStock,
+		Source:    info.Source,
+	}
+
+	if err := s.history.AddPoint(ctx, point); err != nil {
+		s.logger.Error("failed to record price observation",
+			slog.Int64("id", productID),
+			slog.String("error", err.Error()))
+	}
+}
+
+// GetPriceHistory returns raw observations between from and to, or min/max/avg/last
+// buckets of the given step when step is non-zero.
+func (s *ProductService) GetPriceHistory(ctx context.Context, id int64, from, to time.Time, step time.Duration) (*domain.PriceHistory, error) {
+	if !from.Before(to) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidHistoryQuery)
+	}
+	if step < 0 || (step > 0 && to.Sub(from)/step > maxHistoryBuckets) {
+		return nil, fmt.Errorf("%w: at most %d buckets per request", ErrInvalidHistoryQuery, maxHistoryBuckets)
+	}
+
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	h := &domain.PriceHistory{ProductID: id, From: from, To: to}
+
+	var err error
+	if step > 0 {
+		h.Step = step.String()
+		h.Buckets, err = s.history.GetBuckets(ctx, id, from, to, step)
+	} else {
+		h.Points, err = s.history.GetRange(ctx, id, from, to)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error loading price history for product %d: %w", id, err)
+	}
+
+	h.AllTimeLow, err = s.history.GetLowest(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("error loading lowest price for product %d: %w", id, err)
+	}
+
+	return h, nil
+}
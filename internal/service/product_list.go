@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/derkres11/price-pulse/internal/domain"
+)
+
+// ErrInvalidProductQuery is returned for listings and updates that make no sense
+var ErrInvalidProductQuery = domain.NewError(domain.ErrInvalidArgument, "invalid product query")
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// pageCursor is what the opaque cursor string carries. Sort and order are part of
+// it so a cursor cannot be replayed against a different ordering.
+type pageCursor struct {
+	domain.ProductCursor
+	Sort domain.ProductSort `json:"s"`
+	Desc bool               `json:"d,omitempty"`
+}
+
+// ListProducts returns one page of products. cursor is the NextCursor of the previous
+// page and must be used with the same sort order.
+func (s *ProductService) ListProducts(ctx context.Context, q domain.ProductQuery, cursor string) (*domain.ProductPage, error) {
+	if q.Sort == "" {
+		q.Sort = domain.SortByID
+	}
+	switch q.Sort {
+	case domain.SortByID, domain.SortByCreated, domain.SortByUpdated, domain.SortByPrice, domain.SortByTitle:
+	default:
+		return nil, fmt.Errorf("%w: unknown sort %q", ErrInvalidProductQuery, q.Sort)
+	}
+
+	if q.MinPrice < 0 || q.MaxPrice < 0 || (q.MaxPrice > 0 && q.MinPrice > q.MaxPrice) {
+		return nil, fmt.Errorf("%w: bad price range", ErrInvalidProductQuery)
+	}
+
+	if q.Limit <= 0 {
+		q.Limit = defaultPageSize
+	}
+	if q.Limit > maxPageSize {
+		q.Limit = maxPageSize
+	}
+
+	if cursor != "" {
+		after, err := decodeCursor(cursor, q.Sort, q.Desc)
+		if err != nil {
+			return nil, err
+		}
+		q.After = after
+	}
+
+	// One extra row tells whether there is a next page
+	limit := q.Limit
+	q.Limit++
+
+	products, err := s.repo.List(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("error listing products: %w", err)
+	}
+
+	page := &domain.ProductPage{Items: products}
+	if len(products) > limit {
+		page.Items = products[:limit]
+		page.NextCursor = encodeCursor(page.Items[limit-1], q.Sort, q.Desc)
+	}
+	if page.Items == nil {
+		page.Items = []*domain.Product{}
+	}
+	return page, nil
+}
+
+// UpdateProduct applies the patch to the product locked in a transaction, so concurrent
+// patches of other fields are kept
+func (s *ProductService) UpdateProduct(ctx context.Context, id int64, patch domain.ProductPatch) (*domain.Product, error) {
+	if err := validatePatch(patch); err != nil {
+		return nil, err
+	}
+
+	var p *domain.Product
+	err := s.tx.InTx(ctx, func(ctx context.Context) error {
+		var err error
+		if p, err = s.repo.GetForUpdate(ctx, id); err != nil {
+			return err
+		}
+
+		if patch.Title != nil {
+			p.Title = strings.TrimSpace(*patch.Title)
+		}
+		if patch.TargetPrice != nil {
+			p.TargetPrice = *patch.TargetPrice
+		}
+		if patch.CheckInterval != nil {
+			p.CheckInterval = *patch.CheckInterval
+		}
+
+		if err := s.repo.Update(ctx, p); err != nil {
+			return fmt.Errorf("error updating product %d: %w", id, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.invalidate(ctx, id)
+
+	s.logger.Info("product updated", slog.Int64("id", id))
+	return p, nil
+}
+
+func validatePatch(patch domain.ProductPatch) error {
+	if patch.Title != nil && strings.TrimSpace(*patch.Title) == "" {
+		return fmt.Errorf("%w: title must not be empty", ErrInvalidProductQuery)
+	}
+	if patch.TargetPrice != nil && *patch.TargetPrice < 0 {
+		return fmt.Errorf("%w: target price must not be negative", ErrInvalidProductQuery)
+	}
+	if patch.CheckInterval != nil {
+		if d := time.Duration(*patch.CheckInterval); d != 0 {
+			return validateInterval(d)
+		}
+	}
+	return nil
+}
+
+// DeleteProduct removes the product with its history, rules and subscriptions and
+// announces it through the outbox, in the same transaction
+func (s *ProductService) DeleteProduct(ctx context.Context, id int64) error {
+	err := s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Delete(ctx, id); err != nil {
+			return err
+		}
+		return s.producer.SendProductDeleted(ctx, id)
+	})
+	if err != nil {
+		return err
+	}
+	s.invalidate(ctx, id)
+
+	s.logger.Info("product deleted", slog.Int64("id", id))
+	return nil
+}
+
+// invalidate drops cached copies of a product, a stale cache only costs a database read
+func (s *ProductService) invalidate(ctx context.Context, id int64) {
+	if err := s.cache.Delete(ctx, id); err != nil {
+		s.logger.Warn("failed to invalidate product cache", slog.Int64("id", id), slog.String("error", err.Error()))
+	}
+}
+
+func encodeCursor(last *domain.Product, sort domain.ProductSort, desc bool) string {
+	c := pageCursor{ProductCursor: domain.ProductCursor{ID: last.ID}, Sort: sort, Desc: desc}
+
+	switch sort {
+	case domain.SortByID:
+		c.Value = strconv.FormatInt(last.ID, 10)
+	case domain.SortByCreated:
+		c.Value = last.CreatedAt.Format(time.RFC3339Nano)
+	case domain.SortByUpdated:
+		c.Value = last.UpdatedAt.Format(time.RFC3339Nano)
+	case domain.SortByPrice:
+		c.Value = strconv.FormatFloat(last.CurrentPrice, 'f', -1, 64)
+	case domain.SortByTitle:
+		c.Value = last.Title
+	}
+
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(cursor string, sort domain.ProductSort, desc bool) (*domain.ProductCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidProductQuery)
+	}
+
+	var c pageCursor
+	if err := json.Unmarshal(raw, &c); err != nil || c.ID <= 0 {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidProductQuery)
+	}
+	if c.Sort != sort || c.Desc != desc {
+		return nil, fmt.Errorf("%w: cursor belongs to a different sort order", ErrInvalidProductQuery)
+	}
+	return &c.ProductCursor, nil
+}
@@ -2,110 +2,205 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"log/slog"
+	"strconv"
 
 	"github.com/derkres11/price-pulse/internal/domain"
 )
 
+// ErrInvalidProduct is returned for products that could never be fetched
+var ErrInvalidProduct = domain.NewError(domain.ErrInvalidArgument, "invalid product")
+
 type ProductService struct {
 	repo     domain.ProductRepository
+	history  domain.PriceHistoryRepository
+	alerts   domain.AlertRepository
+	subs     domain.SubscriptionRepository
+	tx       domain.Transactor
 	producer domain.TaskProducer
+	notifier domain.NotificationProducer
+	observed domain.ObservationProducer
+	events   domain.PriceEventPublisher
 	cache    domain.ProductCache
+	fetcher  domain.PriceFetcher
 	logger   *slog.Logger
 }
 
 func NewProductService(
 	repo domain.ProductRepository,
+	history domain.PriceHistoryRepository,
+	alerts domain.AlertRepository,
+	subs domain.SubscriptionRepository,
+	tx domain.Transactor,
 	producer domain.TaskProducer,
+	notifier domain.NotificationProducer,
+	observed domain.ObservationProducer,
+	events domain.PriceEventPublisher,
 	cache domain.ProductCache,
+	fetcher domain.PriceFetcher,
 	logger *slog.Logger,
 ) *ProductService {
 	return &ProductService{
 		repo:     repo,
+		history:  history,
+		alerts:   alerts,
+		subs:     subs,
+		tx:       tx,
 		producer: producer,
+		notifier: notifier,
+		observed: observed,
+		events:   events,
 		cache:    cache,
+		fetcher:  fetcher,
 		logger:   logger,
 	}
 }
 
+// Create stores the product and queues its first check in the same transaction,
+// the producer is the outbox and the relay takes it to Kafka
 func (s *ProductService) Create(ctx context.Context, p *domain.Product) error {
+	if err := validateURL(p.URL); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidProduct, err)
+	}
+	if p.TargetPrice < 0 {
+		return fmt.Errorf("%w: target price must not be negative", ErrInvalidProduct)
+	}
+
 	s.logger.Info("creating new product", slog.String("url", p.URL))
 
-	//Save to DB
-	if err := s.repo.Create(ctx, p); err != nil {
-		s.logger.Error("failed to create product in db",
+	err := s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Create(ctx, p); err != nil {
+			return err
+		}
+		return s.producer.SendProductUpdate(ctx, p.ID)
+	})
+	if err != nil {
+		s.logger.Error("failed to create product",
 			slog.String("error", err.Error()),
 			slog.String("url", p.URL))
 		return err
 	}
 
-	//Sending to Kafka
-	if err := s.producer.SendProductUpdate(ctx, p.ID); err != nil {
-		s.logger.Error("failed to send kafka notification",
-			slog.Int64("id", p.ID),
-			slog.String("error", err.Error()))
-
-	}
-
 	return nil
 }
-func (s *ProductService) TrackProduct(ctx context.Context, url string, target_price float64) error {
-	p := &domain.Product{
-		URL:         url,
-		TargetPrice: target_price,
-		Title:       "Pending...",
-	}
-
-	err := s.repo.Create(ctx, p)
-	if err != nil {
-		return err
-	}
 
-	return s.producer.SendProductUpdate(ctx, p.ID)
-}
+// checkBatchSize is how many products CheckPrices holds in memory at a time
+const checkBatchSize = 500
 
 func (s *ProductService) CheckPrices(ctx context.Context) error {
-	products, err := s.repo.GetAll(ctx)
-	if err != nil {
-		return fmt.Errorf("error fetching products: %w", err)
-	}
+	q := domain.ProductQuery{Sort: domain.SortByID, Limit: checkBatchSize}
 
-	for _, p := range products {
-		newPrice, err := s.mockFetchPrice(p.URL)
+	for {
+		products, err := s.repo.List(ctx, q)
 		if err != nil {
-			continue
+			return fmt.Errorf("error fetching products: %w", err)
 		}
 
-		if newPrice == p.CurrentPrice {
-			continue
+		for _, p := range products {
+			if err := s.checkProduct(ctx, p); err != nil {
+				log.Printf("error checking product %d: %v", p.ID, err)
+			}
 		}
 
-		err = s.repo.UpdatePrice(ctx, p.ID, newPrice)
-		if err != nil {
-			log.Printf("error updating price for product %d: %v", p.ID, err)
-			continue
+		if len(products) < checkBatchSize {
+			return nil
 		}
+		last := products[len(products)-1]
+		q.After = &domain.ProductCursor{Value: strconv.FormatInt(last.ID, 10), ID: last.ID}
+	}
+}
 
-		if newPrice <= p.TargetPrice {
-			log.Printf("Price alert for product %d! Current price: %.2f, Target price: %.2f", p.ID, newPrice, p.TargetPrice)
-		}
+// ProcessSingleProduct is the core logic for the Watcher
+func (s *ProductService) ProcessSingleProduct(ctx context.Context, id int64) error {
+	s.logger.Info("Watcher: processing product", append(requestAttrs(ctx), slog.Int64("product_id", id))...)
+
+	p, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("error loading product %d: %w", id, err)
 	}
-	return nil
+
+	return s.checkProduct(ctx, p)
 }
 
-func (s *ProductService) mockFetchPrice(url string) (float64, error) {
-	return 99.99, nil
+// requestAttrs ties log lines to the request that caused the work, across Kafka
+func requestAttrs(ctx context.Context) []any {
+	var attrs []any
+	if id := domain.RequestIDFromContext(ctx); id != "" {
+		attrs = append(attrs, slog.String("request_id", id))
+	}
+	if t, ok := domain.TraceFromContext(ctx); ok {
+		attrs = append(attrs, slog.String("trace_id", fmt.Sprintf("%x", t.TraceID)))
+	}
+	return attrs
 }
 
-// ProcessSingleProduct is the core logic for the Watcher
-func (s *ProductService) ProcessSingleProduct(ctx context.Context, id int64) error {
-	log.Printf("Watcher: processing product %d", id)
-	newPrice, _ := s.mockFetchPrice("url")
+// checkProduct fetches the page, stores the observation and runs the alert rules
+func (s *ProductService) checkProduct(ctx context.Context, p *domain.Product) error {
+	info, err := s.fetcher.Fetch(ctx, p.URL)
+	// The shop asked us to stay away or is down, the scheduler comes back at the next check
+	if errors.Is(err, domain.ErrFetchDisallowed) || errors.Is(err, domain.ErrHostThrottled) || errors.Is(err, domain.ErrCircuitOpen) {
+		s.logger.Warn("product check skipped", append(requestAttrs(ctx), slog.Int64("id", p.ID), slog.String("reason", err.Error()))...)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error fetching price for product %d: %w", p.ID, err)
+	}
 
-	_ = s.cache.SetPrice(ctx, id, newPrice)
-	return s.repo.UpdatePrice(ctx, id, newPrice)
+	s.fillTitle(ctx, p, info)
+
+	// Rules compare against the history before this check, so read it before writing
+	obs := s.buildObservation(ctx, p.ID, info)
+	s.recordObservation(ctx, p.ID, info)
+	if err := s.observed.SendPriceObserved(ctx, p.ID, info, obs.ObservedAt); err != nil {
+		s.logger.Warn("failed to announce price observation", append(requestAttrs(ctx), slog.Int64("id", p.ID), slog.String("error", err.Error()))...)
+	}
+	s.publish(ctx, &domain.PriceEvent{
+		Type:       domain.PriceEventObserved,
+		ProductID:  p.ID,
+		Title:      p.Title,
+		Price:      info.Price,
+		Currency:   info.Currency,
+		InStock:    info.InStock,
+		ObservedAt: obs.ObservedAt,
+	})
+	s.evaluateAlerts(ctx, p, obs, info.Currency)
+
+	// Out of stock pages often show no price at all, keep the last known one
+	if !info.InStock {
+		s.logger.Info("product is out of stock", slog.Int64("id", p.ID))
+		return nil
+	}
+
+	_ = s.cache.SetPrice(ctx, p.ID, info.Price)
+	return s.repo.UpdatePrice(ctx, p.ID, info.Price)
+}
+
+// publish pushes the event to live streams, they are best effort and never fail a check
+func (s *ProductService) publish(ctx context.Context, e *domain.PriceEvent) {
+	if err := s.events.Publish(ctx, e); err != nil {
+		s.logger.Warn("failed to publish price event",
+			slog.Int64("id", e.ProductID),
+			slog.String("type", string(e.Type)),
+			slog.String("error", err.Error()))
+	}
+}
+
+// fillTitle replaces the placeholder set by TrackProduct with the real page title
+func (s *ProductService) fillTitle(ctx context.Context, p *domain.Product, info *domain.PriceInfo) {
+	if info.Title == "" || (p.Title != "" && p.Title != domain.PendingTitle) {
+		return
+	}
+
+	if err := s.repo.UpdateTitle(ctx, p.ID, info.Title); err != nil {
+		s.logger.Error("failed to update product title",
+			slog.Int64("id", p.ID),
+			slog.String("error", err.Error()))
+		return
+	}
+	p.Title = info.Title
 }
 
 func (s *ProductService) GetByID(ctx context.Context, id int64) (*domain.Product, error) {
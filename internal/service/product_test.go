@@ -3,9 +3,14 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/derkres11/price-pulse/internal/domain"
 )
@@ -16,6 +21,9 @@ type repoMock struct {
 }
 
 func (m *repoMock) Create(ctx context.Context, p *domain.Product) error {
+	if p.ID == 0 {
+		p.ID = int64(len(m.products) + 1)
+	}
 	m.products[p.ID] = p
 	return nil
 }
@@ -23,38 +31,365 @@ func (m *repoMock) Create(ctx context.Context, p *domain.Product) error {
 func (m *repoMock) GetByID(ctx context.Context, id int64) (*domain.Product, error) {
 	p, ok := m.products[id]
 	if !ok {
-		return nil, errors.New("not found")
+		return nil, domain.ErrNotFound
 	}
 	return p, nil
 }
 
+func (m *repoMock) GetForUpdate(ctx context.Context, id int64) (*domain.Product, error) {
+	return m.GetByID(ctx, id)
+}
+
+func (m *repoMock) GetByURL(ctx context.Context, url string) (*domain.Product, error) {
+	for _, p := range m.products {
+		if p.URL == url {
+			return p, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
 func (m *repoMock) UpdatePrice(ctx context.Context, id int64, newPrice float64) error {
 	p, ok := m.products[id]
 	if !ok {
-		return errors.New("not found")
+		return domain.ErrNotFound
 	}
 	p.CurrentPrice = newPrice
 	return nil
 }
 
-func (m *repoMock) GetAll(ctx context.Context) ([]*domain.Product, error) {
+func (m *repoMock) UpdateTitle(ctx context.Context, id int64, title string) error {
+	p, ok := m.products[id]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	p.Title = title
+	return nil
+}
+
+func (m *repoMock) Update(ctx context.Context, p *domain.Product) error {
+	if _, ok := m.products[p.ID]; !ok {
+		return domain.ErrNotFound
+	}
+	m.products[p.ID] = p
+	return nil
+}
+
+func (m *repoMock) Delete(ctx context.Context, id int64) error {
+	if _, ok := m.products[id]; !ok {
+		return domain.ErrNotFound
+	}
+	delete(m.products, id)
+	return nil
+}
+
+// List supports the id and price orderings, enough to walk pages like the database does
+func (m *repoMock) List(ctx context.Context, q domain.ProductQuery) ([]*domain.Product, error) {
+	key := func(p *domain.Product) float64 {
+		if q.Sort == domain.SortByPrice {
+			return p.CurrentPrice
+		}
+		return float64(p.ID)
+	}
+	less := func(a, b *domain.Product) bool {
+		if key(a) != key(b) {
+			return key(a) < key(b) != q.Desc
+		}
+		return a.ID != b.ID && a.ID < b.ID != q.Desc
+	}
+
 	var list []*domain.Product
 	for _, p := range m.products {
+		if q.BelowTarget && (p.TargetPrice <= 0 || p.CurrentPrice > p.TargetPrice) {
+			continue
+		}
+		if q.After != nil {
+			v, _ := strconv.ParseFloat(q.After.Value, 64)
+			if !less(&domain.Product{ID: q.After.ID, CurrentPrice: v}, p) {
+				continue
+			}
+		}
 		list = append(list, p)
 	}
+	sort.Slice(list, func(i, j int) bool { return less(list[i], list[j]) })
+
+	if len(list) > q.Limit {
+		list = list[:q.Limit]
+	}
+	return list, nil
+}
+
+// historyMock matches domain.PriceHistoryRepository interface
+type historyMock struct {
+	points []*domain.PricePoint
+}
+
+func (m *historyMock) AddPoint(ctx context.Context, p *domain.PricePoint) error {
+	p.ObservedAt = time.Now()
+	m.points = append(m.points, p)
+	return nil
+}
+
+func (m *historyMock) GetRange(ctx context.Context, productID int64, from, to time.Time) ([]*domain.PricePoint, error) {
+	var list []*domain.PricePoint
+	for _, p := range m.points {
+		if p.ProductID == productID && !p.ObservedAt.Before(from) && p.ObservedAt.Before(to) {
+			list = append(list, p)
+		}
+	}
+	return list, nil
+}
+
+func (m *historyMock) GetBuckets(ctx context.Context, productID int64, from, to time.Time, step time.Duration) ([]*domain.PriceBucket, error) {
+	return nil, nil
+}
+
+func (m *historyMock) GetLowest(ctx context.Context, productID int64) (*domain.PricePoint, error) {
+	var lowest *domain.PricePoint
+	for _, p := range m.points {
+		if p.ProductID == productID && p.InStock && (lowest == nil || p.Price < lowest.Price) {
+			lowest = p
+		}
+	}
+	return lowest, nil
+}
+
+func (m *historyMock) GetLatest(ctx context.Context, productID int64) (*domain.PricePoint, error) {
+	for i := len(m.points) - 1; i >= 0; i-- {
+		if m.points[i].ProductID == productID {
+			return m.points[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *historyMock) GetUnchangedSince(ctx context.Context, productID int64, price float64) (time.Time, error) {
+	return time.Time{}, nil
+}
+
+// alertsMock matches domain.AlertRepository interface
+type alertsMock struct {
+	rules   []*domain.AlertRule
+	events  []*domain.AlertEvent
+	matched []*domain.AlertEvent
+}
+
+func (m *alertsMock) CreateRule(ctx context.Context, r *domain.AlertRule) error {
+	r.ID = int64(len(m.rules) + 1)
+	m.rules = append(m.rules, r)
+	return nil
+}
+
+func (m *alertsMock) ListRules(ctx context.Context, productID int64) ([]*domain.AlertRule, error) {
+	var list []*domain.AlertRule
+	for _, r := range m.rules {
+		if r.ProductID == productID {
+			list = append(list, r)
+		}
+	}
+	return list, nil
+}
+
+func (m *alertsMock) ListSubscriptionRules(ctx context.Context, subscriptionID int64) ([]*domain.AlertRule, error) {
+	var list []*domain.AlertRule
+	for _, r := range m.rules {
+		if r.SubscriptionID == subscriptionID {
+			list = append(list, r)
+		}
+	}
+	return list, nil
+}
+
+func (m *alertsMock) DeleteSubscriptionRule(ctx context.Context, subscriptionID, ruleID int64) error {
+	return nil
+}
+
+func (m *alertsMock) DeleteRule(ctx context.Context, productID, ruleID int64) error {
+	return nil
+}
+
+func (m *alertsMock) SaveEvent(ctx context.Context, e *domain.AlertEvent) error {
+	m.events = append(m.events, e)
+	return nil
+}
+
+func (m *alertsMock) ListEvents(ctx context.Context, productID, userID int64, limit int) ([]*domain.AlertEvent, error) {
+	return m.events, nil
+}
+
+func (m *alertsMock) RearmRules(ctx context.Context, productID int64, matched []*domain.AlertEvent) error {
+	m.matched = matched
+	return nil
+}
+
+// subsMock matches domain.SubscriptionRepository interface
+type subsMock struct {
+	subs []*domain.Subscription
+}
+
+func (m *subsMock) Upsert(ctx context.Context, s *domain.Subscription) error {
+	for _, existing := range m.subs {
+		if existing.UserID == s.UserID && existing.ProductID == s.ProductID {
+			existing.TargetPrice = s.TargetPrice
+			s.ID = existing.ID
+			return nil
+		}
+	}
+	s.ID = int64(len(m.subs) + 1)
+	m.subs = append(m.subs, s)
+	return nil
+}
+
+func (m *subsMock) GetByID(ctx context.Context, userID, id int64) (*domain.Subscription, error) {
+	for _, s := range m.subs {
+		if s.ID == id && s.UserID == userID {
+			return s, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (m *subsMock) ListByUser(ctx context.Context, userID int64) ([]*domain.Subscription, error) {
+	var list []*domain.Subscription
+	for _, s := range m.subs {
+		if s.UserID == userID {
+			list = append(list, s)
+		}
+	}
 	return list, nil
 }
 
+func (m *subsMock) ListByProduct(ctx context.Context, productID int64) ([]*domain.Subscription, error) {
+	var list []*domain.Subscription
+	for _, s := range m.subs {
+		if s.ProductID == productID {
+			list = append(list, s)
+		}
+	}
+	return list, nil
+}
+
+func (m *subsMock) UpdateTarget(ctx context.Context, userID, id int64, targetPrice float64) error {
+	s, err := m.GetByID(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	s.TargetPrice = targetPrice
+	return nil
+}
+
+func (m *subsMock) UpdatePolicy(ctx context.Context, userID, id int64, cooldownMinutes int, rearm bool) error {
+	s, err := m.GetByID(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	s.CooldownMinutes, s.Rearm = cooldownMinutes, rearm
+	return nil
+}
+
+func (m *subsMock) Delete(ctx context.Context, userID, id int64) error {
+	_, err := m.GetByID(ctx, userID, id)
+	return err
+}
+
+// notifierMock matches domain.NotificationProducer interface
+type notifierMock struct {
+	sent []*domain.Notification
+}
+
+func (m *notifierMock) SendNotification(ctx context.Context, n *domain.Notification) error {
+	m.sent = append(m.sent, n)
+	return nil
+}
+
+// eventsMock matches domain.PriceEventPublisher interface
+type eventsMock struct {
+	published []*domain.PriceEvent
+}
+
+func (m *eventsMock) Publish(ctx context.Context, e *domain.PriceEvent) error {
+	m.published = append(m.published, e)
+	return nil
+}
+
 // kafkaMock must match the Producer interface used in your service
 type kafkaMock struct {
-	sent bool
+	sent    bool
+	deleted []int64
+	err     error
 }
 
 func (m *kafkaMock) SendProductUpdate(ctx context.Context, id int64) error {
+	if m.err != nil {
+		return m.err
+	}
 	m.sent = true
 	return nil
 }
 
+func (m *kafkaMock) SendProductDeleted(ctx context.Context, id int64) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.deleted = append(m.deleted, id)
+	return nil
+}
+
+// observedMock matches domain.ObservationProducer interface
+type observedMock struct {
+	prices []float64
+}
+
+func (m *observedMock) SendPriceObserved(ctx context.Context, productID int64, info *domain.PriceInfo, at time.Time) error {
+	m.prices = append(m.prices, info.Price)
+	return nil
+}
+
+// txMock runs fn right away and counts the transactions that would have committed
+type txMock struct {
+	committed int
+}
+
+func (m *txMock) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := fn(ctx); err != nil {
+		return err
+	}
+	m.committed++
+	return nil
+}
+
+// cacheMock matches domain.ProductCache interface, it never hits
+type cacheMock struct {
+	prices  map[int64]float64
+	deleted []int64
+}
+
+func (m *cacheMock) SetPrice(ctx context.Context, id int64, price float64) error {
+	if m.prices != nil {
+		m.prices[id] = price
+	}
+	return nil
+}
+
+func (m *cacheMock) Get(ctx context.Context, id int64) (*domain.Product, error) {
+	return nil, nil
+}
+
+func (m *cacheMock) Delete(ctx context.Context, id int64) error {
+	m.deleted = append(m.deleted, id)
+	return nil
+}
+
+// fetcherMock returns a canned page observation for every URL
+type fetcherMock struct {
+	info *domain.PriceInfo
+	err  error
+}
+
+func (m *fetcherMock) Fetch(ctx context.Context, url string) (*domain.PriceInfo, error) {
+	return m.info, m.err
+}
+
 // --- TESTS ---
 
 func TestProductService_GetByID(t *testing.T) {
@@ -68,7 +403,7 @@ func TestProductService_GetByID(t *testing.T) {
 		CurrentPrice: 100.0,
 	}
 
-	svc := NewProductService(mockRepo, nil, nil, logger)
+	svc := NewProductService(mockRepo, &historyMock{}, &alertsMock{}, &subsMock{}, &txMock{}, nil, &notifierMock{}, &observedMock{}, &eventsMock{}, &cacheMock{}, nil, logger)
 
 	tests := []struct {
 		name      string
@@ -94,10 +429,10 @@ func TestProductService_Create(t *testing.T) {
 	mockRepo := &repoMock{products: make(map[int64]*domain.Product)}
 	mockKafka := &kafkaMock{}
 
-	svc := NewProductService(mockRepo, mockKafka, nil, logger)
+	svc := NewProductService(mockRepo, &historyMock{}, &alertsMock{}, &subsMock{}, &txMock{}, mockKafka, &notifierMock{}, &observedMock{}, &eventsMock{}, nil, nil, logger)
 
 	t.Run("create and notify", func(t *testing.T) {
-		p := &domain.Product{ID: 10, Title: "Gadget"}
+		p := &domain.Product{ID: 10, URL: "https://shop.example/gadget", Title: "Gadget"}
 		err := svc.Create(context.Background(), p)
 
 		if err != nil {
@@ -107,4 +442,674 @@ func TestProductService_Create(t *testing.T) {
 			t.Error("kafka was not notified")
 		}
 	})
+
+	// The outbox write is part of the transaction, failing it fails the create
+	t.Run("outbox failure rolls back", func(t *testing.T) {
+		mockKafka.err = errors.New("outbox unavailable")
+		defer func() { mockKafka.err = nil }()
+
+		if err := svc.Create(context.Background(), &domain.Product{ID: 11, URL: "https://shop.example/gizmo", Title: "Gizmo"}); err == nil {
+			t.Error("expected the create to fail with its outbox message")
+		}
+	})
+
+	t.Run("invalid url", func(t *testing.T) {
+		for _, u := range []string{"", "ftp://shop.example/x", "/relative", "https://shop.example/" + strings.Repeat("a", maxURLLength)} {
+			if err := svc.Create(context.Background(), &domain.Product{URL: u}); !errors.Is(err, domain.ErrInvalidArgument) {
+				t.Errorf("Create(%.30q) = %v, want invalid argument", u, err)
+			}
+		}
+	})
+}
+
+func TestProductService_ProcessSingleProduct(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	tests := []struct {
+		name        string
+		fetcher     *fetcherMock
+		wantPrice   float64
+		wantHistory int
+		wantErr     bool
+	}{
+		{"Price updated", &fetcherMock{info: &domain.PriceInfo{Price: 79.5, Title: "Lamp", InStock: true}}, 79.5, 1, false},
+		{"Out of stock keeps price", &fetcherMock{info: &domain.PriceInfo{InStock: false}}, 100, 1, false},
+		{"Fetch error", &fetcherMock{err: errors.New("timeout")}, 100, 0, true},
+		{"Throttled host skipped", &fetcherMock{err: fmt.Errorf("%w: shop.example", domain.ErrHostThrottled)}, 100, 0, false},
+		{"Disallowed page skipped", &fetcherMock{err: domain.ErrFetchDisallowed}, 100, 0, false},
+		{"Open circuit skipped", &fetcherMock{err: fmt.Errorf("%w: shop.example", domain.ErrCircuitOpen)}, 100, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := &repoMock{products: map[int64]*domain.Product{
+				1: {ID: 1, URL: "https://shop.example/item", Title: domain.PendingTitle, CurrentPrice: 100},
+			}}
+			mockHistory := &historyMock{}
+			svc := NewProductService(mockRepo, mockHistory, &alertsMock{}, &subsMock{}, &txMock{}, nil, &notifierMock{}, &observedMock{}, &eventsMock{}, &cacheMock{}, tt.fetcher, logger)
+
+			err := svc.ProcessSingleProduct(context.Background(), 1)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expected error: %v, got: %v", tt.wantErr, err)
+			}
+			if got := mockRepo.products[1].CurrentPrice; got != tt.wantPrice {
+				t.Errorf("expected price %.2f, got %.2f", tt.wantPrice, got)
+			}
+			if len(mockHistory.points) != tt.wantHistory {
+				t.Errorf("expected %d history points, got %d", tt.wantHistory, len(mockHistory.points))
+			}
+			if tt.fetcher.info != nil && tt.fetcher.info.Title != "" && mockRepo.products[1].Title != tt.fetcher.info.Title {
+				t.Errorf("expected placeholder title to be replaced, got %q", mockRepo.products[1].Title)
+			}
+		})
+	}
+}
+
+func TestProductService_GetPriceHistory(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	mockRepo := &repoMock{products: map[int64]*domain.Product{1: {ID: 1}}}
+	mockHistory := &historyMock{}
+	svc := NewProductService(mockRepo, mockHistory, &alertsMock{}, &subsMock{}, &txMock{}, nil, &notifierMock{}, &observedMock{}, &eventsMock{}, &cacheMock{}, nil, logger)
+
+	for _, price := range []float64{120, 95, 110} {
+		_ = mockHistory.AddPoint(context.Background(), &domain.PricePoint{ProductID: 1, Price: price, InStock: true})
+	}
+
+	now := time.Now()
+	tests := []struct {
+		name       string
+		from, to   time.Time
+		step       time.Duration
+		wantPoints int
+		wantErr    error
+	}{
+		{"Raw points", now.Add(-time.Hour), now.Add(time.Hour), 0, 3, nil},
+		{"Reversed range", now, now.Add(-time.Hour), 0, 0, ErrInvalidHistoryQuery},
+		{"Too many buckets", now.Add(-365 * 24 * time.Hour), now, time.Minute, 0, ErrInvalidHistoryQuery},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, err := svc.GetPriceHistory(context.Background(), 1, tt.from, tt.to, tt.step)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error: %v, got: %v", tt.wantErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if len(h.Points) != tt.wantPoints {
+				t.Errorf("expected %d points, got %d", tt.wantPoints, len(h.Points))
+			}
+			if h.AllTimeLow == nil || h.AllTimeLow.Price != 95 {
+				t.Errorf("expected all time low of 95, got %+v", h.AllTimeLow)
+			}
+		})
+	}
+}
+
+func TestProductService_ProcessSingleProduct_Alerts(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	mockRepo := &repoMock{products: map[int64]*domain.Product{
+		1: {ID: 1, URL: "https://shop.example/item", Title: "Lamp", CurrentPrice: 100, TargetPrice: 80},
+	}}
+	mockHistory := &historyMock{}
+	mockAlerts := &alertsMock{}
+	mockNotifier := &notifierMock{}
+	mockEvents := &eventsMock{}
+	mockObserved := &observedMock{}
+	mockFetcher := &fetcherMock{info: &domain.PriceInfo{Price: 100, InStock: true}}
+	svc := NewProductService(mockRepo, mockHistory, mockAlerts, &subsMock{}, &txMock{}, nil, mockNotifier, mockObserved, mockEvents, &cacheMock{}, mockFetcher, logger)
+
+	if err := svc.CreateAlertRule(context.Background(), &domain.AlertRule{
+		ProductID: 1, Type: domain.AlertAllTimeLow, Enabled: true,
+	}); err != nil {
+		t.Fatalf("create rule failed: %v", err)
+	}
+
+	for _, price := range []float64{100, 90, 75} {
+		mockFetcher.info.Price = price
+		if err := svc.ProcessSingleProduct(context.Background(), 1); err != nil {
+			t.Fatalf("process failed: %v", err)
+		}
+	}
+
+	// 90 and 75 are new lows, 75 is also under the legacy target price
+	var lows, targets int
+	for _, e := range mockAlerts.events {
+		switch {
+		case e.Type == domain.AlertAllTimeLow:
+			lows++
+		case e.Type == domain.AlertThreshold && e.RuleID == 0:
+			targets++
+		}
+	}
+	if lows != 2 || targets != 1 {
+		t.Errorf("expected 2 all-time low and 1 target alerts, got %d and %d", lows, targets)
+	}
+	if len(mockNotifier.sent) != len(mockAlerts.events) {
+		t.Errorf("expected every alert to be queued, got %d of %d", len(mockNotifier.sent), len(mockAlerts.events))
+	}
+	if len(mockObserved.prices) != 3 {
+		t.Errorf("expected every check to be announced, got %v", mockObserved.prices)
+	}
+	if len(mockAlerts.matched) != 2 {
+		t.Errorf("expected both matched rules to stay disarmed, got %v", mockAlerts.matched)
+	}
+
+	// Every check streams its price, every alert follows it
+	var observed, alerted int
+	for _, e := range mockEvents.published {
+		switch e.Type {
+		case domain.PriceEventObserved:
+			observed++
+		case domain.PriceEventAlert:
+			alerted++
+			if e.Alert == nil || e.Alert.ProductID != 1 {
+				t.Errorf("expected alert event for product 1, got %+v", e.Alert)
+			}
+		}
+	}
+	if observed != 3 || alerted != len(mockAlerts.events) {
+		t.Errorf("expected 3 price and %d alert events, got %d and %d", len(mockAlerts.events), observed, alerted)
+	}
+}
+
+func TestProductService_TrackProduct(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	mockRepo := &repoMock{products: make(map[int64]*domain.Product)}
+	mockSubs := &subsMock{}
+	mockKafka := &kafkaMock{}
+	svc := NewProductService(mockRepo, &historyMock{}, &alertsMock{}, mockSubs, &txMock{}, mockKafka, &notifierMock{}, &observedMock{}, &eventsMock{}, &cacheMock{}, nil, logger)
+
+	ctx := context.Background()
+	first, err := svc.TrackProduct(ctx, 1, "https://shop.example/lamp", 80)
+	if err != nil {
+		t.Fatalf("track failed: %v", err)
+	}
+	if !mockKafka.sent || first.Product.Title != domain.PendingTitle {
+		t.Error("expected a new pending product to be queued for its first check")
+	}
+
+	// A second user watches the same product with their own target
+	second, err := svc.TrackProduct(ctx, 2, "https://shop.example/lamp", 60)
+	if err != nil {
+		t.Fatalf("track failed: %v", err)
+	}
+	if len(mockRepo.products) != 1 || second.ProductID != first.ProductID {
+		t.Errorf("expected one shared product, got %d", len(mockRepo.products))
+	}
+	if second.ID == first.ID || second.TargetPrice != 60 {
+		t.Errorf("expected a separate subscription, got %+v", second)
+	}
+
+	// Tracking again only moves the target
+	again, err := svc.TrackProduct(ctx, 1, "https://shop.example/lamp", 70)
+	if err != nil || again.ID != first.ID || len(mockSubs.subs) != 2 {
+		t.Errorf("expected the existing subscription to be updated, got %+v (%v)", again, err)
+	}
+
+	if _, err := svc.TrackProduct(ctx, 1, "ftp://shop.example/lamp", 10); !errors.Is(err, ErrInvalidSubscription) {
+		t.Errorf("expected invalid url to be rejected, got %v", err)
+	}
+	if _, err := svc.UpdateSubscription(ctx, 2, first.ID, 50); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected foreign subscription to be hidden, got %v", err)
+	}
+}
+
+func TestProductService_ProcessSingleProduct_SubscriptionTargets(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	mockRepo := &repoMock{products: map[int64]*domain.Product{
+		1: {ID: 1, URL: "https://shop.example/item", Title: "Lamp", CurrentPrice: 100},
+	}}
+	mockSubs := &subsMock{subs: []*domain.Subscription{
+		{ID: 1, UserID: 10, ProductID: 1, TargetPrice: 90},
+		{ID: 2, UserID: 20, ProductID: 1, TargetPrice: 50},
+	}}
+	mockAlerts := &alertsMock{}
+	mockNotifier := &notifierMock{}
+	mockFetcher := &fetcherMock{info: &domain.PriceInfo{Price: 85, InStock: true}}
+	svc := NewProductService(mockRepo, &historyMock{}, mockAlerts, mockSubs, &txMock{}, nil, mockNotifier, &observedMock{}, &eventsMock{}, &cacheMock{}, mockFetcher, logger)
+
+	if err := svc.ProcessSingleProduct(context.Background(), 1); err != nil {
+		t.Fatalf("process failed: %v", err)
+	}
+
+	// Only the first subscriber's target is reached, and only they are notified
+	if len(mockNotifier.sent) != 1 {
+		t.Fatalf("expected 1 notification, got %d", len(mockNotifier.sent))
+	}
+	if e := mockNotifier.sent[0].Event; e.UserID != 10 || e.SubscriptionID != 1 {
+		t.Errorf("expected the alert to belong to user 10, got %+v", e)
+	}
+}
+
+func TestProductService_ListProducts(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	mockRepo := &repoMock{products: make(map[int64]*domain.Product)}
+	for i := int64(1); i <= 7; i++ {
+		mockRepo.products[i] = &domain.Product{ID: i, CurrentPrice: float64(100 - i%3*10), TargetPrice: 85}
+	}
+	svc := NewProductService(mockRepo, &historyMock{}, &alertsMock{}, &subsMock{}, &txMock{}, nil, &notifierMock{}, &observedMock{}, &eventsMock{}, &cacheMock{}, nil, logger)
+
+	tests := []struct {
+		name    string
+		query   domain.ProductQuery
+		wantIDs []int64
+	}{
+		{"By id", domain.ProductQuery{Limit: 3}, []int64{1, 2, 3, 4, 5, 6, 7}},
+		{"Cheapest first", domain.ProductQuery{Sort: domain.SortByPrice, Limit: 2}, []int64{2, 5, 1, 4, 7, 3, 6}},
+		{"Most expensive first", domain.ProductQuery{Sort: domain.SortByPrice, Desc: true, Limit: 4}, []int64{6, 3, 7, 4, 1, 5, 2}},
+		{"Below target", domain.ProductQuery{ProductFilter: domain.ProductFilter{BelowTarget: true}, Limit: 1}, []int64{2, 5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []int64
+			cursor := ""
+			for pages := 0; pages < 10; pages++ {
+				page, err := svc.ListProducts(context.Background(), tt.query, cursor)
+				if err != nil {
+					t.Fatalf("list failed: %v", err)
+				}
+				if len(page.Items) > tt.query.Limit {
+					t.Fatalf("page of %d exceeds limit %d", len(page.Items), tt.query.Limit)
+				}
+				for _, p := range page.Items {
+					got = append(got, p.ID)
+				}
+				if cursor = page.NextCursor; cursor == "" {
+					break
+				}
+			}
+
+			if fmt.Sprint(got) != fmt.Sprint(tt.wantIDs) {
+				t.Errorf("expected %v, got %v", tt.wantIDs, got)
+			}
+		})
+	}
+
+	page, _ := svc.ListProducts(context.Background(), domain.ProductQuery{Limit: 2}, "")
+	if _, err := svc.ListProducts(context.Background(), domain.ProductQuery{Sort: domain.SortByPrice, Limit: 2}, page.NextCursor); !errors.Is(err, ErrInvalidProductQuery) {
+		t.Errorf("expected a cursor from another ordering to be rejected, got %v", err)
+	}
+	if _, err := svc.ListProducts(context.Background(), domain.ProductQuery{}, "%%%"); !errors.Is(err, ErrInvalidProductQuery) {
+		t.Errorf("expected a malformed cursor to be rejected, got %v", err)
+	}
+}
+
+func TestProductService_UpdateAndDeleteProduct(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	mockRepo := &repoMock{products: map[int64]*domain.Product{
+		1: {ID: 1, Title: "Lamp", TargetPrice: 80},
+	}}
+	mockCache := &cacheMock{}
+	mockKafka := &kafkaMock{}
+	tx := &txMock{}
+	svc := NewProductService(mockRepo, &historyMock{}, &alertsMock{}, &subsMock{}, tx, mockKafka, &notifierMock{}, &observedMock{}, &eventsMock{}, mockCache, nil, logger)
+
+	target := 70.0
+	p, err := svc.UpdateProduct(context.Background(), 1, domain.ProductPatch{TargetPrice: &target})
+	if err != nil || p.TargetPrice != 70 || p.Title != "Lamp" {
+		t.Fatalf("unexpected update result %+v (%v)", p, err)
+	}
+	if tx.committed != 1 {
+		t.Errorf("expected the patch to be applied in one transaction, got %d", tx.committed)
+	}
+
+	empty := " "
+	if _, err := svc.UpdateProduct(context.Background(), 1, domain.ProductPatch{Title: &empty}); !errors.Is(err, ErrInvalidProductQuery) {
+		t.Errorf("expected empty title to be rejected, got %v", err)
+	}
+
+	hourly := domain.Interval(time.Hour)
+	if p, err := svc.UpdateProduct(context.Background(), 1, domain.ProductPatch{CheckInterval: &hourly}); err != nil || p.CheckInterval != hourly {
+		t.Errorf("unexpected check interval update %+v (%v)", p, err)
+	}
+	tooOften := domain.Interval(time.Second)
+	if _, err := svc.UpdateProduct(context.Background(), 1, domain.ProductPatch{CheckInterval: &tooOften}); !errors.Is(err, domain.ErrInvalidArgument) {
+		t.Errorf("expected a 1s check interval to be rejected, got %v", err)
+	}
+	reset := domain.Interval(0)
+	if p, err := svc.UpdateProduct(context.Background(), 1, domain.ProductPatch{CheckInterval: &reset}); err != nil || p.CheckInterval != 0 {
+		t.Errorf("expected 0s to reset the check interval, got %+v (%v)", p, err)
+	}
+
+	if err := svc.DeleteProduct(context.Background(), 1); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	if err := svc.DeleteProduct(context.Background(), 1); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected second delete to miss, got %v", err)
+	}
+	if len(mockKafka.deleted) != 1 || mockKafka.deleted[0] != 1 {
+		t.Errorf("expected the deletion to be announced once, got %v", mockKafka.deleted)
+	}
+	if len(mockCache.deleted) != 4 {
+		t.Errorf("expected cache invalidation on update and delete, got %v", mockCache.deleted)
+	}
+}
+
+func TestNormalizeHost(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"shop.example.com", "shop.example.com", false},
+		{" WWW.Shop.Example.com ", "shop.example.com", false},
+		{"https://www.shop.example.com/item/1", "shop.example.com", false},
+		{"shop.example.com/item", "", true},
+		{"", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := normalizeHost(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("normalizeHost(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestNotificationService_ValidateChannel(t *testing.T) {
+	s := &NotificationService{notifiers: map[domain.ChannelType]domain.Notifier{
+		domain.ChannelWebhook: nil,
+		domain.ChannelEmail:   nil,
+	}}
+
+	tests := []struct {
+		name    string
+		typ     domain.ChannelType
+		target  string
+		wantErr bool
+	}{
+		{"Public webhook", domain.ChannelWebhook, "https://hooks.example.com/price", false},
+		{"Loopback", domain.ChannelWebhook, "http://127.0.0.1:8080/", true},
+		{"Localhost", domain.ChannelWebhook, "http://localhost/hook", true},
+		{"Metadata", domain.ChannelWebhook, "http://169.254.169.254/latest/meta-data", true},
+		{"Private", domain.ChannelWebhook, "http://[fd00::1]/", true},
+		{"Bare email", domain.ChannelEmail, "ops@example.com", false},
+		{"Display name", domain.ChannelEmail, "Ops <ops@example.com>", true},
+		{"Angle brackets", domain.ChannelEmail, "<ops@example.com>", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.validateChannel(&domain.NotificationChannel{Type: tt.typ, Target: tt.target})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateChannel(%q) error = %v, wantErr %v", tt.target, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// channelRepoMock matches domain.ChannelRepository interface
+type channelRepoMock struct {
+	channels []*domain.NotificationChannel
+}
+
+func (m *channelRepoMock) Create(ctx context.Context, ch *domain.NotificationChannel) error {
+	return nil
+}
+
+func (m *channelRepoMock) GetEnabled(ctx context.Context) ([]*domain.NotificationChannel, error) {
+	return m.channels, nil
+}
+
+func (m *channelRepoMock) ListByUser(ctx context.Context, userID int64) ([]*domain.NotificationChannel, error) {
+	return ownedBy(m.channels, userID), nil
+}
+
+func (m *channelRepoMock) Delete(ctx context.Context, userID, id int64) error {
+	return nil
+}
+
+// alertStateMock matches domain.AlertStateRepository interface with a single state row
+type alertStateMock struct {
+	st       *domain.AlertState
+	locked   bool
+	recorded *domain.AlertState
+	released bool
+}
+
+func (m *alertStateMock) Policy(ctx context.Context, subscriptionID int64) (*domain.AlertPolicy, error) {
+	return &domain.AlertPolicy{CooldownMinutes: 60, Rearm: true}, nil
+}
+
+func (m *alertStateMock) WithState(ctx context.Context, subscriptionID, productID, ruleID int64, fn func(ctx context.Context, st *domain.AlertState) (*domain.AlertState, error)) error {
+	m.locked = true
+	defer func() { m.locked = false }()
+
+	next, err := fn(ctx, m.st)
+	if err != nil || next == nil {
+		return err
+	}
+	next.Version++
+	m.st = next
+	return nil
+}
+
+func (m *alertStateMock) RecordSent(ctx context.Context, claim, sent *domain.AlertState) error {
+	m.recorded = sent
+	return nil
+}
+
+func (m *alertStateMock) ReleaseClaim(ctx context.Context, claim *domain.AlertState) error {
+	m.released = true
+	return nil
+}
+
+func (m *alertStateMock) QueueDigest(ctx context.Context, userID int64, n *domain.Notification) error {
+	return nil
+}
+
+func (m *alertStateMock) UsersWithDigest(ctx context.Context) ([]*domain.User, error) {
+	return nil, nil
+}
+
+func (m *alertStateMock) FlushDigest(ctx context.Context, userID int64, until time.Time, fn func(items []*domain.Notification) error) error {
+	return nil
+}
+
+// sendMock matches domain.Notifier interface
+type sendMock struct {
+	err         error
+	whileLocked bool
+	state       *alertStateMock
+	sent        int
+}
+
+func (m *sendMock) Send(ctx context.Context, ch *domain.NotificationChannel, text string, n *domain.Notification) error {
+	m.whileLocked = m.whileLocked || m.state.locked
+	m.sent++
+	return m.err
+}
+
+func TestNotificationService_Dispatch(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	n := &domain.Notification{
+		Event: &domain.AlertEvent{ProductID: 1, SubscriptionID: 3, UserID: 2, Type: domain.AlertThreshold, Price: 79},
+		Title: "Phone",
+	}
+	channels := &channelRepoMock{channels: []*domain.NotificationChannel{
+		{ID: 1, UserID: 2, Type: domain.ChannelSlack, Target: "#deals", Enabled: true},
+		{ID: 2, UserID: 5, Type: domain.ChannelSlack, Target: "#other", Enabled: true},
+	}}
+
+	tests := []struct {
+		name         string
+		sendErr      error
+		wantErr      bool
+		wantRecorded bool
+		wantReleased bool
+	}{
+		{"Delivered", nil, false, true, false},
+		{"Delivery failed", errors.New("slack is down"), true, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := &alertStateMock{}
+			notifier := &sendMock{err: tt.sendErr, state: state}
+			svc := NewNotificationService(channels, state, map[domain.ChannelType]domain.Notifier{domain.ChannelSlack: notifier},
+				domain.AlertPolicy{}, time.Second, logger)
+
+			err := svc.Dispatch(context.Background(), n)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if notifier.sent != 1 {
+				t.Errorf("expected the owner's channel only, got %d sends", notifier.sent)
+			}
+			if notifier.whileLocked {
+				t.Error("delivered while the state row was locked")
+			}
+			if state.st == nil || state.st.ClaimedUntil.IsZero() {
+				t.Error("expected the alert to be claimed before delivery")
+			}
+			if (state.recorded != nil) != tt.wantRecorded || state.released != tt.wantReleased {
+				t.Errorf("expected recorded %v and released %v, got %v and %v", tt.wantRecorded, tt.wantReleased, state.recorded != nil, state.released)
+			}
+		})
+	}
+
+	t.Run("Claimed elsewhere", func(t *testing.T) {
+		state := &alertStateMock{st: &domain.AlertState{Armed: true, ClaimedUntil: time.Now().Add(time.Minute)}}
+		notifier := &sendMock{state: state}
+		svc := NewNotificationService(channels, state, map[domain.ChannelType]domain.Notifier{domain.ChannelSlack: notifier},
+			domain.AlertPolicy{}, time.Second, logger)
+
+		if err := svc.Dispatch(context.Background(), n); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if notifier.sent != 0 {
+			t.Errorf("expected no delivery while another replica holds the claim, got %d", notifier.sent)
+		}
+	})
+}
+
+// userRepoMock matches domain.UserRepository interface
+type userRepoMock struct {
+	users []*domain.User
+}
+
+func (m *userRepoMock) Create(ctx context.Context, u *domain.User) error {
+	u.ID = int64(len(m.users) + 1)
+	m.users = append(m.users, u)
+	return nil
+}
+
+func (m *userRepoMock) GetByID(ctx context.Context, id int64) (*domain.User, error) {
+	for _, u := range m.users {
+		if u.ID == id {
+			return u, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (m *userRepoMock) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	for _, u := range m.users {
+		if u.Email == email {
+			return u, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (m *userRepoMock) UpdateQuietHours(ctx context.Context, id int64, q domain.QuietHours) error {
+	return nil
+}
+
+// apiKeyRepoMock matches domain.APIKeyRepository interface
+type apiKeyRepoMock struct {
+	keys []*domain.APIKey
+	err  error
+}
+
+func (m *apiKeyRepoMock) Create(ctx context.Context, k *domain.APIKey) error {
+	if m.err != nil {
+		return m.err
+	}
+	k.ID = int64(len(m.keys) + 1)
+	m.keys = append(m.keys, k)
+	return nil
+}
+
+func (m *apiKeyRepoMock) GetByPrefix(ctx context.Context, prefix string) (*domain.APIKey, error) {
+	for _, k := range m.keys {
+		if k.Prefix == prefix {
+			return k, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (m *apiKeyRepoMock) ListByUser(ctx context.Context, userID int64) ([]*domain.APIKey, error) {
+	return nil, nil
+}
+
+func (m *apiKeyRepoMock) Revoke(ctx context.Context, userID, id int64) error {
+	return nil
+}
+
+func (m *apiKeyRepoMock) Touch(ctx context.Context, id int64) error {
+	return nil
+}
+
+func TestUserService_Register(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	t.Run("User and key commit together", func(t *testing.T) {
+		users, keys, tx := &userRepoMock{}, &apiKeyRepoMock{}, &txMock{}
+		svc := NewUserService(users, NewAPIKeyService(keys, users, tx, logger), tx, logger)
+
+		key, err := svc.Register(context.Background(), &domain.User{Email: "Ann@Example.com"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if key == "" || len(keys.keys) != 1 || keys.keys[0].Admin {
+			t.Errorf("expected one plain key, got %q and %d keys", key, len(keys.keys))
+		}
+		if tx.committed != 1 {
+			t.Errorf("expected one transaction, got %d", tx.committed)
+		}
+	})
+
+	t.Run("Failed key rolls the user back", func(t *testing.T) {
+		users, keys, tx := &userRepoMock{}, &apiKeyRepoMock{err: errors.New("db down")}, &txMock{}
+		svc := NewUserService(users, NewAPIKeyService(keys, users, tx, logger), tx, logger)
+
+		if _, err := svc.Register(context.Background(), &domain.User{Email: "ann@example.com"}); err == nil {
+			t.Fatal("expected an error")
+		}
+		if tx.committed != 0 {
+			t.Error("expected the transaction to roll back")
+		}
+	})
+}
+
+func TestAPIKeyService_Bootstrap(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	const key = "pp_0123456789ab_c2VjcmV0"
+
+	users, keys := &userRepoMock{}, &apiKeyRepoMock{}
+	svc := NewAPIKeyService(keys, users, &txMock{}, logger)
+
+	for range 2 {
+		if err := svc.Bootstrap(context.Background(), "Ops@Example.com", key); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if len(users.users) != 1 || users.users[0].Email != "ops@example.com" {
+		t.Errorf("expected one admin user, got %+v", users.users)
+	}
+	if len(keys.keys) != 1 || !keys.keys[0].Admin || keys.keys[0].Prefix != "0123456789ab" {
+		t.Errorf("expected one admin key, got %+v", keys.keys)
+	}
+
+	if err := svc.Bootstrap(context.Background(), "ops@example.com", "pp_0123456789ab_b3RoZXI"); err == nil {
+		t.Error("expected an error for a different key under the same prefix")
+	}
+	if err := svc.Bootstrap(context.Background(), "ops@example.com", "not-a-key"); err == nil {
+		t.Error("expected an error for a malformed key")
+	}
 }
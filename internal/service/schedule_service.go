@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/derkres11/price-pulse/internal/domain"
+)
+
+// ErrInvalidInterval is returned for check intervals outside the allowed range
+var ErrInvalidInterval = domain.NewError(domain.ErrInvalidArgument, "invalid check interval")
+
+// Bounds of a product's or host's check interval, shops do not like being hammered
+const (
+	MinCheckInterval = 5 * time.Minute
+	MaxCheckInterval = 30 * 24 * time.Hour
+)
+
+// ScheduleService manages when products are checked. The checks themselves are queued
+// by the scheduler, this is for checks on demand and the per host intervals.
+type ScheduleService struct {
+	products domain.ProductRepository
+	schedule domain.ScheduleRepository
+	planner  domain.CheckPlanner
+	tx       domain.Transactor
+	producer domain.TaskProducer
+	logger   *slog.Logger
+}
+
+func NewScheduleService(
+	products domain.ProductRepository,
+	schedule domain.ScheduleRepository,
+	planner domain.CheckPlanner,
+	tx domain.Transactor,
+	producer domain.TaskProducer,
+	logger *slog.Logger,
+) *ScheduleService {
+	return &ScheduleService{
+		products: products,
+		schedule: schedule,
+		planner:  planner,
+		tx:       tx,
+		producer: producer,
+		logger:   logger,
+	}
+}
+
+// CheckNow queues a check of the product right away. Its regular schedule is kept.
+func (s *ScheduleService) CheckNow(ctx context.Context, productID int64) error {
+	err := s.tx.InTx(ctx, func(ctx context.Context) error {
+		if _, err := s.products.GetByID(ctx, productID); err != nil {
+			return err
+		}
+		return s.producer.SendProductUpdate(ctx, productID)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info("product check requested", slog.Int64("id", productID))
+	return nil
+}
+
+// ExplainInterval tells how often the product is checked and why
+func (s *ScheduleService) ExplainInterval(ctx context.Context, productID int64) (*domain.CheckPlan, error) {
+	p, err := s.products.GetByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := s.planner.Explain(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("error planning checks of product %d: %w", productID, err)
+	}
+	plan.NextCheckAt = p.NextCheckAt
+	return plan, nil
+}
+
+func (s *ScheduleService) ListHostIntervals(ctx context.Context) ([]*domain.HostInterval, error) {
+	intervals, err := s.schedule.ListHostIntervals(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error listing host intervals: %w", err)
+	}
+	if intervals == nil {
+		intervals = []*domain.HostInterval{}
+	}
+	return intervals, nil
+}
+
+// SetHostInterval sets how often the products of a shop are checked, unless a product
+// sets its own interval
+func (s *ScheduleService) SetHostInterval(ctx context.Context, host string, interval time.Duration) (*domain.HostInterval, error) {
+	h, err := normalizeHost(host)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateInterval(interval); err != nil {
+		return nil, err
+	}
+
+	hi := &domain.HostInterval{Host: h, Interval: domain.Interval(interval)}
+	if err := s.schedule.SetHostInterval(ctx, hi); err != nil {
+		return nil, fmt.Errorf("error setting interval of %s: %w", h, err)
+	}
+
+	s.logger.Info("host check interval set", slog.String("host", h), slog.Duration("interval", interval))
+	return hi, nil
+}
+
+func (s *ScheduleService) DeleteHostInterval(ctx context.Context, host string) error {
+	h, err := normalizeHost(host)
+	if err != nil {
+		return err
+	}
+	return s.schedule.DeleteHostInterval(ctx, h)
+}
+
+func validateInterval(d time.Duration) error {
+	if d < MinCheckInterval || d > MaxCheckInterval {
+		return fmt.Errorf("%w: must be between %s and %s", ErrInvalidInterval, MinCheckInterval, MaxCheckInterval)
+	}
+	return nil
+}
+
+// normalizeHost lowercases the host and drops "www.", a URL is accepted too
+func normalizeHost(host string) (string, error) {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if strings.Contains(host, "://") {
+		u, err := url.Parse(host)
+		if err != nil {
+			return "", fmt.Errorf("%w: bad host %q", domain.ErrInvalidArgument, host)
+		}
+		host = u.Hostname()
+	}
+	host = strings.TrimPrefix(host, "www.")
+	if host == "" || strings.ContainsAny(host, "/:?# ") {
+		return "", fmt.Errorf("%w: bad host %q", domain.ErrInvalidArgument, host)
+	}
+	return host, nil
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/mail"
+	"strings"
+
+	"github.com/derkres11/price-pulse/internal/alerts"
+	"github.com/derkres11/price-pulse/internal/domain"
+)
+
+// ErrInvalidUser is returned when registering a user without a usable email
+var ErrInvalidUser = domain.NewError(domain.ErrInvalidArgument, "invalid user")
+
+type UserService struct {
+	users   domain.UserRepository
+	apiKeys *APIKeyService
+	tx      domain.Transactor
+	logger  *slog.Logger
+}
+
+func NewUserService(users domain.UserRepository, apiKeys *APIKeyService, tx domain.Transactor, logger *slog.Logger) *UserService {
+	return &UserService{
+		users:   users,
+		apiKeys: apiKeys,
+		tx:      tx,
+		logger:  logger,
+	}
+}
+
+// Register creates the user together with a first API key and returns the key in plain
+// text. Neither is stored without the other.
+func (s *UserService) Register(ctx context.Context, u *domain.User) (string, error) {
+	addr, err := mail.ParseAddress(u.Email)
+	if err != nil {
+		return "", fmt.Errorf("%w: email must be an email address", ErrInvalidUser)
+	}
+	u.Email = strings.ToLower(addr.Address)
+	if u.Name == "" {
+		u.Name = addr.Name
+	}
+
+	var key string
+	err = s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.users.Create(ctx, u); err != nil {
+			return fmt.Errorf("error creating user: %w", err)
+		}
+		_, plain, err := s.apiKeys.CreateKey(ctx, u.ID, "default", false)
+		key = plain
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	s.logger.Info("user registered", slog.Int64("id", u.ID))
+	return key, nil
+}
+
+func (s *UserService) GetByID(ctx context.Context, id int64) (*domain.User, error) {
+	return s.users.GetByID(ctx, id)
+}
+
+// SetQuietHours applies to every subscription of the user, alerts are held back for a digest
+func (s *UserService) SetQuietHours(ctx context.Context, id int64, q domain.QuietHours) (*domain.User, error) {
+	if q.Timezone == "" {
+		q.Timezone = defaultTimezone
+	}
+	if err := alerts.ValidateQuietHours(&q); err != nil {
+		return nil, err
+	}
+
+	if err := s.users.UpdateQuietHours(ctx, id, q); err != nil {
+		return nil, err
+	}
+	return s.users.GetByID(ctx, id)
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+
+	"github.com/derkres11/price-pulse/internal/alerts"
+	"github.com/derkres11/price-pulse/internal/domain"
+)
+
+// ErrInvalidSubscription is returned for watchlist entries that could never be checked
+var ErrInvalidSubscription = domain.NewError(domain.ErrInvalidArgument, "invalid subscription")
+
+// TrackProduct adds a URL to the user's watchlist. The product is shared: it is only
+// created, and fetched, the first time anybody tracks the URL.
+func (s *ProductService) TrackProduct(ctx context.Context, userID int64, rawURL string, targetPrice float64) (*domain.Subscription, error) {
+	if err := validateTrack(rawURL, targetPrice); err != nil {
+		return nil, err
+	}
+
+	// A new product, its first check and the subscription commit together
+	var sub *domain.Subscription
+	err := s.tx.InTx(ctx, func(ctx context.Context) error {
+		p, err := s.findOrCreate(ctx, rawURL)
+		if err != nil {
+			return err
+		}
+
+		sub = &domain.Subscription{UserID: userID, ProductID: p.ID, TargetPrice: targetPrice, Product: p}
+		if err := s.subs.Upsert(ctx, sub); err != nil {
+			return fmt.Errorf("error creating subscription: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("product tracked",
+		slog.Int64("user_id", userID),
+		slog.Int64("product_id", sub.ProductID),
+		slog.Int64("subscription_id", sub.ID))
+	return sub, nil
+}
+
+func (s *ProductService) findOrCreate(ctx context.Context, rawURL string) (*domain.Product, error) {
+	p, err := s.repo.GetByURL(ctx, rawURL)
+	if err == nil {
+		return p, nil
+	}
+	if !errors.Is(err, domain.ErrNotFound) {
+		return nil, fmt.Errorf("error looking up product: %w", err)
+	}
+
+	p = &domain.Product{URL: rawURL, Title: domain.PendingTitle}
+	if err := s.Create(ctx, p); err != nil {
+		// Somebody else tracked the same URL in the meantime. Create ran in a savepoint,
+		// so the transaction of TrackProduct is still usable for the lookup.
+		if existing, lookupErr := s.repo.GetByURL(ctx, rawURL); lookupErr == nil {
+			return existing, nil
+		}
+		return nil, err
+	}
+	return p, nil
+}
+
+// maxURLLength bounds product URLs, anything longer is no shop page we want to fetch
+const maxURLLength = 2048
+
+// validateURL is what every transport accepts as a product URL
+func validateURL(rawURL string) error {
+	if len(rawURL) > maxURLLength {
+		return fmt.Errorf("url must not be longer than %d characters", maxURLLength)
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("url must be an http(s) url")
+	}
+	return nil
+}
+
+func validateTrack(rawURL string, targetPrice float64) error {
+	if err := validateURL(rawURL); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSubscription, err)
+	}
+	if targetPrice < 0 {
+		return fmt.Errorf("%w: target price must not be negative", ErrInvalidSubscription)
+	}
+	return nil
+}
+
+func (s *ProductService) ListWatchlist(ctx context.Context, userID int64) ([]*domain.Subscription, error) {
+	return s.subs.ListByUser(ctx, userID)
+}
+
+func (s *ProductService) GetSubscription(ctx context.Context, userID, id int64) (*domain.Subscription, error) {
+	return s.subs.GetByID(ctx, userID, id)
+}
+
+func (s *ProductService) UpdateSubscription(ctx context.Context, userID, id int64, targetPrice float64) (*domain.Subscription, error) {
+	if targetPrice < 0 {
+		return nil, fmt.Errorf("%w: target price must not be negative", ErrInvalidSubscription)
+	}
+	if err := s.subs.UpdateTarget(ctx, userID, id, targetPrice); err != nil {
+		return nil, err
+	}
+	return s.subs.GetByID(ctx, userID, id)
+}
+
+// SetAlertPolicy changes how often the subscription may repeat an alert
+func (s *ProductService) SetAlertPolicy(ctx context.Context, userID, id int64, cooldownMinutes int, rearm bool) (*domain.Subscription, error) {
+	if err := alerts.ValidatePolicy(&domain.AlertPolicy{CooldownMinutes: cooldownMinutes, Rearm: rearm}); err != nil {
+		return nil, err
+	}
+	if err := s.subs.UpdatePolicy(ctx, userID, id, cooldownMinutes, rearm); err != nil {
+		return nil, err
+	}
+	return s.subs.GetByID(ctx, userID, id)
+}
+
+// UntrackProduct removes the subscription and its rules, the product stays for other watchers
+func (s *ProductService) UntrackProduct(ctx context.Context, userID, id int64) error {
+	return s.subs.Delete(ctx, userID, id)
+}
+
+func (s *ProductService) CreateSubscriptionRule(ctx context.Context, userID, subscriptionID int64, rule *domain.AlertRule) error {
+	sub, err := s.subs.GetByID(ctx, userID, subscriptionID)
+	if err != nil {
+		return err
+	}
+
+	rule.ProductID = sub.ProductID
+	rule.SubscriptionID = sub.ID
+	if rule.Type == domain.AlertPercentDrop && rule.Baseline == 0 {
+		rule.Baseline = sub.Product.CurrentPrice
+	}
+
+	if err := alerts.Validate(rule); err != nil {
+		return err
+	}
+
+	if err := s.alerts.CreateRule(ctx, rule); err != nil {
+		return fmt.Errorf("error creating alert rule: %w", err)
+	}
+	return nil
+}
+
+func (s *ProductService) ListSubscriptionRules(ctx context.Context, userID, subscriptionID int64) ([]*domain.AlertRule, error) {
+	if _, err := s.subs.GetByID(ctx, userID, subscriptionID); err != nil {
+		return nil, err
+	}
+	return s.alerts.ListSubscriptionRules(ctx, subscriptionID)
+}
+
+func (s *ProductService) DeleteSubscriptionRule(ctx context.Context, userID, subscriptionID, ruleID int64) error {
+	if _, err := s.subs.GetByID(ctx, userID, subscriptionID); err != nil {
+		return err
+	}
+	return s.alerts.DeleteSubscriptionRule(ctx, subscriptionID, ruleID)
+}
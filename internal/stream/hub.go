@@ -0,0 +1,154 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+
+	"github.com/derkres11/price-pulse/internal/domain"
+)
+
+// ErrSlowSubscriber ends a subscription whose buffer filled up. Dropping the subscriber
+// keeps one stuck client from holding back the watcher or everybody else.
+var ErrSlowSubscriber = errors.New("subscriber too slow")
+
+const (
+	// DefaultBuffer is how many events a subscriber may fall behind before it is dropped
+	DefaultBuffer = 64
+	// ReplaySize is how many recent events Resume can hand to a reconnecting client
+	ReplaySize = 256
+)
+
+// Subscription receives the events accepted by its filter until it is closed
+type Subscription struct {
+	events chan *domain.PriceEvent
+	filter func(e *domain.PriceEvent) bool
+	err    error // written before events is closed
+}
+
+// Events is closed when the subscription ends, Err then says why
+func (s *Subscription) Events() <-chan *domain.PriceEvent {
+	return s.events
+}
+
+// Err is nil after Unsubscribe and ErrSlowSubscriber when the hub dropped the subscriber.
+// It is only meaningful once Events is closed.
+func (s *Subscription) Err() error {
+	return s.err
+}
+
+// Hub is the in-process fan-out of price events to streaming clients.
+// It numbers the events and keeps the last ReplaySize of them for resumption.
+type Hub struct {
+	mu     sync.Mutex
+	subs   map[*Subscription]struct{}
+	buffer int
+	seq    int64
+	replay []*domain.PriceEvent // ring, replay[seq%ReplaySize] is the newest
+	logger *slog.Logger
+}
+
+func NewHub(buffer int, logger *slog.Logger) *Hub {
+	if buffer <= 0 {
+		buffer = DefaultBuffer
+	}
+	return &Hub{
+		subs:   make(map[*Subscription]struct{}),
+		buffer: buffer,
+		replay: make([]*domain.PriceEvent, ReplaySize),
+		logger: logger,
+	}
+}
+
+// Subscribe registers a subscriber, a nil filter accepts every event
+func (h *Hub) Subscribe(filter func(e *domain.PriceEvent) bool) *Subscription {
+	s := &Subscription{
+		events: make(chan *domain.PriceEvent, h.buffer),
+		filter: filter,
+	}
+
+	h.mu.Lock()
+	h.subs[s] = struct{}{}
+	h.mu.Unlock()
+	return s
+}
+
+// Resume subscribes and returns the buffered events after lastID that pass the filter,
+// atomically so nothing falls between the replay and the live events. Events older than
+// the buffer are gone; IDs are per replica, so resuming needs the same replica.
+func (h *Hub) Resume(lastID int64, filter func(e *domain.PriceEvent) bool) (*Subscription, []*domain.PriceEvent) {
+	s := &Subscription{
+		events: make(chan *domain.PriceEvent, h.buffer),
+		filter: filter,
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var missed []*domain.PriceEvent
+	for id := max(lastID+1, h.seq-ReplaySize+1, 1); id <= h.seq; id++ {
+		e := h.replay[id%ReplaySize]
+		if filter == nil || filter(e) {
+			missed = append(missed, e)
+		}
+	}
+
+	h.subs[s] = struct{}{}
+	return s, missed
+}
+
+// Unsubscribe ends the subscription, it is safe to call after the hub dropped it
+func (h *Hub) Unsubscribe(s *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.remove(s, nil)
+}
+
+// Publish delivers to the subscribers of this process only, see RedisRelay for replicas
+func (h *Hub) Publish(_ context.Context, e *domain.PriceEvent) error {
+	h.Broadcast(e)
+	return nil
+}
+
+// Broadcast never blocks: a subscriber with a full buffer is dropped instead.
+// Subscribers get a numbered copy of e.
+func (h *Hub) Broadcast(e *domain.PriceEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.seq++
+	numbered := *e
+	numbered.ID = h.seq
+	e = &numbered
+	h.replay[h.seq%ReplaySize] = e
+
+	for s := range h.subs {
+		if s.filter != nil && !s.filter(e) {
+			continue
+		}
+		select {
+		case s.events <- e:
+		default:
+			h.remove(s, ErrSlowSubscriber)
+			h.logger.Warn("dropped slow price stream subscriber", slog.Int("buffer", h.buffer))
+		}
+	}
+}
+
+// Len is the number of live subscribers
+func (h *Hub) Len() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subs)
+}
+
+// remove must be called with mu held
+func (h *Hub) remove(s *Subscription, err error) {
+	if _, ok := h.subs[s]; !ok {
+		return
+	}
+	delete(h.subs, s)
+	s.err = err
+	close(s.events)
+}
@@ -0,0 +1,108 @@
+package stream
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/derkres11/price-pulse/internal/domain"
+)
+
+func TestHub_Broadcast(t *testing.T) {
+	hub := NewHub(4, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	all := hub.Subscribe(nil)
+	onlyTwo := hub.Subscribe(func(e *domain.PriceEvent) bool { return e.ProductID == 2 })
+
+	for _, id := range []int64{1, 2, 3} {
+		hub.Broadcast(&domain.PriceEvent{ProductID: id})
+	}
+
+	if got := len(all.Events()); got != 3 {
+		t.Errorf("expected 3 events for the unfiltered subscriber, got %d", got)
+	}
+	if got := len(onlyTwo.Events()); got != 1 {
+		t.Fatalf("expected 1 event for the filtered subscriber, got %d", got)
+	}
+	if e := <-onlyTwo.Events(); e.ProductID != 2 {
+		t.Errorf("expected product 2, got %d", e.ProductID)
+	}
+}
+
+func TestHub_SlowSubscriber(t *testing.T) {
+	hub := NewHub(2, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	slow := hub.Subscribe(nil)
+	fast := hub.Subscribe(nil)
+
+	for i := 0; i < 3; i++ {
+		hub.Broadcast(&domain.PriceEvent{ProductID: 1})
+		<-fast.Events()
+	}
+
+	// The two buffered events are still readable, then the channel is closed
+	var n int
+	for range slow.Events() {
+		n++
+	}
+	if n != 2 {
+		t.Errorf("expected 2 buffered events, got %d", n)
+	}
+	if !errors.Is(slow.Err(), ErrSlowSubscriber) {
+		t.Errorf("expected ErrSlowSubscriber, got %v", slow.Err())
+	}
+	if hub.Len() != 1 {
+		t.Errorf("expected only the fast subscriber to remain, got %d", hub.Len())
+	}
+
+	// Unsubscribing a dropped subscriber is a no-op
+	hub.Unsubscribe(slow)
+	hub.Unsubscribe(fast)
+	if _, ok := <-fast.Events(); ok || fast.Err() != nil {
+		t.Errorf("expected a clean close after Unsubscribe, got err %v", fast.Err())
+	}
+}
+
+func TestHub_Resume(t *testing.T) {
+	hub := NewHub(DefaultBuffer, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	for i := 0; i < ReplaySize+10; i++ {
+		hub.Broadcast(&domain.PriceEvent{ProductID: int64(i%2 + 1)})
+	}
+	last := int64(ReplaySize + 10)
+
+	tests := []struct {
+		name      string
+		lastID    int64
+		filter    func(e *domain.PriceEvent) bool
+		wantCount int
+		wantFirst int64
+	}{
+		{"Caught up", last, nil, 0, 0},
+		{"Missed a few", last - 3, nil, 3, last - 2},
+		{"Filtered", last - 4, func(e *domain.PriceEvent) bool { return e.ProductID == 1 }, 2, last - 3},
+		{"Older than the buffer", 1, nil, ReplaySize, last - ReplaySize + 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub, missed := hub.Resume(tt.lastID, tt.filter)
+			defer hub.Unsubscribe(sub)
+
+			if len(missed) != tt.wantCount {
+				t.Fatalf("expected %d events, got %d", tt.wantCount, len(missed))
+			}
+			if tt.wantCount > 0 && missed[0].ID != tt.wantFirst {
+				t.Errorf("expected replay to start at %d, got %d", tt.wantFirst, missed[0].ID)
+			}
+		})
+	}
+
+	// Live events continue the numbering
+	sub, _ := hub.Resume(last, nil)
+	hub.Broadcast(&domain.PriceEvent{ProductID: 1})
+	if e := <-sub.Events(); e.ID != last+1 {
+		t.Errorf("expected live event %d, got %d", last+1, e.ID)
+	}
+}
@@ -0,0 +1,74 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/derkres11/price-pulse/internal/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+const priceEventsChannel = "pricepulse:price-events"
+
+// RedisRelay shares price events between replicas. The watcher that checked a product
+// publishes to Redis and every replica, itself included, hands the event to its hub.
+type RedisRelay struct {
+	client *redis.Client
+	hub    *Hub
+	logger *slog.Logger
+}
+
+func NewRedisRelay(opts *redis.Options, hub *Hub, logger *slog.Logger) *RedisRelay {
+	return &RedisRelay{
+		client: redis.NewClient(opts),
+		hub:    hub,
+		logger: logger,
+	}
+}
+
+// Publish sends the event to every replica. When Redis is unreachable the local
+// subscribers still get it.
+func (r *RedisRelay) Publish(ctx context.Context, e *domain.PriceEvent) error {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode price event: %w", err)
+	}
+
+	if err := r.client.Publish(ctx, priceEventsChannel, payload).Err(); err != nil {
+		r.hub.Broadcast(e)
+		return fmt.Errorf("failed to publish price event: %w", err)
+	}
+	return nil
+}
+
+// Run feeds events published by any replica into the local hub until ctx is done.
+// go-redis reconnects the subscription on its own, events sent meanwhile are lost.
+func (r *RedisRelay) Run(ctx context.Context) error {
+	ps := r.client.Subscribe(ctx, priceEventsChannel)
+	defer ps.Close()
+
+	messages := ps.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case msg, ok := <-messages:
+			if !ok {
+				return nil
+			}
+
+			e := &domain.PriceEvent{}
+			if err := json.Unmarshal([]byte(msg.Payload), e); err != nil {
+				r.logger.Error("skipping malformed price event", slog.String("error", err.Error()))
+				continue
+			}
+			r.hub.Broadcast(e)
+		}
+	}
+}
+
+func (r *RedisRelay) Close() error {
+	return r.client.Close()
+}
@@ -0,0 +1,55 @@
+package stream
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/derkres11/price-pulse/internal/domain"
+)
+
+// ErrInvalidSelection is returned for stream requests that select nothing or too much
+var ErrInvalidSelection = domain.NewError(domain.ErrInvalidArgument, "invalid stream selection")
+
+// MaxProducts bounds the filter every published event runs through
+const MaxProducts = 1000
+
+// Selection is what a client asked to stream, the same for gRPC, SSE and WebSocket
+type Selection struct {
+	UserID     int64
+	ProductIDs []int64
+	Watchlist  bool // add the user's watchlist as it is when the stream starts
+}
+
+// Filter resolves the selection into the predicate the hub runs for the client.
+// Alerts of somebody else's watchlist entry never pass it.
+func (s Selection) Filter(ctx context.Context, products domain.ProductService) (func(e *domain.PriceEvent) bool, error) {
+	ids := make(map[int64]struct{}, len(s.ProductIDs))
+	for _, id := range s.ProductIDs {
+		ids[id] = struct{}{}
+	}
+	if s.Watchlist {
+		subs, err := products.ListWatchlist(ctx, s.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("error loading watchlist: %w", err)
+		}
+		for _, sub := range subs {
+			ids[sub.ProductID] = struct{}{}
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("%w: no products to watch", ErrInvalidSelection)
+	}
+	if len(ids) > MaxProducts {
+		return nil, fmt.Errorf("%w: at most %d products per stream", ErrInvalidSelection, MaxProducts)
+	}
+
+	// ids is read by the hub from now on and must not change
+	userID := s.UserID
+	return func(e *domain.PriceEvent) bool {
+		if _, ok := ids[e.ProductID]; !ok {
+			return false
+		}
+		return e.Alert == nil || e.Alert.UserID == 0 || e.Alert.UserID == userID
+	}, nil
+}
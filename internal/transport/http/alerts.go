@@ -0,0 +1,150 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/derkres11/price-pulse/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+type alertRuleInput struct {
+	Type      domain.AlertRuleType `json:"type" binding:"required"`
+	Threshold float64              `json:"threshold"`
+	Percent   float64              `json:"percent"`
+	Baseline  float64              `json:"baseline"`
+	Days      int                  `json:"days"`
+	Enabled   *bool                `json:"enabled"`
+}
+
+func (in alertRuleInput) rule() *domain.AlertRule {
+	return &domain.AlertRule{
+		Type:      in.Type,
+		Threshold: in.Threshold,
+		Percent:   in.Percent,
+		Baseline:  in.Baseline,
+		Days:      in.Days,
+		Enabled:   in.Enabled == nil || *in.Enabled,
+	}
+}
+
+// CreateAlertRule godoc
+// @Summary Add an alert rule to a product
+// @Description Admin only, product rules alert the operator. Watchers add rules to their subscription.
+// @Tags alerts
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param input body alertRuleInput true "Rule"
+// @Success 201 {object} domain.AlertRule
+// @Failure 400 {object} Problem
+// @Router /products/{id}/alert-rules [post]
+
+func (h *Handler) CreateAlertRule(c *gin.Context) {
+	productID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		problem(c, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	var input alertRuleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	rule := input.rule()
+	rule.ProductID = productID
+
+	if err := h.services.CreateAlertRule(c.Request.Context(), rule); err != nil {
+		h.fail(c, err, "alert rule")
+		return
+	}
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+// ListAlertRules godoc
+// @Summary List alert rules of a product
+// @Tags alerts
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Product ID"
+// @Success 200 {array} domain.AlertRule
+// @Router /products/{id}/alert-rules [get]
+
+func (h *Handler) ListAlertRules(c *gin.Context) {
+	productID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		problem(c, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	rules, err := h.services.ListAlertRules(c.Request.Context(), productID)
+	if err != nil {
+		h.fail(c, err, "alert rule")
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+// DeleteAlertRule godoc
+// @Summary Remove an alert rule
+// @Description Admin only
+// @Tags alerts
+// @Security BearerAuth
+// @Param id path int true "Product ID"
+// @Param rule_id path int true "Rule ID"
+// @Success 204
+// @Failure 404 {object} Problem
+// @Router /products/{id}/alert-rules/{rule_id} [delete]
+
+func (h *Handler) DeleteAlertRule(c *gin.Context) {
+	productID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		problem(c, http.StatusBadRequest, "invalid id")
+		return
+	}
+	ruleID, err := strconv.ParseInt(c.Param("rule_id"), 10, 64)
+	if err != nil {
+		problem(c, http.StatusBadRequest, "invalid rule id")
+		return
+	}
+
+	if err := h.services.DeleteAlertRule(c.Request.Context(), productID, ruleID); err != nil {
+		h.fail(c, err, "alert rule")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListAlertEvents godoc
+// @Summary List the latest alerts fired for a product
+// @Description Alerts of the product's own rules and of the caller's subscription
+// @Tags alerts
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param limit query int false "Max events (default 50)"
+// @Success 200 {array} domain.AlertEvent
+// @Router /products/{id}/alerts [get]
+
+func (h *Handler) ListAlertEvents(c *gin.Context) {
+	productID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		problem(c, http.StatusBadRequest, "invalid id")
+		return
+	}
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	events, err := h.services.ListAlertEvents(c.Request.Context(), currentUser(c), productID, limit)
+	if err != nil {
+		h.fail(c, err, "alert")
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
+}
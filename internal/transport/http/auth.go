@@ -0,0 +1,136 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/derkres11/price-pulse/internal/auth"
+	"github.com/derkres11/price-pulse/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// apiKeyHeader is accepted next to "Authorization: Bearer" for clients that cannot set the latter
+const apiKeyHeader = "X-API-Key"
+
+// authenticate puts the caller on the request context and rejects anonymous requests
+func (h *Handler) authenticate(c *gin.Context) {
+	credential, ok := auth.BearerToken(c.GetHeader("Authorization"))
+	if !ok {
+		credential = c.GetHeader(apiKeyHeader)
+	}
+	if credential == "" {
+		c.Header("WWW-Authenticate", `Bearer realm="price-pulse"`)
+		problem(c, http.StatusUnauthorized, "authentication required")
+		return
+	}
+
+	principal, err := h.auth.Authenticate(c.Request.Context(), credential)
+	if err != nil {
+		if errors.Is(err, auth.ErrUnauthenticated) {
+			c.Header("WWW-Authenticate", `Bearer realm="price-pulse", error="invalid_token"`)
+			problem(c, http.StatusUnauthorized, "invalid credentials")
+			return
+		}
+		h.fail(c, fmt.Errorf("%w: %w", domain.ErrUnavailable, err), "authentication")
+		return
+	}
+
+	c.Request = c.Request.WithContext(domain.WithPrincipal(c.Request.Context(), principal))
+	c.Next()
+}
+
+// requireAdmin only lets admin principals through, it runs after authenticate
+func requireAdmin(c *gin.Context) {
+	if p := domain.PrincipalFromContext(c.Request.Context()); p == nil || !p.Admin {
+		problem(c, http.StatusForbidden, "admin only")
+		return
+	}
+	c.Next()
+}
+
+func currentUser(c *gin.Context) int64 {
+	id, _ := domain.UserIDFromContext(c.Request.Context())
+	return id
+}
+
+type apiKeyInput struct {
+	Name  string `json:"name" binding:"required"`
+	Admin bool   `json:"admin"`
+}
+
+type createdAPIKey struct {
+	*domain.APIKey
+	Key string `json:"key"` // shown only once
+}
+
+// CreateAPIKey godoc
+// @Summary Create an API key for the caller
+// @Description The key is returned once and only its hash is stored. Admin keys can only be created by admins.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body apiKeyInput true "Key"
+// @Success 201 {object} createdAPIKey
+// @Failure 403 {object} Problem
+// @Router /api-keys [post]
+
+func (h *Handler) CreateAPIKey(c *gin.Context) {
+	var input apiKeyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	k, plain, err := h.apiKeys.CreateKey(c.Request.Context(), currentUser(c), input.Name, input.Admin)
+	if err != nil {
+		h.fail(c, err, "api key")
+		return
+	}
+
+	c.JSON(http.StatusCreated, createdAPIKey{APIKey: k, Key: plain})
+}
+
+// ListAPIKeys godoc
+// @Summary List the caller's API keys
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} domain.APIKey
+// @Router /api-keys [get]
+
+func (h *Handler) ListAPIKeys(c *gin.Context) {
+	keys, err := h.apiKeys.ListKeys(c.Request.Context(), currentUser(c))
+	if err != nil {
+		h.fail(c, err, "api key")
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+// RevokeAPIKey godoc
+// @Summary Revoke one of the caller's API keys
+// @Tags auth
+// @Security BearerAuth
+// @Param id path int true "Key ID"
+// @Success 204
+// @Failure 404 {object} Problem
+// @Router /api-keys/{id} [delete]
+
+func (h *Handler) RevokeAPIKey(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		problem(c, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	if err := h.apiKeys.RevokeKey(c.Request.Context(), currentUser(c), id); err != nil {
+		h.fail(c, err, "api key")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
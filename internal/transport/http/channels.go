@@ -0,0 +1,115 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/derkres11/price-pulse/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// channelOwnerKey is set on the admin routes, which manage the operator channels
+const channelOwnerKey = "channel_owner"
+
+func operatorChannels(c *gin.Context) {
+	c.Set(channelOwnerKey, int64(0))
+	c.Next()
+}
+
+// channelOwner is the caller, or 0 for the operator channels under /admin
+func channelOwner(c *gin.Context) int64 {
+	if owner, ok := c.Get(channelOwnerKey); ok {
+		return owner.(int64)
+	}
+	return currentUser(c)
+}
+
+type channelInput struct {
+	Name     string             `json:"name" binding:"required"`
+	Type     domain.ChannelType `json:"type" binding:"required"`
+	Target   string             `json:"target" binding:"required"`
+	Secret   string             `json:"secret"`
+	Template string             `json:"template"`
+	Enabled  *bool              `json:"enabled"`
+}
+
+// CreateChannel godoc
+// @Summary Add a notification channel
+// @Description Channels belong to the caller and receive their watchlist alerts. Operator channels under /admin/channels receive product level alerts.
+// @Description Types: webhook (target is a URL, secret enables HMAC signing), email, telegram (chat id), slack (channel).
+// @Description Repeats are limited per watchlist entry, see /watchlist/{id}/alert-policy, quiet hours per user, see /me/quiet-hours.
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body channelInput true "Channel"
+// @Success 201 {object} domain.NotificationChannel
+// @Failure 400 {object} Problem
+// @Router /channels [post]
+
+func (h *Handler) CreateChannel(c *gin.Context) {
+	var input channelInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ch := &domain.NotificationChannel{
+		UserID:   channelOwner(c),
+		Name:     input.Name,
+		Type:     input.Type,
+		Target:   input.Target,
+		Secret:   input.Secret,
+		Template: input.Template,
+		Enabled:  input.Enabled == nil || *input.Enabled,
+	}
+
+	if err := h.notifications.CreateChannel(c.Request.Context(), ch); err != nil {
+		h.fail(c, err, "channel")
+		return
+	}
+
+	c.JSON(http.StatusCreated, ch)
+}
+
+// ListChannels godoc
+// @Summary List notification channels
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} domain.NotificationChannel
+// @Router /channels [get]
+
+func (h *Handler) ListChannels(c *gin.Context) {
+	channels, err := h.notifications.ListChannels(c.Request.Context(), channelOwner(c))
+	if err != nil {
+		h.fail(c, err, "channel")
+		return
+	}
+
+	c.JSON(http.StatusOK, channels)
+}
+
+// DeleteChannel godoc
+// @Summary Remove a notification channel
+// @Tags notifications
+// @Security BearerAuth
+// @Param id path int true "Channel ID"
+// @Success 204
+// @Failure 404 {object} Problem
+// @Router /channels/{id} [delete]
+
+func (h *Handler) DeleteChannel(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		problem(c, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	if err := h.notifications.DeleteChannel(c.Request.Context(), channelOwner(c), id); err != nil {
+		h.fail(c, err, "channel")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,25 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListCircuits godoc
+// @Summary List the circuit breakers of failing shops
+// @Description Hosts whose circuit is open or half-open, or that failed lately. The circuits are shared by all replicas.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} domain.HostCircuit
+// @Router /admin/circuits [get]
+
+func (h *Handler) ListCircuits(c *gin.Context) {
+	circuits, err := h.circuits.Circuits(c.Request.Context())
+	if err != nil {
+		h.fail(c, err, "circuits")
+		return
+	}
+	c.JSON(http.StatusOK, circuits)
+}
@@ -0,0 +1,79 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultDeadLetterLimit = 50
+	maxDeadLetterLimit     = 500
+)
+
+// ListDeadLetters godoc
+// @Summary List dead-lettered product updates
+// @Description The newest dead letters of every partition, newest first
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Dead letters per partition (default 50, max 500)"
+// @Success 200 {array} domain.DeadLetter
+// @Failure 400 {object} Problem
+// @Failure 503 {object} Problem
+// @Router /admin/dlq [get]
+
+func (h *Handler) ListDeadLetters(c *gin.Context) {
+	limit := defaultDeadLetterLimit
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxDeadLetterLimit {
+			problem(c, http.StatusBadRequest, "limit must be between 1 and 500")
+			return
+		}
+		limit = n
+	}
+
+	letters, err := h.dlq.List(c.Request.Context(), limit)
+	if err != nil {
+		h.fail(c, err, "dead letter")
+		return
+	}
+
+	c.JSON(http.StatusOK, letters)
+}
+
+// ReplayDeadLetter godoc
+// @Summary Replay a dead letter
+// @Description Sends the message back to its original topic. It stays on the dead-letter topic.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param partition path int true "Dead-letter partition"
+// @Param offset path int true "Dead-letter offset"
+// @Success 202 {object} map[string]interface{}
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 503 {object} Problem
+// @Router /admin/dlq/{partition}/{offset}/replay [post]
+
+func (h *Handler) ReplayDeadLetter(c *gin.Context) {
+	partition, err := strconv.Atoi(c.Param("partition"))
+	if err != nil || partition < 0 {
+		problem(c, http.StatusBadRequest, "invalid partition")
+		return
+	}
+	offset, err := strconv.ParseInt(c.Param("offset"), 10, 64)
+	if err != nil || offset < 0 {
+		problem(c, http.StatusBadRequest, "invalid offset")
+		return
+	}
+
+	if err := h.dlq.Replay(c.Request.Context(), partition, offset); err != nil {
+		h.fail(c, err, "dead letter")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"partition": partition, "offset": offset, "status": "replayed"})
+}
@@ -0,0 +1,75 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/derkres11/price-pulse/internal/auth"
+	"github.com/derkres11/price-pulse/internal/domain"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// apiKeyMetadata is accepted next to "authorization: Bearer ..."
+const apiKeyMetadata = "x-api-key"
+
+// AuthUnaryInterceptor authenticates every unary call and puts the principal on its context
+func AuthUnaryInterceptor(a *auth.Authenticator, logger *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := authenticate(ctx, a, logger)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// AuthStreamInterceptor does the same for streaming calls
+func AuthStreamInterceptor(a *auth.Authenticator, logger *slog.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), a, logger)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// contextStream serves ctx in place of the stream's own context
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+func authenticate(ctx context.Context, a *auth.Authenticator, logger *slog.Logger) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	var credential string
+	if values := md.Get("authorization"); len(values) > 0 {
+		credential, _ = auth.BearerToken(values[0])
+	}
+	if values := md.Get(apiKeyMetadata); credential == "" && len(values) > 0 {
+		credential = values[0]
+	}
+	if credential == "" {
+		return nil, status.Error(codes.Unauthenticated, "authentication required")
+	}
+
+	principal, err := a.Authenticate(ctx, credential)
+	if err != nil {
+		if errors.Is(err, auth.ErrUnauthenticated) {
+			return nil, status.Error(codes.Unauthenticated, "invalid credentials")
+		}
+		logger.Error("failed to authenticate call", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Unavailable, "authentication unavailable")
+	}
+
+	return domain.WithPrincipal(ctx, principal), nil
+}
@@ -0,0 +1,80 @@
+package grpc
+
+import (
+	"errors"
+	"log/slog"
+
+	"github.com/derkres11/price-pulse/internal/domain"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errorDomain names the service in ErrorInfo details
+const errorDomain = "price-pulse"
+
+// toStatus maps the kind of err to a status code. resource names what was asked for,
+// e.g. "product". The ErrorInfo reason is the same code REST clients get in problem+json.
+// Invalid and forbidden calls get the error's public message, what it hides is logged.
+func toStatus(err error, resource string) error {
+	var (
+		code codes.Code
+		msg  string
+	)
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		code, msg = codes.NotFound, resource+" not found"
+	case errors.Is(err, domain.ErrAlreadyExists):
+		code, msg = codes.AlreadyExists, resource+" already exists"
+	case errors.Is(err, domain.ErrInvalidArgument):
+		code, msg = codes.InvalidArgument, domain.PublicMessage(err)
+	case errors.Is(err, domain.ErrPermissionDenied):
+		code, msg = codes.PermissionDenied, domain.PublicMessage(err)
+	case errors.Is(err, domain.ErrUnavailable):
+		code, msg = codes.Unavailable, "temporarily unavailable, retry later"
+	default:
+		code, msg = codes.Internal, resource+" request failed"
+	}
+
+	switch {
+	case code == codes.Internal:
+		slog.Error(resource+" call failed", slog.String("error", err.Error()))
+	case code == codes.Unavailable:
+		slog.Warn(resource+" call unavailable", slog.String("error", err.Error()))
+	case (code == codes.InvalidArgument || code == codes.PermissionDenied) && msg != err.Error():
+		slog.Warn(resource+" call rejected", slog.String("error", err.Error()))
+	}
+	return withReason(code, msg, resource)
+}
+
+// withReason builds the status with an ErrorInfo detail carrying the reason
+func withReason(code codes.Code, msg, resource string) error {
+	st := status.New(code, msg)
+	info := &errdetails.ErrorInfo{
+		Reason:   reason(code),
+		Domain:   errorDomain,
+		Metadata: map[string]string{"resource": resource},
+	}
+	if detailed, err := st.WithDetails(info); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+// reason spells the code the way problem+json does, e.g. NOT_FOUND
+func reason(code codes.Code) string {
+	switch code {
+	case codes.NotFound:
+		return "NOT_FOUND"
+	case codes.AlreadyExists:
+		return "ALREADY_EXISTS"
+	case codes.InvalidArgument:
+		return "INVALID_ARGUMENT"
+	case codes.PermissionDenied:
+		return "PERMISSION_DENIED"
+	case codes.Unavailable:
+		return "UNAVAILABLE"
+	default:
+		return "INTERNAL"
+	}
+}
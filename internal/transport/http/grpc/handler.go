@@ -2,34 +2,223 @@ package grpc
 
 import (
 	"context"
+	"strings"
+	"time"
 
 	"github.com/derkres11/price-pulse/internal/domain"
+	"github.com/derkres11/price-pulse/internal/stream"
 	desc "github.com/derkres11/price-pulse/pkg/api/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+const defaultHistoryWindow = 30 * 24 * time.Hour
+
 type Handler struct {
 	desc.UnimplementedProductServiceServer
 	service domain.ProductService
+	hub     *stream.Hub
 }
 
-func NewHandler(svc domain.ProductService) *Handler {
+func NewHandler(svc domain.ProductService, hub *stream.Hub) *Handler {
 	return &Handler{
 		service: svc,
+		hub:     hub,
 	}
 }
 
 func (h *Handler) GetProduct(ctx context.Context, req *desc.GetProductRequest) (*desc.GetProductResponse, error) {
 	product, err := h.service.GetByID(ctx, req.GetId())
 	if err != nil {
-		return nil, err
+		return nil, toStatus(err, "product")
 	}
 
 	return &desc.GetProductResponse{
 		Id:           product.ID,
+		Url:          product.URL,
 		Title:        product.Title,
 		CurrentPrice: product.CurrentPrice,
 		TargetPrice:  product.TargetPrice,
 		CreatedAt:    timestamppb.New(product.CreatedAt),
+		UpdatedAt:    timestamppb.New(product.UpdatedAt),
 	}, nil
 }
+
+// CreateProduct leaves validation to the service, REST gets the same checks
+func (h *Handler) CreateProduct(ctx context.Context, req *desc.CreateProductRequest) (*desc.Product, error) {
+	p := &domain.Product{URL: req.GetUrl(), Title: req.GetTitle(), TargetPrice: req.GetTargetPrice()}
+	if p.Title == "" {
+		p.Title = domain.PendingTitle
+	}
+	if err := h.service.Create(ctx, p); err != nil {
+		return nil, toStatus(err, "product")
+	}
+	return toProduct(p), nil
+}
+
+func (h *Handler) TrackProduct(ctx context.Context, req *desc.TrackProductRequest) (*desc.Subscription, error) {
+	userID, err := caller(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sub, err := h.service.TrackProduct(ctx, userID, req.GetUrl(), req.GetTargetPrice())
+	if err != nil {
+		return nil, toStatus(err, "subscription")
+	}
+	return toSubscription(sub), nil
+}
+
+func (h *Handler) ListProducts(ctx context.Context, req *desc.ListProductsRequest) (*desc.ListProductsResponse, error) {
+	if req.GetPageSize() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "page_size must not be negative")
+	}
+
+	q := domain.ProductQuery{
+		ProductFilter: domain.ProductFilter{
+			Host:        strings.ToLower(strings.TrimSpace(req.GetHost())),
+			MinPrice:    req.GetMinPrice(),
+			MaxPrice:    req.GetMaxPrice(),
+			BelowTarget: req.GetBelowTarget(),
+			Search:      strings.TrimSpace(req.GetQuery()),
+		},
+		Limit: int(req.GetPageSize()),
+	}
+	if req.UpdatedSince != nil {
+		q.UpdatedSince = req.GetUpdatedSince().AsTime()
+	}
+
+	sort, descending := strings.CutPrefix(req.GetOrderBy(), "-")
+	q.Sort, q.Desc = domain.ProductSort(sort), descending
+
+	page, err := h.service.ListProducts(ctx, q, req.GetPageToken())
+	if err != nil {
+		return nil, toStatus(err, "product")
+	}
+
+	resp := &desc.ListProductsResponse{
+		Products:      make([]*desc.Product, 0, len(page.Items)),
+		NextPageToken: page.NextCursor,
+	}
+	for _, p := range page.Items {
+		resp.Products = append(resp.Products, toProduct(p))
+	}
+	return resp, nil
+}
+
+func (h *Handler) UpdateProduct(ctx context.Context, req *desc.UpdateProductRequest) (*desc.Product, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	var patch domain.ProductPatch
+	if req.Title != nil {
+		title := req.GetTitle()
+		patch.Title = &title
+	}
+	if req.TargetPrice != nil {
+		target := req.GetTargetPrice()
+		patch.TargetPrice = &target
+	}
+
+	p, err := h.service.UpdateProduct(ctx, req.GetId(), patch)
+	if err != nil {
+		return nil, toStatus(err, "product")
+	}
+	return toProduct(p), nil
+}
+
+func (h *Handler) DeleteProduct(ctx context.Context, req *desc.DeleteProductRequest) (*desc.DeleteProductResponse, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := h.service.DeleteProduct(ctx, req.GetId()); err != nil {
+		return nil, toStatus(err, "product")
+	}
+	return &desc.DeleteProductResponse{}, nil
+}
+
+func (h *Handler) GetPriceHistory(ctx context.Context, req *desc.GetPriceHistoryRequest) (*desc.PriceHistory, error) {
+	to := time.Now().UTC()
+	if req.To != nil {
+		to = req.GetTo().AsTime()
+	}
+	from := to.Add(-defaultHistoryWindow)
+	if req.From != nil {
+		from = req.GetFrom().AsTime()
+	}
+
+	var step time.Duration
+	if req.Step != nil {
+		step = req.GetStep().AsDuration()
+		if step < time.Minute {
+			return nil, status.Error(codes.InvalidArgument, "step must be at least 1m")
+		}
+	}
+
+	history, err := h.service.GetPriceHistory(ctx, req.GetId(), from, to, step)
+	if err != nil {
+		return nil, toStatus(err, "product")
+	}
+	return toPriceHistory(history), nil
+}
+
+// requireAdmin guards the RPCs that change products shared by every watcher
+func requireAdmin(ctx context.Context) error {
+	if p := domain.PrincipalFromContext(ctx); p == nil || !p.Admin {
+		return withReason(codes.PermissionDenied, "admin only", "product")
+	}
+	return nil
+}
+
+func toProduct(p *domain.Product) *desc.Product {
+	return &desc.Product{
+		Id:           p.ID,
+		Url:          p.URL,
+		Title:        p.Title,
+		CurrentPrice: p.CurrentPrice,
+		TargetPrice:  p.TargetPrice,
+		CreatedAt:    timestamppb.New(p.CreatedAt),
+		UpdatedAt:    timestamppb.New(p.UpdatedAt),
+	}
+}
+
+func toPriceHistory(h *domain.PriceHistory) *desc.PriceHistory {
+	out := &desc.PriceHistory{
+		ProductId: h.ProductID,
+		From:      timestamppb.New(h.From),
+		To:        timestamppb.New(h.To),
+		Step:      h.Step,
+		Points:    make([]*desc.PricePoint, 0, len(h.Points)),
+		Buckets:   make([]*desc.PriceBucket, 0, len(h.Buckets)),
+	}
+	for _, p := range h.Points {
+		out.Points = append(out.Points, toPricePoint(p))
+	}
+	for _, b := range h.Buckets {
+		out.Buckets = append(out.Buckets, &desc.PriceBucket{
+			Start: timestamppb.New(b.Start),
+			Min:   b.Min,
+			Max:   b.Max,
+			Avg:   b.Avg,
+			Last:  b.Last,
+			Count: int64(b.Count),
+		})
+	}
+	if h.AllTimeLow != nil {
+		out.AllTimeLow = toPricePoint(h.AllTimeLow)
+	}
+	return out
+}
+
+func toPricePoint(p *domain.PricePoint) *desc.PricePoint {
+	return &desc.PricePoint{
+		Price:      p.Price,
+		Currency:   p.Currency,
+		InStock:    p.InStock,
+		Source:     p.Source,
+		ObservedAt: timestamppb.New(p.ObservedAt),
+	}
+}
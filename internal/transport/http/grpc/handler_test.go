@@ -0,0 +1,51 @@
+package grpc
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/derkres11/price-pulse/internal/domain"
+	"github.com/derkres11/price-pulse/internal/service"
+	desc "github.com/derkres11/price-pulse/pkg/api/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// The requests below are refused before the service touches any dependency
+func TestHandler_RejectsInvalidURLs(t *testing.T) {
+	svc := service.NewProductService(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, slog.New(slog.DiscardHandler))
+	h := NewHandler(svc, nil)
+	ctx := domain.WithPrincipal(context.Background(), &domain.Principal{UserID: 1})
+
+	tests := []struct {
+		name string
+		url  string
+	}{
+		{"Empty", ""},
+		{"No scheme", "shop.example/item"},
+		{"Not http", "ftp://shop.example/item"},
+		{"No host", "https:///item"},
+		{"Too long", "https://shop.example/" + strings.Repeat("a", 2048)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := h.CreateProduct(ctx, &desc.CreateProductRequest{Url: tt.url})
+			if code := status.Code(err); code != codes.InvalidArgument {
+				t.Errorf("CreateProduct: expected InvalidArgument, got %v (%v)", code, err)
+			}
+
+			_, err = h.TrackProduct(ctx, &desc.TrackProductRequest{Url: tt.url})
+			if code := status.Code(err); code != codes.InvalidArgument {
+				t.Errorf("TrackProduct: expected InvalidArgument, got %v (%v)", code, err)
+			}
+		})
+	}
+
+	_, err := h.CreateProduct(ctx, &desc.CreateProductRequest{Url: "https://shop.example/item", TargetPrice: -1})
+	if code := status.Code(err); code != codes.InvalidArgument {
+		t.Errorf("negative target: expected InvalidArgument, got %v", code)
+	}
+}
@@ -0,0 +1,56 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/derkres11/price-pulse/internal/domain"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	requestIDMetadata   = "x-request-id"
+	traceparentMetadata = "traceparent"
+)
+
+// RequestContextUnaryInterceptor gives every call an ID and a trace context, taken from
+// the x-request-id and traceparent metadata when the caller sent them. The ID is
+// returned in the response header.
+func RequestContextUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx = requestContext(ctx)
+		grpc.SetHeader(ctx, metadata.Pairs(requestIDMetadata, domain.RequestIDFromContext(ctx)))
+		return handler(ctx, req)
+	}
+}
+
+// RequestContextStreamInterceptor does the same for streaming calls
+func RequestContextStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := requestContext(ss.Context())
+		ss.SetHeader(metadata.Pairs(requestIDMetadata, domain.RequestIDFromContext(ctx)))
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func requestContext(ctx context.Context) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	first := func(key string) string {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+
+	id := first(requestIDMetadata)
+	if !domain.ValidRequestID(id) {
+		id = domain.NewRequestID()
+	}
+	trace, err := domain.ParseTraceparent(first(traceparentMetadata))
+	if err != nil {
+		trace = domain.NewTraceContext()
+	} else {
+		trace = trace.Child()
+	}
+	return domain.WithTrace(domain.WithRequestID(ctx, id), trace)
+}
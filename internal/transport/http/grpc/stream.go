@@ -0,0 +1,72 @@
+package grpc
+
+import (
+	"errors"
+
+	"github.com/derkres11/price-pulse/internal/domain"
+	"github.com/derkres11/price-pulse/internal/stream"
+	desc "github.com/derkres11/price-pulse/pkg/api/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (h *Handler) WatchPrices(req *desc.WatchPricesRequest, srv grpc.ServerStreamingServer[desc.PriceEvent]) error {
+	ctx := srv.Context()
+	userID, err := caller(ctx)
+	if err != nil {
+		return err
+	}
+
+	sel := stream.Selection{UserID: userID, ProductIDs: req.GetProductIds(), Watchlist: req.GetWatchlist()}
+	filter, err := sel.Filter(ctx, h.service)
+	if err != nil {
+		return toStatus(err, "stream")
+	}
+
+	sub := h.hub.Subscribe(filter)
+	defer h.hub.Unsubscribe(sub)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case e, ok := <-sub.Events():
+			if !ok {
+				if errors.Is(sub.Err(), stream.ErrSlowSubscriber) {
+					return status.Error(codes.ResourceExhausted, "client too slow, resubscribe")
+				}
+				return nil
+			}
+			if err := srv.Send(toPriceEvent(e)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func toPriceEvent(e *domain.PriceEvent) *desc.PriceEvent {
+	out := &desc.PriceEvent{
+		Type:       desc.PriceEvent_TYPE_PRICE,
+		ProductId:  e.ProductID,
+		Title:      e.Title,
+		Price:      e.Price,
+		Currency:   e.Currency,
+		InStock:    e.InStock,
+		ObservedAt: timestamppb.New(e.ObservedAt),
+	}
+	if a := e.Alert; a != nil {
+		out.Type = desc.PriceEvent_TYPE_ALERT
+		out.Alert = &desc.AlertEvent{
+			Id:             a.ID,
+			RuleId:         a.RuleID,
+			SubscriptionId: a.SubscriptionID,
+			Type:           string(a.Type),
+			Price:          a.Price,
+			PreviousPrice:  a.PreviousPrice,
+			Reason:         a.Reason,
+		}
+	}
+	return out
+}
@@ -0,0 +1,101 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/derkres11/price-pulse/internal/domain"
+	desc "github.com/derkres11/price-pulse/pkg/api/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type WatchlistHandler struct {
+	desc.UnimplementedWatchlistServiceServer
+	service domain.ProductService
+}
+
+func NewWatchlistHandler(svc domain.ProductService) *WatchlistHandler {
+	return &WatchlistHandler{
+		service: svc,
+	}
+}
+
+func (h *WatchlistHandler) Watch(ctx context.Context, req *desc.WatchRequest) (*desc.Subscription, error) {
+	userID, err := caller(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sub, err := h.service.TrackProduct(ctx, userID, req.GetUrl(), req.GetTargetPrice())
+	if err != nil {
+		return nil, toStatus(err, "subscription")
+	}
+	return toSubscription(sub), nil
+}
+
+func (h *WatchlistHandler) ListWatchlist(ctx context.Context, _ *desc.ListWatchlistRequest) (*desc.ListWatchlistResponse, error) {
+	userID, err := caller(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	subs, err := h.service.ListWatchlist(ctx, userID)
+	if err != nil {
+		return nil, toStatus(err, "subscription")
+	}
+
+	resp := &desc.ListWatchlistResponse{Subscriptions: make([]*desc.Subscription, 0, len(subs))}
+	for _, sub := range subs {
+		resp.Subscriptions = append(resp.Subscriptions, toSubscription(sub))
+	}
+	return resp, nil
+}
+
+func (h *WatchlistHandler) UpdateSubscription(ctx context.Context, req *desc.UpdateSubscriptionRequest) (*desc.Subscription, error) {
+	userID, err := caller(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sub, err := h.service.UpdateSubscription(ctx, userID, req.GetId(), req.GetTargetPrice())
+	if err != nil {
+		return nil, toStatus(err, "subscription")
+	}
+	return toSubscription(sub), nil
+}
+
+func (h *WatchlistHandler) Unwatch(ctx context.Context, req *desc.UnwatchRequest) (*desc.UnwatchResponse, error) {
+	userID, err := caller(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.service.UntrackProduct(ctx, userID, req.GetId()); err != nil {
+		return nil, toStatus(err, "subscription")
+	}
+	return &desc.UnwatchResponse{}, nil
+}
+
+func caller(ctx context.Context) (int64, error) {
+	userID, ok := domain.UserIDFromContext(ctx)
+	if !ok {
+		return 0, status.Error(codes.Unauthenticated, "user required")
+	}
+	return userID, nil
+}
+
+func toSubscription(sub *domain.Subscription) *desc.Subscription {
+	out := &desc.Subscription{
+		Id:          sub.ID,
+		ProductId:   sub.ProductID,
+		TargetPrice: sub.TargetPrice,
+		CreatedAt:   timestamppb.New(sub.CreatedAt),
+	}
+	if p := sub.Product; p != nil {
+		out.Url = p.URL
+		out.Title = p.Title
+		out.CurrentPrice = p.CurrentPrice
+	}
+	return out
+}
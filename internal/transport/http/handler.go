@@ -6,8 +6,10 @@ import (
 	"strconv"
 
 	_ "github.com/derkres11/price-pulse/docs"
+	"github.com/derkres11/price-pulse/internal/auth"
 	"github.com/derkres11/price-pulse/internal/domain"
 	"github.com/derkres11/price-pulse/internal/service"
+	"github.com/derkres11/price-pulse/internal/stream"
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 
@@ -16,14 +18,45 @@ import (
 )
 
 type Handler struct {
-	services *service.ProductService
-	logger   *slog.Logger
+	services      *service.ProductService
+	users         *service.UserService
+	apiKeys       *service.APIKeyService
+	notifications *service.NotificationService
+	schedule      *service.ScheduleService
+	auth          *auth.Authenticator
+	hub           *stream.Hub
+	rules         domain.ExtractionRules
+	dlq           domain.DeadLetterQueue
+	circuits      domain.HostCircuits
+	origins       []string
+	logger        *slog.Logger
 }
 
-func NewHandler(services *service.ProductService, logger *slog.Logger) *Handler {
+func NewHandler(
+	services *service.ProductService,
+	users *service.UserService,
+	apiKeys *service.APIKeyService,
+	notifications *service.NotificationService,
+	schedule *service.ScheduleService,
+	authenticator *auth.Authenticator,
+	hub *stream.Hub,
+	rules domain.ExtractionRules,
+	dlq domain.DeadLetterQueue,
+	circuits domain.HostCircuits,
+	logger *slog.Logger,
+) *Handler {
 	return &Handler{
-		services: services,
-		logger:   logger,
+		services:      services,
+		users:         users,
+		apiKeys:       apiKeys,
+		notifications: notifications,
+		schedule:      schedule,
+		auth:          authenticator,
+		hub:           hub,
+		rules:         rules,
+		dlq:           dlq,
+		circuits:      circuits,
+		logger:        logger,
 	}
 }
 
@@ -32,44 +65,131 @@ func NewHandler(services *service.ProductService, logger *slog.Logger) *Handler
 // @description API Server for Price Monitoring Service
 // @host localhost:8080
 // @BasePath /
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
 
-func (h *Handler) InitRoutes() *gin.Engine {
-	router := gin.Default()
+// InitRoutes builds the public API. Everything except registration needs an API key or a JWT.
+// Browsers on allowedOrigins may open WebSocket streams next to the API's own origin.
+func (h *Handler) InitRoutes(allowedOrigins []string) *gin.Engine {
+	h.origins = allowedOrigins
 
-	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	router := gin.New()
+	router.Use(accessLog, gin.Recovery(), requestContext)
 
-	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	router.POST("/users", h.RegisterUser)
+
+	api := router.Group("/", h.authenticate)
 
-	products := router.Group("/products")
+	api.GET("/me", h.GetMe)
+	api.PUT("/me/quiet-hours", h.SetQuietHours)
+
+	// Browsers cannot send headers with EventSource and WebSocket
+	streams := router.Group("/", queryToken, h.authenticate)
+	{
+		streams.GET("/products/:id/stream", h.StreamProduct)
+		streams.GET("/stream", h.StreamProduct)
+		streams.GET("/ws", h.StreamPrices)
+	}
+
+	keys := api.Group("/api-keys")
+	{
+		keys.POST("/", h.CreateAPIKey)
+		keys.GET("/", h.ListAPIKeys)
+		keys.DELETE("/:id", h.RevokeAPIKey)
+	}
+
+	watchlist := api.Group("/watchlist")
+	{
+		watchlist.POST("/", h.TrackProduct)
+		watchlist.GET("/", h.ListWatchlist)
+		watchlist.PATCH("/:id", h.UpdateSubscription)
+		watchlist.DELETE("/:id", h.UntrackProduct)
+		watchlist.PUT("/:id/alert-policy", h.SetAlertPolicy)
+		watchlist.POST("/:id/alert-rules", h.CreateSubscriptionRule)
+		watchlist.GET("/:id/alert-rules", h.ListSubscriptionRules)
+		watchlist.DELETE("/:id/alert-rules/:rule_id", h.DeleteSubscriptionRule)
+	}
+
+	products := api.Group("/products")
 	{
 		products.POST("/", h.CreateProduct)
+		products.GET("/", h.ListProducts)
 		products.GET("/:id", h.GetProduct)
+		products.PATCH("/:id", requireAdmin, h.UpdateProduct)
+		products.DELETE("/:id", requireAdmin, h.DeleteProduct)
+		products.POST("/:id/check", requireAdmin, h.CheckProduct)
+		products.GET("/:id/schedule", requireAdmin, h.ExplainInterval)
+		products.GET("/:id/history", h.GetPriceHistory)
+		products.POST("/:id/alert-rules", requireAdmin, h.CreateAlertRule)
+		products.GET("/:id/alert-rules", h.ListAlertRules)
+		products.DELETE("/:id/alert-rules/:rule_id", requireAdmin, h.DeleteAlertRule)
+		products.GET("/:id/alerts", h.ListAlertEvents)
+	}
+
+	channels := api.Group("/channels")
+	{
+		channels.POST("/", h.CreateChannel)
+		channels.GET("/", h.ListChannels)
+		channels.DELETE("/:id", h.DeleteChannel)
+	}
+
+	admin := api.Group("/admin", requireAdmin)
+	{
+		admin.POST("/rules/reload", h.ReloadRules)
+		admin.GET("/dlq", h.ListDeadLetters)
+		admin.POST("/dlq/:partition/:offset/replay", h.ReplayDeadLetter)
+		admin.GET("/check-intervals", h.ListHostIntervals)
+		admin.PUT("/check-intervals/:host", h.SetHostInterval)
+		admin.DELETE("/check-intervals/:host", h.DeleteHostInterval)
+		admin.GET("/circuits", h.ListCircuits)
+
+		operator := admin.Group("/channels", operatorChannels)
+		operator.POST("/", h.CreateChannel)
+		operator.GET("/", h.ListChannels)
+		operator.DELETE("/:id", h.DeleteChannel)
 	}
 
 	return router
 }
 
+// InitAdminRoutes builds the admin listener with metrics and API docs. It is meant to stay
+// on an internal address; with credentials set it also requires HTTP basic auth.
+func (h *Handler) InitAdminRoutes(user, password string) *gin.Engine {
+	router := gin.New()
+	router.Use(gin.Recovery())
+
+	if password != "" {
+		router.Use(gin.BasicAuth(gin.Accounts{user: password}))
+	}
+
+	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+
+	return router
+}
+
 // CreateProduct godoc
 // @Summary Create a new product
 // @Tags products
 // @Accept json
 // @Produce json
+// @Security BearerAuth
 // @Param input body domain.Product true "Product info"
 // @Success 201 {object} domain.Product
-// @Failure 400 {object} map[string]string
+// @Failure 400 {object} Problem
 // @Router /products [post]
 
 func (h *Handler) CreateProduct(c *gin.Context) {
 	var input domain.Product
 	if err := c.ShouldBindJSON(&input); err != nil {
 		h.logger.Error("invalid input", slog.String("error", err.Error()))
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		problem(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
 	if err := h.services.Create(c.Request.Context(), &input); err != nil {
-		h.logger.Error("failed to create product", slog.String("error", err.Error()))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.fail(c, err, "product")
 		return
 	}
 
@@ -81,25 +201,46 @@ func (h *Handler) CreateProduct(c *gin.Context) {
 // @Summary Get product by ID
 // @Tags products
 // @Produce json
+// @Security BearerAuth
 // @Param id path int true "Product ID"
 // @Success 200 {object} domain.Product
-// @Failure 404 {object} map[string]string
+// @Failure 404 {object} Problem
 // @Router /products/{id} [get]
 
 func (h *Handler) GetProduct(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		problem(c, http.StatusBadRequest, "invalid id")
 		return
 	}
 
 	product, err := h.services.GetByID(c.Request.Context(), id)
 	if err != nil {
-		h.logger.Error("failed to get product", slog.Int64("id", id), slog.String("error", err.Error()))
-		c.JSON(http.StatusNotFound, gin.H{"error": "product not found"})
+		h.fail(c, err, "product")
 		return
 	}
 
 	c.JSON(http.StatusOK, product)
 }
+
+// ReloadRules godoc
+// @Summary Reload per-host extraction rules from disk
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Failure 422 {object} Problem
+// @Router /admin/rules/reload [post]
+
+func (h *Handler) ReloadRules(c *gin.Context) {
+	if err := h.rules.Reload(); err != nil {
+		h.logger.Error("failed to reload extraction rules", slog.String("error", err.Error()))
+		problem(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	hosts := h.rules.Hosts()
+	h.logger.Info("extraction rules reloaded", slog.Int("count", len(hosts)))
+	c.JSON(http.StatusOK, gin.H{"hosts": hosts, "count": len(hosts)})
+}
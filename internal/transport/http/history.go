@@ -0,0 +1,84 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const defaultHistoryWindow = 30 * 24 * time.Hour
+
+// GetPriceHistory godoc
+// @Summary Get price history of a product
+// @Description Raw observations, or min/max/avg/last buckets when step is set
+// @Tags products
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param from query string false "Range start, RFC3339 (default: to - 30d)"
+// @Param to query string false "Range end, RFC3339 (default: now)"
+// @Param step query string false "Bucket size, e.g. 1h, 6h, 1d"
+// @Success 200 {object} domain.PriceHistory
+// @Failure 400 {object} Problem
+// @Router /products/{id}/history [get]
+
+func (h *Handler) GetPriceHistory(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		problem(c, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	to := time.Now().UTC()
+	if v := c.Query("to"); v != "" {
+		if to, err = time.Parse(time.RFC3339, v); err != nil {
+			problem(c, http.StatusBadRequest, "invalid to, expected RFC3339")
+			return
+		}
+	}
+
+	from := to.Add(-defaultHistoryWindow)
+	if v := c.Query("from"); v != "" {
+		if from, err = time.Parse(time.RFC3339, v); err != nil {
+			problem(c, http.StatusBadRequest, "invalid from, expected RFC3339")
+			return
+		}
+	}
+
+	var step time.Duration
+	if v := c.Query("step"); v != "" {
+		if step, err = parseStep(v); err != nil {
+			problem(c, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	history, err := h.services.GetPriceHistory(c.Request.Context(), id, from, to, step)
+	if err != nil {
+		h.fail(c, err, "price history")
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// parseStep accepts Go durations ("90m", "6h") and whole days ("1d", "7d")
+func parseStep(v string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(v, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid step %q", v)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	step, err := time.ParseDuration(v)
+	if err != nil || step < time.Minute {
+		return 0, fmt.Errorf("invalid step %q, minimum is 1m", v)
+	}
+	return step, nil
+}
@@ -0,0 +1,93 @@
+package http
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/derkres11/price-pulse/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+const problemContentType = "application/problem+json"
+
+// Problem is the RFC 7807 body of every error response. Code is the same reason
+// the gRPC API puts into its ErrorInfo details.
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	Code     string `json:"code,omitempty"`
+}
+
+// problem aborts the request with a problem+json body
+func problem(c *gin.Context, status int, detail string) {
+	writeProblem(c, status, codeForStatus(status), detail)
+}
+
+func writeProblem(c *gin.Context, status int, code, detail string) {
+	c.Header("Content-Type", problemContentType)
+	c.AbortWithStatusJSON(status, Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   detail,
+		Instance: c.Request.URL.Path,
+		Code:     code,
+	})
+}
+
+// fail answers with the status of the error's kind. resource names what was asked for,
+// e.g. "product", and words the detail of not found and conflict answers. Invalid and
+// forbidden requests get the error's public message, errors of no known kind are
+// logged and hidden from the client.
+func (h *Handler) fail(c *gin.Context, err error, resource string) {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		writeProblem(c, http.StatusNotFound, "NOT_FOUND", resource+" not found")
+	case errors.Is(err, domain.ErrAlreadyExists):
+		writeProblem(c, http.StatusConflict, "ALREADY_EXISTS", resource+" already exists")
+	case errors.Is(err, domain.ErrInvalidArgument):
+		writeProblem(c, http.StatusBadRequest, "INVALID_ARGUMENT", h.public(c, err, resource))
+	case errors.Is(err, domain.ErrPermissionDenied):
+		writeProblem(c, http.StatusForbidden, "PERMISSION_DENIED", h.public(c, err, resource))
+	case errors.Is(err, domain.ErrUnavailable):
+		h.logger.Warn(resource+" request unavailable", slog.String("path", c.FullPath()), slog.String("error", err.Error()))
+		writeProblem(c, http.StatusServiceUnavailable, "UNAVAILABLE", "temporarily unavailable, retry later")
+	default:
+		h.logger.Error(resource+" request failed", slog.String("path", c.FullPath()), slog.String("error", err.Error()))
+		writeProblem(c, http.StatusInternalServerError, "INTERNAL", resource+" request failed")
+	}
+}
+
+// public returns the message the client may see and logs the cause if that hides it
+func (h *Handler) public(c *gin.Context, err error, resource string) string {
+	msg := domain.PublicMessage(err)
+	if msg != err.Error() {
+		h.logger.Warn(resource+" request rejected", slog.String("path", c.FullPath()), slog.String("error", err.Error()))
+	}
+	return msg
+}
+
+func codeForStatus(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return "INVALID_ARGUMENT"
+	case http.StatusUnauthorized:
+		return "UNAUTHENTICATED"
+	case http.StatusForbidden:
+		return "PERMISSION_DENIED"
+	case http.StatusNotFound:
+		return "NOT_FOUND"
+	case http.StatusConflict:
+		return "ALREADY_EXISTS"
+	case http.StatusServiceUnavailable:
+		return "UNAVAILABLE"
+	case http.StatusUnprocessableEntity:
+		return "FAILED_PRECONDITION"
+	default:
+		return "INTERNAL"
+	}
+}
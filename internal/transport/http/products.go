@@ -0,0 +1,143 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/derkres11/price-pulse/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// ListProducts godoc
+// @Summary List products
+// @Description Keyset paginated, pass next_cursor back as cursor with the same sort to get the next page
+// @Tags products
+// @Security BearerAuth
+// @Produce json
+// @Param host query string false "Shop host, www. is optional"
+// @Param min_price query number false "Lowest current price"
+// @Param max_price query number false "Highest current price"
+// @Param below_target query bool false "Only products at or below their target"
+// @Param updated_since query string false "Updated at or after, RFC3339"
+// @Param q query string false "Words in the title"
+// @Param sort query string false "id, created_at, updated_at, price or title, prefix with - for descending"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} domain.ProductPage
+// @Failure 400 {object} Problem
+// @Router /products [get]
+
+func (h *Handler) ListProducts(c *gin.Context) {
+	var (
+		q   domain.ProductQuery
+		err error
+	)
+
+	q.Host = strings.ToLower(strings.TrimSpace(c.Query("host")))
+	q.Search = strings.TrimSpace(c.Query("q"))
+
+	if v := c.Query("min_price"); v != "" {
+		if q.MinPrice, err = strconv.ParseFloat(v, 64); err != nil {
+			problem(c, http.StatusBadRequest, "invalid min_price")
+			return
+		}
+	}
+	if v := c.Query("max_price"); v != "" {
+		if q.MaxPrice, err = strconv.ParseFloat(v, 64); err != nil {
+			problem(c, http.StatusBadRequest, "invalid max_price")
+			return
+		}
+	}
+	if v := c.Query("below_target"); v != "" {
+		if q.BelowTarget, err = strconv.ParseBool(v); err != nil {
+			problem(c, http.StatusBadRequest, "invalid below_target")
+			return
+		}
+	}
+	if v := c.Query("updated_since"); v != "" {
+		if q.UpdatedSince, err = time.Parse(time.RFC3339, v); err != nil {
+			problem(c, http.StatusBadRequest, "invalid updated_since, expected RFC3339")
+			return
+		}
+	}
+	if v := c.Query("limit"); v != "" {
+		if q.Limit, err = strconv.Atoi(v); err != nil || q.Limit <= 0 {
+			problem(c, http.StatusBadRequest, "invalid limit")
+			return
+		}
+	}
+
+	sort, desc := strings.CutPrefix(c.Query("sort"), "-")
+	q.Sort, q.Desc = domain.ProductSort(sort), desc
+
+	page, err := h.services.ListProducts(c.Request.Context(), q, c.Query("cursor"))
+	if err != nil {
+		h.fail(c, err, "product")
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// UpdateProduct godoc
+// @Summary Change the title or target price of a product
+// @Description Admin only, products are shared between all watchers
+// @Tags products
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param input body domain.ProductPatch true "Fields to change"
+// @Success 200 {object} domain.Product
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Router /products/{id} [patch]
+
+func (h *Handler) UpdateProduct(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		problem(c, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	var patch domain.ProductPatch
+	if err := c.ShouldBindJSON(&patch); err != nil {
+		problem(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	product, err := h.services.UpdateProduct(c.Request.Context(), id, patch)
+	if err != nil {
+		h.fail(c, err, "product")
+		return
+	}
+
+	c.JSON(http.StatusOK, product)
+}
+
+// DeleteProduct godoc
+// @Summary Delete a product with its history, rules and watchlist entries
+// @Description Admin only
+// @Tags products
+// @Security BearerAuth
+// @Param id path int true "Product ID"
+// @Success 204
+// @Failure 404 {object} Problem
+// @Router /products/{id} [delete]
+
+func (h *Handler) DeleteProduct(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		problem(c, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	if err := h.services.DeleteProduct(c.Request.Context(), id); err != nil {
+		h.fail(c, err, "product")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,33 @@
+package http
+
+import (
+	"github.com/derkres11/price-pulse/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	requestIDHeader   = "X-Request-ID"
+	traceparentHeader = "traceparent"
+)
+
+// requestContext gives every request an ID and a trace context, taken from the
+// X-Request-ID and traceparent headers when the caller sent them. Both follow the
+// request into the Kafka messages it causes. The ID is echoed in the response.
+func requestContext(c *gin.Context) {
+	id := c.GetHeader(requestIDHeader)
+	if !domain.ValidRequestID(id) {
+		id = domain.NewRequestID()
+	}
+
+	trace, err := domain.ParseTraceparent(c.GetHeader(traceparentHeader))
+	if err != nil {
+		trace = domain.NewTraceContext()
+	} else {
+		trace = trace.Child()
+	}
+
+	ctx := domain.WithTrace(domain.WithRequestID(c.Request.Context(), id), trace)
+	c.Request = c.Request.WithContext(ctx)
+	c.Header(requestIDHeader, id)
+	c.Next()
+}
@@ -0,0 +1,133 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/derkres11/price-pulse/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// CheckProduct godoc
+// @Summary Check a product's price now
+// @Description Admin only. Queues a check right away, the regular schedule is kept.
+// @Tags products
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Product ID"
+// @Success 202 {object} map[string]interface{}
+// @Failure 404 {object} Problem
+// @Router /products/{id}/check [post]
+
+func (h *Handler) CheckProduct(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		problem(c, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	if err := h.schedule.CheckNow(c.Request.Context(), id); err != nil {
+		h.fail(c, err, "product")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"product_id": id, "status": "queued"})
+}
+
+// ExplainInterval godoc
+// @Summary Explain a product's check interval
+// @Description Admin only. Shows the base interval, where it comes from and, when intervals adapt, the signals and factors that changed it.
+// @Tags products
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Product ID"
+// @Success 200 {object} domain.CheckPlan
+// @Failure 404 {object} Problem
+// @Router /products/{id}/schedule [get]
+
+func (h *Handler) ExplainInterval(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		problem(c, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	plan, err := h.schedule.ExplainInterval(c.Request.Context(), id)
+	if err != nil {
+		h.fail(c, err, "product")
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
+
+// ListHostIntervals godoc
+// @Summary List the check intervals set per shop
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} domain.HostInterval
+// @Router /admin/check-intervals [get]
+
+func (h *Handler) ListHostIntervals(c *gin.Context) {
+	intervals, err := h.schedule.ListHostIntervals(c.Request.Context())
+	if err != nil {
+		h.fail(c, err, "check interval")
+		return
+	}
+
+	c.JSON(http.StatusOK, intervals)
+}
+
+// hostIntervalInput takes a duration string, e.g. {"interval": "2h"}
+type hostIntervalInput struct {
+	Interval domain.Interval `json:"interval" binding:"required"`
+}
+
+// SetHostInterval godoc
+// @Summary Set how often the products of a shop are checked
+// @Description Products with an interval of their own keep it. "www." is ignored.
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param host path string true "Host, e.g. shop.example.com"
+// @Param input body hostIntervalInput true "Interval"
+// @Success 200 {object} domain.HostInterval
+// @Failure 400 {object} Problem
+// @Router /admin/check-intervals/{host} [put]
+
+func (h *Handler) SetHostInterval(c *gin.Context) {
+	var input hostIntervalInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	interval, err := h.schedule.SetHostInterval(c.Request.Context(), c.Param("host"), time.Duration(input.Interval))
+	if err != nil {
+		h.fail(c, err, "check interval")
+		return
+	}
+
+	c.JSON(http.StatusOK, interval)
+}
+
+// DeleteHostInterval godoc
+// @Summary Go back to the default interval for a shop
+// @Tags admin
+// @Security BearerAuth
+// @Param host path string true "Host"
+// @Success 204
+// @Failure 404 {object} Problem
+// @Router /admin/check-intervals/{host} [delete]
+
+func (h *Handler) DeleteHostInterval(c *gin.Context) {
+	if err := h.schedule.DeleteHostInterval(c.Request.Context(), c.Param("host")); err != nil {
+		h.fail(c, err, "check interval")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,302 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/derkres11/price-pulse/internal/domain"
+	"github.com/derkres11/price-pulse/internal/stream"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	heartbeatInterval = 15 * time.Second
+	wsWriteTimeout    = 10 * time.Second
+)
+
+// accessTokenParam carries the credential of clients that cannot set headers
+const accessTokenParam = "access_token"
+
+// queryToken lets browser clients, which cannot set headers on EventSource or WebSocket,
+// pass their credential as ?access_token=. It runs before authenticate and takes the
+// token out of the URL, accessLog leaves it out as well.
+func queryToken(c *gin.Context) {
+	query := c.Request.URL.Query()
+	if token := query.Get(accessTokenParam); token != "" && c.GetHeader("Authorization") == "" && c.GetHeader(apiKeyHeader) == "" {
+		c.Request.Header.Set("Authorization", "Bearer "+token)
+	}
+	if query.Has(accessTokenParam) {
+		query.Del(accessTokenParam)
+		c.Request.URL.RawQuery = query.Encode()
+	}
+	c.Next()
+}
+
+// accessLog is gin's request log with the access token redacted from the query
+var accessLog = gin.LoggerWithFormatter(func(p gin.LogFormatterParams) string {
+	return fmt.Sprintf("[GIN] %v | %3d | %13v | %15s | %-7s %#v\n%s",
+		p.TimeStamp.Format("2006/01/02 - 15:04:05"),
+		p.StatusCode, p.Latency, p.ClientIP, p.Method, redactQuery(p.Path), p.ErrorMessage)
+})
+
+// redactQuery hides the access token of a logged path, a query that does not parse is dropped
+func redactQuery(path string) string {
+	path, rawQuery, ok := strings.Cut(path, "?")
+	if !ok {
+		return path
+	}
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return path
+	}
+	if query.Has(accessTokenParam) {
+		query.Set(accessTokenParam, "REDACTED")
+	}
+	return path + "?" + query.Encode()
+}
+
+// checkOrigin admits browsers on the API's own origin and on the allowed ones. Streams
+// authenticate with a token, not cookies, but a page on any origin could still use a
+// token it got hold of. Clients that send no Origin are not browsers and pass.
+func (h *Handler) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	if strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	for _, allowed := range h.origins {
+		if allowed == "*" || strings.EqualFold(strings.TrimSuffix(allowed, "/"), origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// StreamProduct godoc
+// @Summary Stream live prices and alerts of a product
+// @Description Server-Sent Events, "price" and "alert" events carry a domain.PriceEvent. A comment line is sent as heartbeat.
+// @Description Reconnect with Last-Event-ID to replay what was missed, as far as the replica still buffers it.
+// @Tags products
+// @Security BearerAuth
+// @Produce text/event-stream
+// @Description The product of the path, if any, is streamed along with product_ids and the watchlist.
+// @Param id path int false "Product ID"
+// @Param product_ids query string false "Comma separated product IDs"
+// @Param watchlist query bool false "Add the caller's watchlist"
+// @Param Last-Event-ID header int false "ID of the last event received"
+// @Param access_token query string false "Credential for clients that cannot set headers"
+// @Success 200 {object} domain.PriceEvent
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Router /products/{id}/stream [get]
+// @Router /stream [get]
+
+func (h *Handler) StreamProduct(c *gin.Context) {
+	sel, ok := streamSelection(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	if raw := c.Param("id"); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			problem(c, http.StatusBadRequest, "invalid id")
+			return
+		}
+		if _, err := h.services.GetByID(ctx, id); err != nil {
+			h.fail(c, err, "product")
+			return
+		}
+		sel.ProductIDs = append(sel.ProductIDs, id)
+	}
+
+	lastID := c.GetHeader("Last-Event-ID")
+	var after int64
+	if lastID != "" {
+		var err error
+		if after, err = strconv.ParseInt(lastID, 10, 64); err != nil {
+			problem(c, http.StatusBadRequest, "invalid Last-Event-ID")
+			return
+		}
+	}
+
+	filter, err := sel.Filter(ctx, h.services)
+	if err != nil {
+		h.fail(c, err, "stream")
+		return
+	}
+
+	var (
+		sub    *stream.Subscription
+		missed []*domain.PriceEvent
+	)
+	if lastID != "" {
+		sub, missed = h.hub.Resume(after, filter)
+	} else {
+		sub = h.hub.Subscribe(filter)
+	}
+	defer h.hub.Unsubscribe(sub)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // keep nginx from holding events back
+	c.Status(http.StatusOK)
+
+	for _, e := range missed {
+		if err := writeSSE(c.Writer, e); err != nil {
+			return
+		}
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-heartbeat.C:
+			if _, err := io.WriteString(c.Writer, ": ping\n\n"); err != nil {
+				return
+			}
+		case e, ok := <-sub.Events():
+			if !ok {
+				if errors.Is(sub.Err(), stream.ErrSlowSubscriber) {
+					fmt.Fprint(c.Writer, "event: error\ndata: {\"error\":\"client too slow, reconnect\"}\n\n")
+					c.Writer.Flush()
+				}
+				return
+			}
+			if err := writeSSE(c.Writer, e); err != nil {
+				return
+			}
+		}
+		c.Writer.Flush()
+	}
+}
+
+// StreamPrices godoc
+// @Summary Stream live prices and alerts over a WebSocket
+// @Description Every text message is a domain.PriceEvent. The server pings every 15s and closes connections that stop answering.
+// @Tags products
+// @Security BearerAuth
+// @Param product_ids query string false "Comma separated product IDs"
+// @Param watchlist query bool false "Add the caller's watchlist"
+// @Param access_token query string false "Credential for clients that cannot set headers"
+// @Success 101
+// @Failure 400 {object} Problem
+// @Router /ws [get]
+
+func (h *Handler) StreamPrices(c *gin.Context) {
+	sel, ok := streamSelection(c)
+	if !ok {
+		return
+	}
+
+	filter, err := sel.Filter(c.Request.Context(), h.services)
+	if err != nil {
+		h.fail(c, err, "stream")
+		return
+	}
+
+	upgrader := websocket.Upgrader{CheckOrigin: h.checkOrigin}
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return // the upgrader already answered
+	}
+	defer conn.Close()
+
+	sub := h.hub.Subscribe(filter)
+	defer h.hub.Unsubscribe(sub)
+
+	// The client never sends anything we use, reading only handles pongs and the close
+	closed := make(chan struct{})
+	conn.SetReadDeadline(time.Now().Add(2 * heartbeatInterval))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(2 * heartbeatInterval))
+	})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case <-heartbeat.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteTimeout)); err != nil {
+				return
+			}
+		case e, ok := <-sub.Events():
+			if !ok {
+				if errors.Is(sub.Err(), stream.ErrSlowSubscriber) {
+					msg := websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "client too slow")
+					conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(wsWriteTimeout))
+				}
+				return
+			}
+			conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+			if err := conn.WriteJSON(e); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// streamSelection reads the product_ids and watchlist a stream was asked for, it answers
+// the request when they do not parse
+func streamSelection(c *gin.Context) (stream.Selection, bool) {
+	sel := stream.Selection{UserID: currentUser(c)}
+	if v := c.Query("product_ids"); v != "" {
+		for _, raw := range strings.Split(v, ",") {
+			id, err := strconv.ParseInt(strings.TrimSpace(raw), 10, 64)
+			if err != nil {
+				problem(c, http.StatusBadRequest, "invalid product_ids")
+				return sel, false
+			}
+			sel.ProductIDs = append(sel.ProductIDs, id)
+		}
+	}
+	if v := c.Query("watchlist"); v != "" {
+		var err error
+		if sel.Watchlist, err = strconv.ParseBool(v); err != nil {
+			problem(c, http.StatusBadRequest, "invalid watchlist")
+			return sel, false
+		}
+	}
+	return sel, true
+}
+
+func writeSSE(w io.Writer, e *domain.PriceEvent) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+	return err
+}
@@ -0,0 +1,100 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/derkres11/price-pulse/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+type userInput struct {
+	Email string `json:"email" binding:"required"`
+	Name  string `json:"name"`
+}
+
+type quietHoursInput struct {
+	QuietStart string `json:"quiet_start"`
+	QuietEnd   string `json:"quiet_end"`
+	Timezone   string `json:"timezone"`
+}
+
+type registerResponse struct {
+	User   *domain.User `json:"user"`
+	APIKey string       `json:"api_key"` // shown only once
+}
+
+// RegisterUser godoc
+// @Summary Register a user
+// @Description Returns the user and a first API key. The key is not shown again.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param input body userInput true "User"
+// @Success 201 {object} registerResponse
+// @Failure 400 {object} Problem
+// @Router /users [post]
+
+func (h *Handler) RegisterUser(c *gin.Context) {
+	var input userInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	u := &domain.User{Email: input.Email, Name: input.Name}
+	key, err := h.users.Register(c.Request.Context(), u)
+	if err != nil {
+		h.fail(c, err, "user")
+		return
+	}
+
+	c.JSON(http.StatusCreated, registerResponse{User: u, APIKey: key})
+}
+
+// GetMe godoc
+// @Summary Get the calling user
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} domain.User
+// @Failure 404 {object} Problem
+// @Router /me [get]
+
+func (h *Handler) GetMe(c *gin.Context) {
+	u, err := h.users.GetByID(c.Request.Context(), currentUser(c))
+	if err != nil {
+		h.fail(c, err, "user")
+		return
+	}
+
+	c.JSON(http.StatusOK, u)
+}
+
+// SetQuietHours godoc
+// @Summary Set the caller's quiet hours
+// @Description Alerts between quiet_start and quiet_end ("22:00", "07:30") in timezone (default UTC) are queued and sent as one digest afterwards. Empty times switch quiet hours off.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body quietHoursInput true "Quiet hours"
+// @Success 200 {object} domain.User
+// @Failure 400 {object} Problem
+// @Router /me/quiet-hours [put]
+
+func (h *Handler) SetQuietHours(c *gin.Context) {
+	var input quietHoursInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	q := domain.QuietHours{QuietStart: input.QuietStart, QuietEnd: input.QuietEnd, Timezone: input.Timezone}
+	u, err := h.users.SetQuietHours(c.Request.Context(), currentUser(c), q)
+	if err != nil {
+		h.fail(c, err, "user")
+		return
+	}
+
+	c.JSON(http.StatusOK, u)
+}
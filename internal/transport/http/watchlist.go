@@ -0,0 +1,258 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/derkres11/price-pulse/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type trackInput struct {
+	URL         string  `json:"url" binding:"required"`
+	TargetPrice float64 `json:"target_price"`
+}
+
+type subscriptionInput struct {
+	TargetPrice float64 `json:"target_price"`
+}
+
+type alertPolicyInput struct {
+	CooldownMinutes *int  `json:"cooldown_minutes"`
+	Rearm           *bool `json:"rearm"`
+}
+
+// TrackProduct godoc
+// @Summary Add a product URL to the watchlist
+// @Description The product is shared between users, it is created on first track. Tracking it again updates the target.
+// @Tags watchlist
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param input body trackInput true "Product URL and target"
+// @Success 201 {object} domain.Subscription
+// @Failure 400 {object} Problem
+// @Router /watchlist [post]
+
+func (h *Handler) TrackProduct(c *gin.Context) {
+	var input trackInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	sub, err := h.services.TrackProduct(c.Request.Context(), currentUser(c), input.URL, input.TargetPrice)
+	if err != nil {
+		h.fail(c, err, "subscription")
+		return
+	}
+
+	c.JSON(http.StatusCreated, sub)
+}
+
+// ListWatchlist godoc
+// @Summary List the caller's watchlist
+// @Tags watchlist
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} domain.Subscription
+// @Router /watchlist [get]
+
+func (h *Handler) ListWatchlist(c *gin.Context) {
+	subs, err := h.services.ListWatchlist(c.Request.Context(), currentUser(c))
+	if err != nil {
+		h.fail(c, err, "subscription")
+		return
+	}
+
+	c.JSON(http.StatusOK, subs)
+}
+
+// UpdateSubscription godoc
+// @Summary Change the target price of a watchlist entry
+// @Tags watchlist
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Param input body subscriptionInput true "Target"
+// @Success 200 {object} domain.Subscription
+// @Failure 404 {object} Problem
+// @Router /watchlist/{id} [patch]
+
+func (h *Handler) UpdateSubscription(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		problem(c, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	var input subscriptionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	sub, err := h.services.UpdateSubscription(c.Request.Context(), currentUser(c), id, input.TargetPrice)
+	if err != nil {
+		h.fail(c, err, "subscription")
+		return
+	}
+
+	c.JSON(http.StatusOK, sub)
+}
+
+// SetAlertPolicy godoc
+// @Summary Limit repeated alerts of a watchlist entry
+// @Description No alert repeats within cooldown_minutes (default 360). With rearm (default true) a rule only alerts again once its condition has cleared.
+// @Tags watchlist
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Param input body alertPolicyInput true "Policy"
+// @Success 200 {object} domain.Subscription
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Router /watchlist/{id}/alert-policy [put]
+
+func (h *Handler) SetAlertPolicy(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		problem(c, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	var input alertPolicyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	cooldown := service.DefaultCooldownMinutes
+	if input.CooldownMinutes != nil {
+		cooldown = *input.CooldownMinutes
+	}
+	rearm := input.Rearm == nil || *input.Rearm
+
+	sub, err := h.services.SetAlertPolicy(c.Request.Context(), currentUser(c), id, cooldown, rearm)
+	if err != nil {
+		h.fail(c, err, "subscription")
+		return
+	}
+
+	c.JSON(http.StatusOK, sub)
+}
+
+// UntrackProduct godoc
+// @Summary Remove a watchlist entry
+// @Tags watchlist
+// @Security BearerAuth
+// @Param id path int true "Subscription ID"
+// @Success 204
+// @Failure 404 {object} Problem
+// @Router /watchlist/{id} [delete]
+
+func (h *Handler) UntrackProduct(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		problem(c, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	if err := h.services.UntrackProduct(c.Request.Context(), currentUser(c), id); err != nil {
+		h.fail(c, err, "subscription")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// CreateSubscriptionRule godoc
+// @Summary Add an alert rule to a watchlist entry
+// @Tags watchlist
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Param input body alertRuleInput true "Rule"
+// @Success 201 {object} domain.AlertRule
+// @Failure 400 {object} Problem
+// @Router /watchlist/{id}/alert-rules [post]
+
+func (h *Handler) CreateSubscriptionRule(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		problem(c, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	var input alertRuleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	rule := input.rule()
+	if err := h.services.CreateSubscriptionRule(c.Request.Context(), currentUser(c), id, rule); err != nil {
+		h.fail(c, err, "subscription")
+		return
+	}
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+// ListSubscriptionRules godoc
+// @Summary List alert rules of a watchlist entry
+// @Tags watchlist
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Success 200 {array} domain.AlertRule
+// @Router /watchlist/{id}/alert-rules [get]
+
+func (h *Handler) ListSubscriptionRules(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		problem(c, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	rules, err := h.services.ListSubscriptionRules(c.Request.Context(), currentUser(c), id)
+	if err != nil {
+		h.fail(c, err, "subscription")
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+// DeleteSubscriptionRule godoc
+// @Summary Remove an alert rule from a watchlist entry
+// @Tags watchlist
+// @Security BearerAuth
+// @Param id path int true "Subscription ID"
+// @Param rule_id path int true "Rule ID"
+// @Success 204
+// @Failure 404 {object} Problem
+// @Router /watchlist/{id}/alert-rules/{rule_id} [delete]
+
+func (h *Handler) DeleteSubscriptionRule(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		problem(c, http.StatusBadRequest, "invalid id")
+		return
+	}
+	ruleID, err := strconv.ParseInt(c.Param("rule_id"), 10, 64)
+	if err != nil {
+		problem(c, http.StatusBadRequest, "invalid rule id")
+		return
+	}
+
+	if err := h.services.DeleteSubscriptionRule(c.Request.Context(), currentUser(c), id, ruleID); err != nil {
+		h.fail(c, err, "subscription")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_price_history_product_observed;
+DROP TABLE IF EXISTS price_history;
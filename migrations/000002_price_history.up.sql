@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS price_history (
+    id BIGSERIAL PRIMARY KEY,
+    product_id BIGINT NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    price DECIMAL(12, 2) NOT NULL,
+    currency VARCHAR(3) NOT NULL DEFAULT '',
+    in_stock BOOLEAN NOT NULL DEFAULT TRUE,
+    source TEXT NOT NULL DEFAULT '',
+    observed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_price_history_product_observed ON price_history (product_id, observed_at);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_alert_events_product_created;
+DROP TABLE IF EXISTS alert_events;
+DROP INDEX IF EXISTS idx_alert_rules_product;
+DROP TABLE IF EXISTS alert_rules;
@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS alert_rules (
+    id BIGSERIAL PRIMARY KEY,
+    product_id BIGINT NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    type TEXT NOT NULL,
+    threshold DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    percent DECIMAL(5, 2) NOT NULL DEFAULT 0,
+    baseline DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    days INTEGER NOT NULL DEFAULT 0,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_alert_rules_product ON alert_rules (product_id);
+
+CREATE TABLE IF NOT EXISTS alert_events (
+    id BIGSERIAL PRIMARY KEY,
+    rule_id BIGINT REFERENCES alert_rules (id) ON DELETE SET NULL,
+    product_id BIGINT NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    type TEXT NOT NULL,
+    price DECIMAL(12, 2) NOT NULL,
+    previous_price DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    reason TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_alert_events_product_created ON alert_events (product_id, created_at DESC);
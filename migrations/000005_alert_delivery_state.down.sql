@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_digest_queue_channel;
+DROP TABLE IF EXISTS digest_queue;
+DROP INDEX IF EXISTS idx_alert_state_product;
+DROP TABLE IF EXISTS alert_state;
+
+ALTER TABLE notification_channels
+    DROP COLUMN IF EXISTS cooldown_minutes,
+    DROP COLUMN IF EXISTS rearm,
+    DROP COLUMN IF EXISTS quiet_start,
+    DROP COLUMN IF EXISTS quiet_end,
+    DROP COLUMN IF EXISTS timezone;
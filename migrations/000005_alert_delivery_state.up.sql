@@ -0,0 +1,28 @@
+ALTER TABLE notification_channels
+    ADD COLUMN IF NOT EXISTS cooldown_minutes INTEGER NOT NULL DEFAULT 360,
+    ADD COLUMN IF NOT EXISTS rearm BOOLEAN NOT NULL DEFAULT TRUE,
+    ADD COLUMN IF NOT EXISTS quiet_start TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS quiet_end TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS timezone TEXT NOT NULL DEFAULT 'UTC';
+
+-- rule_id 0 is the implicit rule built from products.target_price, so no foreign key here
+CREATE TABLE IF NOT EXISTS alert_state (
+    channel_id BIGINT NOT NULL REFERENCES notification_channels (id) ON DELETE CASCADE,
+    product_id BIGINT NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    rule_id BIGINT NOT NULL DEFAULT 0,
+    last_price DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    last_sent_at TIMESTAMP WITH TIME ZONE,
+    armed BOOLEAN NOT NULL DEFAULT TRUE,
+    PRIMARY KEY (channel_id, product_id, rule_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_alert_state_product ON alert_state (product_id) WHERE NOT armed;
+
+CREATE TABLE IF NOT EXISTS digest_queue (
+    id BIGSERIAL PRIMARY KEY,
+    channel_id BIGINT NOT NULL REFERENCES notification_channels (id) ON DELETE CASCADE,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_digest_queue_channel ON digest_queue (channel_id, id);
@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_notification_channels_user;
+ALTER TABLE notification_channels DROP COLUMN IF EXISTS user_id;
+
+ALTER TABLE alert_events DROP COLUMN IF EXISTS subscription_id;
+
+DROP INDEX IF EXISTS idx_alert_rules_subscription;
+ALTER TABLE alert_rules DROP COLUMN IF EXISTS subscription_id;
+
+DROP INDEX IF EXISTS idx_subscriptions_product;
+DROP TABLE IF EXISTS subscriptions;
+DROP TABLE IF EXISTS users;
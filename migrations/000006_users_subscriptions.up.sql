@@ -0,0 +1,34 @@
+CREATE TABLE IF NOT EXISTS users (
+    id BIGSERIAL PRIMARY KEY,
+    email TEXT NOT NULL UNIQUE,
+    name TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- A product is fetched once, every user watching it has a subscription with their own target
+CREATE TABLE IF NOT EXISTS subscriptions (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    product_id BIGINT NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    target_price DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (user_id, product_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_subscriptions_product ON subscriptions (product_id);
+
+-- Rules and events without a subscription belong to the product itself, as before
+ALTER TABLE alert_rules
+    ADD COLUMN IF NOT EXISTS subscription_id BIGINT REFERENCES subscriptions (id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_alert_rules_subscription ON alert_rules (subscription_id) WHERE subscription_id IS NOT NULL;
+
+ALTER TABLE alert_events
+    ADD COLUMN IF NOT EXISTS subscription_id BIGINT REFERENCES subscriptions (id) ON DELETE SET NULL;
+
+-- Channels without a user are operator channels and receive product level alerts only
+ALTER TABLE notification_channels
+    ADD COLUMN IF NOT EXISTS user_id BIGINT REFERENCES users (id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_notification_channels_user ON notification_channels (user_id);
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_products_title_search;
+DROP INDEX IF EXISTS idx_products_title;
+DROP INDEX IF EXISTS idx_products_price;
+DROP INDEX IF EXISTS idx_products_updated;
+DROP INDEX IF EXISTS idx_products_created;
+DROP INDEX IF EXISTS idx_products_host;
+
+ALTER TABLE products DROP COLUMN IF EXISTS host;
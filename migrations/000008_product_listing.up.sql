@@ -0,0 +1,13 @@
+-- Host is derived once so listing by shop can use an index instead of matching every url
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS host TEXT GENERATED ALWAYS AS (lower(substring(url from '^[A-Za-z]+://([^/:?#]+)'))) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_products_host ON products (host, id);
+
+-- Keyset pagination indexes, one per sort key, id breaks ties
+CREATE INDEX IF NOT EXISTS idx_products_created ON products (created_at, id);
+CREATE INDEX IF NOT EXISTS idx_products_updated ON products (updated_at, id);
+CREATE INDEX IF NOT EXISTS idx_products_price ON products ((COALESCE(current_price, 0)), id);
+CREATE INDEX IF NOT EXISTS idx_products_title ON products (title, id);
+
+CREATE INDEX IF NOT EXISTS idx_products_title_search ON products USING GIN (to_tsvector('simple', title));
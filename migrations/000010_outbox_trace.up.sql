@@ -0,0 +1,3 @@
+-- The request that queued a message, so the relay can pass it on to Kafka
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS request_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS traceparent TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS host_check_intervals;
+DROP INDEX IF EXISTS idx_products_next_check;
+ALTER TABLE products DROP COLUMN IF EXISTS next_check_at;
+ALTER TABLE products DROP COLUMN IF EXISTS check_interval_seconds;
@@ -0,0 +1,16 @@
+-- check_interval_seconds overrides the host's and the default interval, NULL keeps them.
+-- next_check_at is NULL for a new product, its first check is queued on creation.
+ALTER TABLE products ADD COLUMN IF NOT EXISTS check_interval_seconds BIGINT CHECK (check_interval_seconds > 0);
+ALTER TABLE products ADD COLUMN IF NOT EXISTS next_check_at TIMESTAMPTZ;
+
+-- Spread the existing products over the first hour instead of checking all at once
+UPDATE products SET next_check_at = NOW() + random() * INTERVAL '1 hour' WHERE next_check_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_products_next_check ON products (next_check_at NULLS FIRST, id);
+
+-- Per shop intervals, the host is stored without "www."
+CREATE TABLE IF NOT EXISTS host_check_intervals (
+    host TEXT PRIMARY KEY,
+    check_interval_seconds BIGINT NOT NULL CHECK (check_interval_seconds > 0),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,43 @@
+ALTER TABLE notification_channels
+    ADD COLUMN IF NOT EXISTS cooldown_minutes INTEGER NOT NULL DEFAULT 360,
+    ADD COLUMN IF NOT EXISTS rearm BOOLEAN NOT NULL DEFAULT TRUE,
+    ADD COLUMN IF NOT EXISTS quiet_start TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS quiet_end TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS timezone TEXT NOT NULL DEFAULT 'UTC';
+
+UPDATE notification_channels c
+SET quiet_start = u.quiet_start, quiet_end = u.quiet_end, timezone = u.timezone
+FROM users u
+WHERE u.id = c.user_id;
+
+-- Per subscription state and per user digests do not map back onto channels
+DROP INDEX IF EXISTS idx_digest_queue_user;
+DELETE FROM digest_queue;
+ALTER TABLE digest_queue
+    DROP COLUMN IF EXISTS user_id,
+    DROP COLUMN IF EXISTS claimed_until,
+    ADD COLUMN IF NOT EXISTS channel_id BIGINT NOT NULL REFERENCES notification_channels (id) ON DELETE CASCADE;
+CREATE INDEX IF NOT EXISTS idx_digest_queue_channel ON digest_queue (channel_id, id);
+
+DROP TABLE IF EXISTS alert_state;
+
+CREATE TABLE IF NOT EXISTS alert_state (
+    channel_id BIGINT NOT NULL REFERENCES notification_channels (id) ON DELETE CASCADE,
+    product_id BIGINT NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    rule_id BIGINT NOT NULL DEFAULT 0,
+    last_price DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    last_sent_at TIMESTAMP WITH TIME ZONE,
+    armed BOOLEAN NOT NULL DEFAULT TRUE,
+    PRIMARY KEY (channel_id, product_id, rule_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_alert_state_product ON alert_state (product_id) WHERE NOT armed;
+
+ALTER TABLE subscriptions
+    DROP COLUMN IF EXISTS cooldown_minutes,
+    DROP COLUMN IF EXISTS rearm;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS quiet_start,
+    DROP COLUMN IF EXISTS quiet_end,
+    DROP COLUMN IF EXISTS timezone;
@@ -0,0 +1,87 @@
+-- Cooldown and re-arm belong to a subscription, quiet hours to its owner. Product level
+-- alerts have neither and take the operator policy from the configuration.
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS quiet_start TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS quiet_end TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS timezone TEXT NOT NULL DEFAULT 'UTC';
+
+ALTER TABLE subscriptions
+    ADD COLUMN IF NOT EXISTS cooldown_minutes INTEGER NOT NULL DEFAULT 360,
+    ADD COLUMN IF NOT EXISTS rearm BOOLEAN NOT NULL DEFAULT TRUE;
+
+-- Keep what users set on their channels, their oldest channel wins
+UPDATE users u
+SET quiet_start = c.quiet_start, quiet_end = c.quiet_end, timezone = c.timezone
+FROM (
+    SELECT DISTINCT ON (user_id) user_id, quiet_start, quiet_end, timezone
+    FROM notification_channels
+    WHERE user_id IS NOT NULL AND quiet_start <> ''
+    ORDER BY user_id, id
+) c
+WHERE c.user_id = u.id;
+
+UPDATE subscriptions s
+SET cooldown_minutes = c.cooldown_minutes, rearm = c.rearm
+FROM (
+    SELECT DISTINCT ON (user_id) user_id, cooldown_minutes, rearm
+    FROM notification_channels
+    WHERE user_id IS NOT NULL
+    ORDER BY user_id, id
+) c
+WHERE c.user_id = s.user_id;
+
+ALTER TABLE alert_state RENAME TO alert_state_by_channel;
+
+-- subscription_id 0 is a product level alert, like rule_id 0 it has no foreign key.
+-- version changes with every write, claimed_until is set while a replica delivers.
+CREATE TABLE alert_state (
+    subscription_id BIGINT NOT NULL DEFAULT 0,
+    product_id BIGINT NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    rule_id BIGINT NOT NULL DEFAULT 0,
+    last_price DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    last_sent_at TIMESTAMP WITH TIME ZONE,
+    armed BOOLEAN NOT NULL DEFAULT TRUE,
+    version BIGINT NOT NULL DEFAULT 0,
+    claimed_until TIMESTAMP WITH TIME ZONE,
+    PRIMARY KEY (subscription_id, product_id, rule_id)
+);
+
+-- The most recent alert of any of the owner's channels becomes the subscription's
+INSERT INTO alert_state (subscription_id, product_id, rule_id, last_price, last_sent_at, armed)
+SELECT DISTINCT ON (COALESCE(s.id, 0), a.product_id, a.rule_id)
+    COALESCE(s.id, 0), a.product_id, a.rule_id, a.last_price, a.last_sent_at, a.armed
+FROM alert_state_by_channel a
+JOIN notification_channels c ON c.id = a.channel_id
+LEFT JOIN subscriptions s ON s.user_id = c.user_id AND s.product_id = a.product_id
+WHERE c.user_id IS NULL OR s.id IS NOT NULL
+ORDER BY COALESCE(s.id, 0), a.product_id, a.rule_id, a.last_sent_at DESC NULLS LAST;
+
+DROP TABLE alert_state_by_channel;
+
+CREATE INDEX IF NOT EXISTS idx_alert_state_product ON alert_state (product_id);
+
+-- The digest is per user now, NULL for the operator like on notification_channels
+ALTER TABLE digest_queue
+    ADD COLUMN IF NOT EXISTS user_id BIGINT REFERENCES users (id) ON DELETE CASCADE,
+    ADD COLUMN IF NOT EXISTS claimed_until TIMESTAMP WITH TIME ZONE;
+
+UPDATE digest_queue d
+SET user_id = c.user_id
+FROM notification_channels c
+WHERE c.id = d.channel_id;
+
+-- Every channel of a user queued its own copy
+DELETE FROM digest_queue d
+USING digest_queue o
+WHERE o.user_id IS NOT DISTINCT FROM d.user_id AND o.payload = d.payload AND o.id < d.id;
+
+DROP INDEX IF EXISTS idx_digest_queue_channel;
+ALTER TABLE digest_queue DROP COLUMN IF EXISTS channel_id;
+CREATE INDEX IF NOT EXISTS idx_digest_queue_user ON digest_queue (user_id, id);
+
+ALTER TABLE notification_channels
+    DROP COLUMN IF EXISTS cooldown_minutes,
+    DROP COLUMN IF EXISTS rearm,
+    DROP COLUMN IF EXISTS quiet_start,
+    DROP COLUMN IF EXISTS quiet_end,
+    DROP COLUMN IF EXISTS timezone;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_outbox_parked;
+DROP INDEX IF EXISTS idx_outbox_pending;
+CREATE INDEX IF NOT EXISTS idx_outbox_pending ON outbox (available_at, id) WHERE sent_at IS NULL;
+ALTER TABLE outbox DROP COLUMN IF EXISTS parked_at;
@@ -0,0 +1,8 @@
+-- The relay claims messages by moving available_at past the publish, so no lock is held
+-- while Kafka is written. Messages that can't be published, or failed max_attempts
+-- times, are parked with their last error instead of retried forever.
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS parked_at TIMESTAMPTZ;
+
+DROP INDEX IF EXISTS idx_outbox_pending;
+CREATE INDEX IF NOT EXISTS idx_outbox_pending ON outbox (available_at, id) WHERE sent_at IS NULL AND parked_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_outbox_parked ON outbox (parked_at) WHERE parked_at IS NOT NULL;
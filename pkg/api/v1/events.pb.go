@@ -0,0 +1,453 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v6.33.5
+// source: proto/events.proto
+
+package v1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// PriceCheckRequested asks the watcher to fetch the current price of a product.
+type PriceCheckRequested struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProductId     int64                  `protobuf:"varint,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	RequestedAt   *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=requested_at,json=requestedAt,proto3" json:"requested_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PriceCheckRequested) Reset() {
+	*x = PriceCheckRequested{}
+	mi := &file_proto_events_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PriceCheckRequested) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PriceCheckRequested) ProtoMessage() {}
+
+func (x *PriceCheckRequested) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_events_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PriceCheckRequested.ProtoReflect.Descriptor instead.
+func (*PriceCheckRequested) Descriptor() ([]byte, []int) {
+	return file_proto_events_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *PriceCheckRequested) GetProductId() int64 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+func (x *PriceCheckRequested) GetRequestedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.RequestedAt
+	}
+	return nil
+}
+
+// PriceObserved is the outcome of a price check.
+type PriceObserved struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProductId     int64                  `protobuf:"varint,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	Price         float64                `protobuf:"fixed64,2,opt,name=price,proto3" json:"price,omitempty"`
+	Currency      string                 `protobuf:"bytes,3,opt,name=currency,proto3" json:"currency,omitempty"`
+	InStock       bool                   `protobuf:"varint,4,opt,name=in_stock,json=inStock,proto3" json:"in_stock,omitempty"`
+	Source        string                 `protobuf:"bytes,5,opt,name=source,proto3" json:"source,omitempty"`
+	ObservedAt    *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=observed_at,json=observedAt,proto3" json:"observed_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PriceObserved) Reset() {
+	*x = PriceObserved{}
+	mi := &file_proto_events_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PriceObserved) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PriceObserved) ProtoMessage() {}
+
+func (x *PriceObserved) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_events_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PriceObserved.ProtoReflect.Descriptor instead.
+func (*PriceObserved) Descriptor() ([]byte, []int) {
+	return file_proto_events_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *PriceObserved) GetProductId() int64 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+func (x *PriceObserved) GetPrice() float64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *PriceObserved) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *PriceObserved) GetInStock() bool {
+	if x != nil {
+		return x.InStock
+	}
+	return false
+}
+
+func (x *PriceObserved) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *PriceObserved) GetObservedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ObservedAt
+	}
+	return nil
+}
+
+// AlertTriggered is an alert rule firing, published on the notifications topic.
+// subscription_id and user_id are set for rules of a watchlist subscription. The
+// product's title, url and currency are what the notification shows.
+type AlertTriggered struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ProductId      int64                  `protobuf:"varint,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	RuleId         int64                  `protobuf:"varint,2,opt,name=rule_id,json=ruleId,proto3" json:"rule_id,omitempty"`
+	SubscriptionId int64                  `protobuf:"varint,3,opt,name=subscription_id,json=subscriptionId,proto3" json:"subscription_id,omitempty"`
+	UserId         int64                  `protobuf:"varint,4,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Type           string                 `protobuf:"bytes,5,opt,name=type,proto3" json:"type,omitempty"`
+	Price          float64                `protobuf:"fixed64,6,opt,name=price,proto3" json:"price,omitempty"`
+	PreviousPrice  float64                `protobuf:"fixed64,7,opt,name=previous_price,json=previousPrice,proto3" json:"previous_price,omitempty"`
+	Reason         string                 `protobuf:"bytes,8,opt,name=reason,proto3" json:"reason,omitempty"`
+	TriggeredAt    *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=triggered_at,json=triggeredAt,proto3" json:"triggered_at,omitempty"`
+	EventId        int64                  `protobuf:"varint,10,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	Title          string                 `protobuf:"bytes,11,opt,name=title,proto3" json:"title,omitempty"`
+	Url            string                 `protobuf:"bytes,12,opt,name=url,proto3" json:"url,omitempty"`
+	Currency       string                 `protobuf:"bytes,13,opt,name=currency,proto3" json:"currency,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *AlertTriggered) Reset() {
+	*x = AlertTriggered{}
+	mi := &file_proto_events_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AlertTriggered) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AlertTriggered) ProtoMessage() {}
+
+func (x *AlertTriggered) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_events_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AlertTriggered.ProtoReflect.Descriptor instead.
+func (*AlertTriggered) Descriptor() ([]byte, []int) {
+	return file_proto_events_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *AlertTriggered) GetProductId() int64 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+func (x *AlertTriggered) GetRuleId() int64 {
+	if x != nil {
+		return x.RuleId
+	}
+	return 0
+}
+
+func (x *AlertTriggered) GetSubscriptionId() int64 {
+	if x != nil {
+		return x.SubscriptionId
+	}
+	return 0
+}
+
+func (x *AlertTriggered) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *AlertTriggered) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *AlertTriggered) GetPrice() float64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *AlertTriggered) GetPreviousPrice() float64 {
+	if x != nil {
+		return x.PreviousPrice
+	}
+	return 0
+}
+
+func (x *AlertTriggered) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *AlertTriggered) GetTriggeredAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.TriggeredAt
+	}
+	return nil
+}
+
+func (x *AlertTriggered) GetEventId() int64 {
+	if x != nil {
+		return x.EventId
+	}
+	return 0
+}
+
+func (x *AlertTriggered) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *AlertTriggered) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *AlertTriggered) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+// ProductDeleted tells consumers to drop what they hold about a product.
+type ProductDeleted struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProductId     int64                  `protobuf:"varint,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	DeletedAt     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=deleted_at,json=deletedAt,proto3" json:"deleted_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProductDeleted) Reset() {
+	*x = ProductDeleted{}
+	mi := &file_proto_events_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProductDeleted) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProductDeleted) ProtoMessage() {}
+
+func (x *ProductDeleted) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_events_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProductDeleted.ProtoReflect.Descriptor instead.
+func (*ProductDeleted) Descriptor() ([]byte, []int) {
+	return file_proto_events_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ProductDeleted) GetProductId() int64 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+func (x *ProductDeleted) GetDeletedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DeletedAt
+	}
+	return nil
+}
+
+var File_proto_events_proto protoreflect.FileDescriptor
+
+const file_proto_events_proto_rawDesc = "" +
+	"\n" +
+	"\x12proto/events.proto\x12\x02v1\x1a\x1fgoogle/protobuf/timestamp.proto\"s\n" +
+	"\x13PriceCheckRequested\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\x03R\tproductId\x12=\n" +
+	"\frequested_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\vrequestedAt\"\xd0\x01\n" +
+	"\rPriceObserved\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\x03R\tproductId\x12\x14\n" +
+	"\x05price\x18\x02 \x01(\x01R\x05price\x12\x1a\n" +
+	"\bcurrency\x18\x03 \x01(\tR\bcurrency\x12\x19\n" +
+	"\bin_stock\x18\x04 \x01(\bR\ainStock\x12\x16\n" +
+	"\x06source\x18\x05 \x01(\tR\x06source\x12;\n" +
+	"\vobserved_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"observedAt\"\x91\x03\n" +
+	"\x0eAlertTriggered\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\x03R\tproductId\x12\x17\n" +
+	"\arule_id\x18\x02 \x01(\x03R\x06ruleId\x12'\n" +
+	"\x0fsubscription_id\x18\x03 \x01(\x03R\x0esubscriptionId\x12\x17\n" +
+	"\auser_id\x18\x04 \x01(\x03R\x06userId\x12\x12\n" +
+	"\x04type\x18\x05 \x01(\tR\x04type\x12\x14\n" +
+	"\x05price\x18\x06 \x01(\x01R\x05price\x12%\n" +
+	"\x0eprevious_price\x18\a \x01(\x01R\rpreviousPrice\x12\x16\n" +
+	"\x06reason\x18\b \x01(\tR\x06reason\x12=\n" +
+	"\ftriggered_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\vtriggeredAt\x12\x19\n" +
+	"\bevent_id\x18\n" +
+	" \x01(\x03R\aeventId\x12\x14\n" +
+	"\x05title\x18\v \x01(\tR\x05title\x12\x10\n" +
+	"\x03url\x18\f \x01(\tR\x03url\x12\x1a\n" +
+	"\bcurrency\x18\r \x01(\tR\bcurrency\"j\n" +
+	"\x0eProductDeleted\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\x03R\tproductId\x129\n" +
+	"\n" +
+	"deleted_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\tdeletedAtB0Z.github.com/derkres11/price-pulse/pkg/api/v1;v1b\x06proto3"
+
+var (
+	file_proto_events_proto_rawDescOnce sync.Once
+	file_proto_events_proto_rawDescData []byte
+)
+
+func file_proto_events_proto_rawDescGZIP() []byte {
+	file_proto_events_proto_rawDescOnce.Do(func() {
+		file_proto_events_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proto_events_proto_rawDesc), len(file_proto_events_proto_rawDesc)))
+	})
+	return file_proto_events_proto_rawDescData
+}
+
+var file_proto_events_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_proto_events_proto_goTypes = []any{
+	(*PriceCheckRequested)(nil),   // 0: v1.PriceCheckRequested
+	(*PriceObserved)(nil),         // 1: v1.PriceObserved
+	(*AlertTriggered)(nil),        // 2: v1.AlertTriggered
+	(*ProductDeleted)(nil),        // 3: v1.ProductDeleted
+	(*timestamppb.Timestamp)(nil), // 4: google.protobuf.Timestamp
+}
+var file_proto_events_proto_depIdxs = []int32{
+	4, // 0: v1.PriceCheckRequested.requested_at:type_name -> google.protobuf.Timestamp
+	4, // 1: v1.PriceObserved.observed_at:type_name -> google.protobuf.Timestamp
+	4, // 2: v1.AlertTriggered.triggered_at:type_name -> google.protobuf.Timestamp
+	4, // 3: v1.ProductDeleted.deleted_at:type_name -> google.protobuf.Timestamp
+	4, // [4:4] is the sub-list for method output_type
+	4, // [4:4] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_proto_events_proto_init() }
+func file_proto_events_proto_init() {
+	if File_proto_events_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_events_proto_rawDesc), len(file_proto_events_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   4,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_proto_events_proto_goTypes,
+		DependencyIndexes: file_proto_events_proto_depIdxs,
+		MessageInfos:      file_proto_events_proto_msgTypes,
+	}.Build()
+	File_proto_events_proto = out.File
+	file_proto_events_proto_goTypes = nil
+	file_proto_events_proto_depIdxs = nil
+}
@@ -0,0 +1,477 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v6.33.5
+// source: proto/watchlist.proto
+
+package v1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Subscription struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	ProductId     int64                  `protobuf:"varint,2,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	Url           string                 `protobuf:"bytes,3,opt,name=url,proto3" json:"url,omitempty"`
+	Title         string                 `protobuf:"bytes,4,opt,name=title,proto3" json:"title,omitempty"`
+	CurrentPrice  float64                `protobuf:"fixed64,5,opt,name=current_price,json=currentPrice,proto3" json:"current_price,omitempty"`
+	TargetPrice   float64                `protobuf:"fixed64,6,opt,name=target_price,json=targetPrice,proto3" json:"target_price,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Subscription) Reset() {
+	*x = Subscription{}
+	mi := &file_proto_watchlist_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Subscription) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Subscription) ProtoMessage() {}
+
+func (x *Subscription) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_watchlist_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Subscription.ProtoReflect.Descriptor instead.
+func (*Subscription) Descriptor() ([]byte, []int) {
+	return file_proto_watchlist_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Subscription) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Subscription) GetProductId() int64 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+func (x *Subscription) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Subscription) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Subscription) GetCurrentPrice() float64 {
+	if x != nil {
+		return x.CurrentPrice
+	}
+	return 0
+}
+
+func (x *Subscription) GetTargetPrice() float64 {
+	if x != nil {
+		return x.TargetPrice
+	}
+	return 0
+}
+
+func (x *Subscription) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type WatchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Url           string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	TargetPrice   float64                `protobuf:"fixed64,2,opt,name=target_price,json=targetPrice,proto3" json:"target_price,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchRequest) Reset() {
+	*x = WatchRequest{}
+	mi := &file_proto_watchlist_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchRequest) ProtoMessage() {}
+
+func (x *WatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_watchlist_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchRequest.ProtoReflect.Descriptor instead.
+func (*WatchRequest) Descriptor() ([]byte, []int) {
+	return file_proto_watchlist_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *WatchRequest) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *WatchRequest) GetTargetPrice() float64 {
+	if x != nil {
+		return x.TargetPrice
+	}
+	return 0
+}
+
+type ListWatchlistRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListWatchlistRequest) Reset() {
+	*x = ListWatchlistRequest{}
+	mi := &file_proto_watchlist_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListWatchlistRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListWatchlistRequest) ProtoMessage() {}
+
+func (x *ListWatchlistRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_watchlist_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListWatchlistRequest.ProtoReflect.Descriptor instead.
+func (*ListWatchlistRequest) Descriptor() ([]byte, []int) {
+	return file_proto_watchlist_proto_rawDescGZIP(), []int{2}
+}
+
+type ListWatchlistResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Subscriptions []*Subscription        `protobuf:"bytes,1,rep,name=subscriptions,proto3" json:"subscriptions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListWatchlistResponse) Reset() {
+	*x = ListWatchlistResponse{}
+	mi := &file_proto_watchlist_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListWatchlistResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListWatchlistResponse) ProtoMessage() {}
+
+func (x *ListWatchlistResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_watchlist_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListWatchlistResponse.ProtoReflect.Descriptor instead.
+func (*ListWatchlistResponse) Descriptor() ([]byte, []int) {
+	return file_proto_watchlist_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListWatchlistResponse) GetSubscriptions() []*Subscription {
+	if x != nil {
+		return x.Subscriptions
+	}
+	return nil
+}
+
+type UpdateSubscriptionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	TargetPrice   float64                `protobuf:"fixed64,2,opt,name=target_price,json=targetPrice,proto3" json:"target_price,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateSubscriptionRequest) Reset() {
+	*x = UpdateSubscriptionRequest{}
+	mi := &file_proto_watchlist_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateSubscriptionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateSubscriptionRequest) ProtoMessage() {}
+
+func (x *UpdateSubscriptionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_watchlist_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateSubscriptionRequest.ProtoReflect.Descriptor instead.
+func (*UpdateSubscriptionRequest) Descriptor() ([]byte, []int) {
+	return file_proto_watchlist_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *UpdateSubscriptionRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *UpdateSubscriptionRequest) GetTargetPrice() float64 {
+	if x != nil {
+		return x.TargetPrice
+	}
+	return 0
+}
+
+type UnwatchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnwatchRequest) Reset() {
+	*x = UnwatchRequest{}
+	mi := &file_proto_watchlist_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnwatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnwatchRequest) ProtoMessage() {}
+
+func (x *UnwatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_watchlist_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnwatchRequest.ProtoReflect.Descriptor instead.
+func (*UnwatchRequest) Descriptor() ([]byte, []int) {
+	return file_proto_watchlist_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *UnwatchRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type UnwatchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnwatchResponse) Reset() {
+	*x = UnwatchResponse{}
+	mi := &file_proto_watchlist_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnwatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnwatchResponse) ProtoMessage() {}
+
+func (x *UnwatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_watchlist_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnwatchResponse.ProtoReflect.Descriptor instead.
+func (*UnwatchResponse) Descriptor() ([]byte, []int) {
+	return file_proto_watchlist_proto_rawDescGZIP(), []int{6}
+}
+
+var File_proto_watchlist_proto protoreflect.FileDescriptor
+
+const file_proto_watchlist_proto_rawDesc = "" +
+	"\n" +
+	"\x15proto/watchlist.proto\x12\x02v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xe8\x01\n" +
+	"\fSubscription\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x02 \x01(\x03R\tproductId\x12\x10\n" +
+	"\x03url\x18\x03 \x01(\tR\x03url\x12\x14\n" +
+	"\x05title\x18\x04 \x01(\tR\x05title\x12#\n" +
+	"\rcurrent_price\x18\x05 \x01(\x01R\fcurrentPrice\x12!\n" +
+	"\ftarget_price\x18\x06 \x01(\x01R\vtargetPrice\x129\n" +
+	"\n" +
+	"created_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"C\n" +
+	"\fWatchRequest\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x12!\n" +
+	"\ftarget_price\x18\x02 \x01(\x01R\vtargetPrice\"\x16\n" +
+	"\x14ListWatchlistRequest\"O\n" +
+	"\x15ListWatchlistResponse\x126\n" +
+	"\rsubscriptions\x18\x01 \x03(\v2\x10.v1.SubscriptionR\rsubscriptions\"N\n" +
+	"\x19UpdateSubscriptionRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12!\n" +
+	"\ftarget_price\x18\x02 \x01(\x01R\vtargetPrice\" \n" +
+	"\x0eUnwatchRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"\x11\n" +
+	"\x0fUnwatchResponse2\x80\x02\n" +
+	"\x10WatchlistService\x12+\n" +
+	"\x05Watch\x12\x10.v1.WatchRequest\x1a\x10.v1.Subscription\x12D\n" +
+	"\rListWatchlist\x12\x18.v1.ListWatchlistRequest\x1a\x19.v1.ListWatchlistResponse\x12E\n" +
+	"\x12UpdateSubscription\x12\x1d.v1.UpdateSubscriptionRequest\x1a\x10.v1.Subscription\x122\n" +
+	"\aUnwatch\x12\x12.v1.UnwatchRequest\x1a\x13.v1.UnwatchResponseB0Z.github.com/derkres11/price-pulse/pkg/api/v1;v1b\x06proto3"
+
+var (
+	file_proto_watchlist_proto_rawDescOnce sync.Once
+	file_proto_watchlist_proto_rawDescData []byte
+)
+
+func file_proto_watchlist_proto_rawDescGZIP() []byte {
+	file_proto_watchlist_proto_rawDescOnce.Do(func() {
+		file_proto_watchlist_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proto_watchlist_proto_rawDesc), len(file_proto_watchlist_proto_rawDesc)))
+	})
+	return file_proto_watchlist_proto_rawDescData
+}
+
+var file_proto_watchlist_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_proto_watchlist_proto_goTypes = []any{
+	(*Subscription)(nil),              // 0: v1.Subscription
+	(*WatchRequest)(nil),              // 1: v1.WatchRequest
+	(*ListWatchlistRequest)(nil),      // 2: v1.ListWatchlistRequest
+	(*ListWatchlistResponse)(nil),     // 3: v1.ListWatchlistResponse
+	(*UpdateSubscriptionRequest)(nil), // 4: v1.UpdateSubscriptionRequest
+	(*UnwatchRequest)(nil),            // 5: v1.UnwatchRequest
+	(*UnwatchResponse)(nil),           // 6: v1.UnwatchResponse
+	(*timestamppb.Timestamp)(nil),     // 7: google.protobuf.Timestamp
+}
+var file_proto_watchlist_proto_depIdxs = []int32{
+	7, // 0: v1.Subscription.created_at:type_name -> google.protobuf.Timestamp
+	0, // 1: v1.ListWatchlistResponse.subscriptions:type_name -> v1.Subscription
+	1, // 2: v1.WatchlistService.Watch:input_type -> v1.WatchRequest
+	2, // 3: v1.WatchlistService.ListWatchlist:input_type -> v1.ListWatchlistRequest
+	4, // 4: v1.WatchlistService.UpdateSubscription:input_type -> v1.UpdateSubscriptionRequest
+	5, // 5: v1.WatchlistService.Unwatch:input_type -> v1.UnwatchRequest
+	0, // 6: v1.WatchlistService.Watch:output_type -> v1.Subscription
+	3, // 7: v1.WatchlistService.ListWatchlist:output_type -> v1.ListWatchlistResponse
+	0, // 8: v1.WatchlistService.UpdateSubscription:output_type -> v1.Subscription
+	6, // 9: v1.WatchlistService.Unwatch:output_type -> v1.UnwatchResponse
+	6, // [6:10] is the sub-list for method output_type
+	2, // [2:6] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_proto_watchlist_proto_init() }
+func file_proto_watchlist_proto_init() {
+	if File_proto_watchlist_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_watchlist_proto_rawDesc), len(file_proto_watchlist_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_proto_watchlist_proto_goTypes,
+		DependencyIndexes: file_proto_watchlist_proto_depIdxs,
+		MessageInfos:      file_proto_watchlist_proto_msgTypes,
+	}.Build()
+	File_proto_watchlist_proto = out.File
+	file_proto_watchlist_proto_goTypes = nil
+	file_proto_watchlist_proto_depIdxs = nil
+}
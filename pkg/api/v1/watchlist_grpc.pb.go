@@ -0,0 +1,241 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.1
+// - protoc             v6.33.5
+// source: proto/watchlist.proto
+
+package v1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	WatchlistService_Watch_FullMethodName              = "/v1.WatchlistService/Watch"
+	WatchlistService_ListWatchlist_FullMethodName      = "/v1.WatchlistService/ListWatchlist"
+	WatchlistService_UpdateSubscription_FullMethodName = "/v1.WatchlistService/UpdateSubscription"
+	WatchlistService_Unwatch_FullMethodName            = "/v1.WatchlistService/Unwatch"
+)
+
+// WatchlistServiceClient is the client API for WatchlistService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// WatchlistService manages the calling user's subscriptions.
+// The caller is taken from the authorization (Bearer API key or JWT) metadata.
+type WatchlistServiceClient interface {
+	Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (*Subscription, error)
+	ListWatchlist(ctx context.Context, in *ListWatchlistRequest, opts ...grpc.CallOption) (*ListWatchlistResponse, error)
+	UpdateSubscription(ctx context.Context, in *UpdateSubscriptionRequest, opts ...grpc.CallOption) (*Subscription, error)
+	Unwatch(ctx context.Context, in *UnwatchRequest, opts ...grpc.CallOption) (*UnwatchResponse, error)
+}
+
+type watchlistServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewWatchlistServiceClient(cc grpc.ClientConnInterface) WatchlistServiceClient {
+	return &watchlistServiceClient{cc}
+}
+
+func (c *watchlistServiceClient) Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (*Subscription, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Subscription)
+	err := c.cc.Invoke(ctx, WatchlistService_Watch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *watchlistServiceClient) ListWatchlist(ctx context.Context, in *ListWatchlistRequest, opts ...grpc.CallOption) (*ListWatchlistResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListWatchlistResponse)
+	err := c.cc.Invoke(ctx, WatchlistService_ListWatchlist_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *watchlistServiceClient) UpdateSubscription(ctx context.Context, in *UpdateSubscriptionRequest, opts ...grpc.CallOption) (*Subscription, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Subscription)
+	err := c.cc.Invoke(ctx, WatchlistService_UpdateSubscription_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *watchlistServiceClient) Unwatch(ctx context.Context, in *UnwatchRequest, opts ...grpc.CallOption) (*UnwatchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UnwatchResponse)
+	err := c.cc.Invoke(ctx, WatchlistService_Unwatch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// WatchlistServiceServer is the server API for WatchlistService service.
+// All implementations must embed UnimplementedWatchlistServiceServer
+// for forward compatibility.
+//
+// WatchlistService manages the calling user's subscriptions.
+// The caller is taken from the authorization (Bearer API key or JWT) metadata.
+type WatchlistServiceServer interface {
+	Watch(context.Context, *WatchRequest) (*Subscription, error)
+	ListWatchlist(context.Context, *ListWatchlistRequest) (*ListWatchlistResponse, error)
+	UpdateSubscription(context.Context, *UpdateSubscriptionRequest) (*Subscription, error)
+	Unwatch(context.Context, *UnwatchRequest) (*UnwatchResponse, error)
+	mustEmbedUnimplementedWatchlistServiceServer()
+}
+
+// UnimplementedWatchlistServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedWatchlistServiceServer struct{}
+
+func (UnimplementedWatchlistServiceServer) Watch(context.Context, *WatchRequest) (*Subscription, error) {
+	return nil, status.Error(codes.Unimplemented, "method Watch not implemented")
+}
+func (UnimplementedWatchlistServiceServer) ListWatchlist(context.Context, *ListWatchlistRequest) (*ListWatchlistResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListWatchlist not implemented")
+}
+func (UnimplementedWatchlistServiceServer) UpdateSubscription(context.Context, *UpdateSubscriptionRequest) (*Subscription, error) {
+	return nil, status.Error(codes.Unimplemented, "method UpdateSubscription not implemented")
+}
+func (UnimplementedWatchlistServiceServer) Unwatch(context.Context, *UnwatchRequest) (*UnwatchResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Unwatch not implemented")
+}
+func (UnimplementedWatchlistServiceServer) mustEmbedUnimplementedWatchlistServiceServer() {}
+func (UnimplementedWatchlistServiceServer) testEmbeddedByValue()                          {}
+
+// UnsafeWatchlistServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to WatchlistServiceServer will
+// result in compilation errors.
+type UnsafeWatchlistServiceServer interface {
+	mustEmbedUnimplementedWatchlistServiceServer()
+}
+
+func RegisterWatchlistServiceServer(s grpc.ServiceRegistrar, srv WatchlistServiceServer) {
+	// If the following call panics, it indicates UnimplementedWatchlistServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&WatchlistService_ServiceDesc, srv)
+}
+
+func _WatchlistService_Watch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WatchlistServiceServer).Watch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WatchlistService_Watch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WatchlistServiceServer).Watch(ctx, req.(*WatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WatchlistService_ListWatchlist_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListWatchlistRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WatchlistServiceServer).ListWatchlist(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WatchlistService_ListWatchlist_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WatchlistServiceServer).ListWatchlist(ctx, req.(*ListWatchlistRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WatchlistService_UpdateSubscription_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateSubscriptionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WatchlistServiceServer).UpdateSubscription(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WatchlistService_UpdateSubscription_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WatchlistServiceServer).UpdateSubscription(ctx, req.(*UpdateSubscriptionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WatchlistService_Unwatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnwatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WatchlistServiceServer).Unwatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WatchlistService_Unwatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WatchlistServiceServer).Unwatch(ctx, req.(*UnwatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// WatchlistService_ServiceDesc is the grpc.ServiceDesc for WatchlistService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var WatchlistService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "v1.WatchlistService",
+	HandlerType: (*WatchlistServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Watch",
+			Handler:    _WatchlistService_Watch_Handler,
+		},
+		{
+			MethodName: "ListWatchlist",
+			Handler:    _WatchlistService_ListWatchlist_Handler,
+		},
+		{
+			MethodName: "UpdateSubscription",
+			Handler:    _WatchlistService_UpdateSubscription_Handler,
+		},
+		{
+			MethodName: "Unwatch",
+			Handler:    _WatchlistService_Unwatch_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "proto/watchlist.proto",
+}